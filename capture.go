@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+)
+
+// captureContent calls fn with the output of the document redirected to a
+// separate content stream, which is returned, for use in a form XObject that
+// shares the resources of the document. Settings that are tracked to avoid
+// redundant output are invalidated, because the graphics state of a form is
+// inherited from wherever it is drawn. The current position and settings are
+// restored afterward, and no page breaks occur while fn is called. The
+// content is captured even during a dry run performed by Measure(), since the
+// resulting form remains with the document.
+func (f *Fpdf) captureContent(fn func()) *bytes.Buffer {
+	var content bytes.Buffer
+	page, state, x, y := f.page, f.state, f.x, f.y
+	autoPageBreak := f.autoPageBreak
+	color, colorFlag := f.color, f.colorFlag
+	lineWidth, capStyle, joinStyle := f.lineWidth, f.capStyle, f.joinStyle
+	dashArray, dashPhase := f.dashArray, f.dashPhase
+	alpha, blendMode := f.alpha, f.blendMode
+	fontFamily, fontStyle, underline := f.fontFamily, f.fontStyle, f.underline
+	fontSizePt, fontSize, currentFont := f.fontSizePt, f.fontSize, f.currentFont
+	measure := f.measure
+	f.measure = nil
+	f.pages = append(f.pages, &content)
+	f.pageLinks = append(f.pageLinks, nil)
+	f.page, f.state = len(f.pages)-1, 2
+	f.autoPageBreak = false
+	f.color.fill.str, f.color.draw.str = "", ""
+	f.capStyle, f.joinStyle = -1, -1
+	f.dashArray, f.dashPhase = nil, -1
+	f.alpha, f.blendMode = 1, "Normal"
+	f.fontFamily, f.fontSizePt = "", 0
+	fn()
+	f.pages = f.pages[:len(f.pages)-1]
+	f.pageLinks = f.pageLinks[:len(f.pageLinks)-1]
+	f.page, f.state, f.x, f.y = page, state, x, y
+	f.autoPageBreak = autoPageBreak
+	f.color, f.colorFlag = color, colorFlag
+	f.lineWidth, f.capStyle, f.joinStyle = lineWidth, capStyle, joinStyle
+	f.dashArray, f.dashPhase = dashArray, dashPhase
+	f.alpha, f.blendMode = alpha, blendMode
+	f.fontFamily, f.fontStyle, f.underline = fontFamily, fontStyle, underline
+	f.fontSizePt, f.fontSize, f.currentFont = fontSizePt, fontSize, currentFont
+	f.measure = measure
+	return &content
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// crossRefType is a cross-reference that has been output with a reserved
+// width. The page number is right-padded to the reserved width when the
+// document is closed.
+type crossRefType struct {
+	name    string
+	fontKey string
+	sizePt  float64
+	wd      float64 // reserved width in points
+}
+
+var (
+	crossRefRe    = regexp.MustCompile(`\{ref:([^{}]*)\}`)
+	crossRefPosRe = regexp.MustCompile(`\{ref\((\d+)\)\}`)
+)
+
+// AliasCrossRefs enables forward cross-references. When enabled, a reference
+// of the form "{ref:name}" in text output by methods such as Cell(), Write(),
+// MultiCell() and Text() is replaced, as the document is closed, with the
+// number of the page on which the anchor with the given name is set with
+// SetAnchor(). The anchor may be set before or after the reference is output.
+// A reference to an anchor that is never set results in an error that is
+// reported by Error() and Output().
+//
+// wd is the width, in the unit of measure specified in New(), that is
+// reserved for each reference when text is measured, wrapped and aligned. The
+// page number is left-aligned in this space. If wd is zero, no space is
+// reserved and the reference is replaced with the page number in the same
+// manner as the alias specified with AliasNbPages(); in this case text that is
+// aligned or justified will be positioned according to the width of the
+// reference itself rather than that of the page number. Reference names may
+// not contain braces, parentheses or backslashes.
+//
+// The SetAnchor() example demonstrates this method.
+func (f *Fpdf) AliasCrossRefs(wd float64) {
+	f.crossRef = true
+	f.crossRefWd = wd
+}
+
+// SetAnchor associates the specified name with the current page for the
+// purpose of cross-references. See AliasCrossRefs() for more details. Setting
+// an anchor with a name that is already in use results in an error.
+func (f *Fpdf) SetAnchor(nameStr string) {
+	if f.err != nil || f.measure != nil {
+		return
+	}
+	if f.page < 1 {
+		f.SetErrorf("anchor %q cannot be set before the first page is added", nameStr)
+		return
+	}
+	if f.anchors == nil {
+		f.anchors = make(map[string]int)
+	}
+	if _, ok := f.anchors[nameStr]; ok {
+		f.SetErrorf("anchor %q is already set", nameStr)
+		return
+	}
+	f.anchors[nameStr] = f.page
+}
+
+// crossRefReserved returns true if cross-references in s occupy a reserved
+// width
+func (f *Fpdf) crossRefReserved(s string) bool {
+	return f.crossRef && f.crossRefWd > 0 && strings.Contains(s, "{ref:")
+}
+
+// crossRefWidths returns the width of each byte of s in thousandths of the
+// current font size, or nil if s does not contain any cross-references that
+// occupy a reserved width. The reserved width is assigned to the first byte of
+// each reference; the remaining bytes have zero width.
+func (f *Fpdf) crossRefWidths(s string) (list []int) {
+	if !f.crossRefReserved(s) {
+		return
+	}
+	cw := &f.currentFont.Cw
+	list = make([]int, len(s))
+	for j := 0; j < len(s); j++ {
+		list[j] = cw[s[j]]
+	}
+	wd := int(math.Floor(f.crossRefWd*1000/f.fontSize + 0.5))
+	for _, pos := range crossRefRe.FindAllStringIndex(s, -1) {
+		list[pos[0]] = wd
+		for j := pos[0] + 1; j < pos[1]; j++ {
+			list[j] = 0
+		}
+	}
+	return
+}
+
+// crossRefStringWidth returns the width of s in user units, counting each
+// cross-reference as the reserved width
+func (f *Fpdf) crossRefStringWidth(s string) float64 {
+	refList := crossRefRe.FindAllStringIndex(s, -1)
+	s = crossRefRe.ReplaceAllString(s, "")
+	w := 0
+	for _, ch := range []byte(s) {
+		if ch == 0 {
+			break
+		}
+		w += f.currentFont.Cw[ch]
+	}
+	return float64(w)*f.fontSize/1000 + float64(len(refList))*f.crossRefWd
+}
+
+// crossRefText prepares the escaped text s of a text showing operation for
+// the substitution of cross-references with a reserved width. Each reference
+// ends the current string operand and is replaced with a numbered placeholder
+// that is resolved by crossRefResolve(). The placeholder contains an
+// unescaped parenthesis, so it cannot be mistaken for escaped text.
+func (f *Fpdf) crossRefText(s string) string {
+	if !f.crossRefReserved(s) {
+		return s
+	}
+	unescape := strings.NewReplacer("\\\\", "\\", "\\(", "(", "\\)", ")")
+	return crossRefRe.ReplaceAllStringFunc(s, func(refStr string) string {
+		f.crossRefList = append(f.crossRefList, crossRefType{
+			name:    unescape.Replace(refStr[5 : len(refStr)-1]),
+			fontKey: f.fontFamily + f.fontStyle,
+			sizePt:  f.fontSizePt,
+			wd:      f.crossRefWd * f.k,
+		})
+		return fmt.Sprintf(") Tj {ref(%d)} (", len(f.crossRefList)-1)
+	})
+}
+
+// crossRefResolve replaces the cross-references in the specified page content
+// with page numbers
+func (f *Fpdf) crossRefResolve(s string) string {
+	pageStr := func(nameStr string) string {
+		page, ok := f.anchors[nameStr]
+		if !ok {
+			if f.err == nil {
+				f.err = fmt.Errorf("cross-reference to undefined anchor %q", nameStr)
+			}
+			return ""
+		}
+		return strconv.Itoa(page)
+	}
+	s = crossRefPosRe.ReplaceAllStringFunc(s, func(refStr string) string {
+		n, _ := strconv.Atoi(refStr[5 : len(refStr)-1])
+		ref := f.crossRefList[n]
+		numStr := pageStr(ref.name)
+		cw := f.fonts[ref.fontKey].Cw
+		w := 0
+		for _, ch := range []byte(numStr) {
+			w += cw[ch]
+		}
+		// Displacement of the following text in thousandths of text space
+		adj := ref.wd*1000/ref.sizePt - float64(w)
+		return sprintf("[(%s) %.2f] TJ", numStr, -adj)
+	})
+	return crossRefRe.ReplaceAllStringFunc(s, func(refStr string) string {
+		return pageStr(refStr[5 : len(refStr)-1])
+	})
+}
@@ -32,13 +32,62 @@ type blendModeType struct {
 	objNum                      int
 }
 
+// gradientStopType is a color of a gradient at position pos, which ranges
+// from 0 to 1
+type gradientStopType struct {
+	pos    float64
+	clrStr string
+}
+
 type gradientType struct {
-	tp                int // 2: linear, 3: radial
-	clr1Str, clr2Str  string
+	tp                int    // 2: linear, 3: radial, 4 - 7: mesh
+	spaceStr          string // color space of stops, DeviceRGB if empty
+	stops             []gradientStopType
 	x1, y1, x2, y2, r float64
+	r1                float64 // radius of starting circle of radial gradient
+	extend            [2]bool // extend gradient beyond its starting and ending points
+	data              []byte  // vertices or patches of mesh
+	decodeStr         string  // ranges of coordinates and color components of mesh
+	perRow            int     // vertices per row of lattice mesh
 	objNum            int
 }
 
+// RGBType holds the red, green and blue components of a color, each of
+// which ranges from 0 to 255.
+type RGBType struct {
+	R, G, B int
+}
+
+// ColorStopType specifies the color Clr of a gradient at position Pos, which
+// ranges from 0 at the starting point of the gradient to 1 at its ending
+// point.
+type ColorStopType struct {
+	Pos float64
+	Clr RGBType
+}
+
+// MeshVertexType is a vertex of a triangle or lattice mesh. Pt is the
+// position of the vertex in the unit of measure specified in New() and Clr
+// is its color.
+type MeshVertexType struct {
+	Pt  PointType
+	Clr RGBType
+}
+
+// MeshPatchType is a patch of a Coons patch or tensor-product patch mesh.
+// Points holds the control points of the four cubic Bézier curves that bound
+// the patch. Points[0], Points[3], Points[6] and Points[9] are the corners
+// of the patch, each followed by the two control points of the curve to the
+// next corner; the last curve returns to Points[0]. Clrs holds the colors of
+// the four corners in the same order. Inner holds the four control points in
+// the interior of the patch, which are only used by tensor-product patches.
+// Inner[j] is the interior control point nearest to the corner Points[3*j].
+type MeshPatchType struct {
+	Points [12]PointType
+	Inner  [4]PointType
+	Clrs   [4]RGBType
+}
+
 // SizeType fields Wd and Ht specify the horizontal and vertical extents of a
 // document element such as a page.
 type SizeType struct {
@@ -70,6 +119,7 @@ type ImageInfoType struct {
 	f     string
 	dp    string
 	trns  []int
+	icc   []byte  // embedded ICC profile
 	scale float64 // document scaling factor
 	dpi   float64
 }
@@ -193,6 +243,8 @@ type Fpdf struct {
 	rMargin          float64                   // right margin
 	bMargin          float64                   // page break margin
 	cMargin          float64                   // cell margin
+	cRadius          float64                   // radius of rounded cell corners
+	cCornersStr      string                    // corners of cells that are rounded
 	x, y             float64                   // current position in user unit
 	lasth            float64                   // height of last printed cell
 	lineWidth        float64                   // line width in user unit
@@ -210,8 +262,10 @@ type Fpdf struct {
 	fontSize         float64                   // current font size in user unit
 	ws               float64                   // word spacing
 	images           map[string]*ImageInfoType // array of used images
+	svgs             map[string]*svgFormType   // SVG images registered with RegisterSVG()
 	pageLinks        [][]linkType              // pageLinks[page][link], both 1-based
 	links            []intLinkType             // array of internal links
+	linkOffset       PointType                 // translation of link areas, such as that of the current label
 	outlines         []outlineType             // array of outlines
 	outlineRoot      int                       // root of outlines
 	autoPageBreak    bool                      // automatic page breaking
@@ -221,6 +275,10 @@ type Fpdf struct {
 	headerFnc        func()                    // function provided by app and called to write header
 	inFooter         bool                      // flag set when processing footer
 	footerFnc        func()                    // function provided by app and called to write footer
+	pageStampFnc     func(int, int, SizeType)  // function provided by app and called for each page when document is closed
+	inStamp          bool                      // flag set when processing page stamps
+	pageStates       map[int]pageStateType     // state of pages that have been left, keyed by page number
+	openPage         int                       // page most recently added, 0 once its footer has been output or if it has been deleted
 	zoomMode         string                    // zoom display mode
 	layoutMode       string                    // layout display mode
 	title            string                    // title
@@ -241,6 +299,12 @@ type Fpdf struct {
 	blendMode        string                    // current blend mode
 	alpha            float64                   // current transpacency
 	gradientList     []gradientType            // slice[idx] of gradient records
+	spotColorList    []spotColorType           // slice[idx] of spot colors, 1-based
+	spotColorMap     map[string]int            // map of spot color names into spotColorList
+	patternList      []patternType             // slice[idx] of tiling patterns, 1-based
+	softMaskList     []softMaskType            // slice[idx] of soft masks, 1-based
+	iccObjMap        map[string]int            // object numbers of ICC profiles keyed by content
+	outputIntent     *outputIntentType         // output device of document, if specified
 	clipNest         int                       // Number of active clipping contexts
 	transformNest    int                       // Number of active transformation contexts
 	err              error                     // Set if error occurs during life cycle of instance
@@ -248,6 +312,12 @@ type Fpdf struct {
 	layer            layerRecType              // manages optional layers in document
 	catalogSort      bool                      // sort resource catalogs in document
 	colorFlag        bool                      // indicates whether fill and text colors are different
+	measure          *measureRecType           // non-nil while a dry run is performed by Measure()
+	index            []indexEntryType          // back-of-book index entries recorded by IndexEntry()
+	crossRef         bool                      // substitute cross-references when document is closed
+	crossRefWd       float64                   // width reserved for each cross-reference, 0 for literal width
+	crossRefList     []crossRefType            // cross-references output with a reserved width
+	anchors          map[string]int            // page numbers of anchors keyed by name
 	color            struct {
 		// Composite values of colors
 		draw, fill, text clrType
@@ -82,6 +82,7 @@ func fpdfNew(orientationStr, unitStr, sizeStr, fontDirStr string, size SizeType)
 	f.templates = make(map[int64]Template)
 	f.templateObjects = make(map[int64]int)
 	f.images = make(map[string]*ImageInfoType)
+	f.svgs = make(map[string]*svgFormType)
 	f.pageLinks = make([][]linkType, 0, 8)
 	f.pageLinks = append(f.pageLinks, make([]linkType, 0, 0)) // pageLinks[0] is unused (1-based)
 	f.links = make([]intLinkType, 0, 8)
@@ -179,6 +180,11 @@ func fpdfNew(orientationStr, unitStr, sizeStr, fontDirStr string, size SizeType)
 	f.blendList = make([]blendModeType, 0, 8)
 	f.blendList = append(f.blendList, blendModeType{}) // blendList[0] is unused (1-based)
 	f.blendMap = make(map[string]int)
+	f.spotColorList = make([]spotColorType, 1) // spotColorList[0] is unused (1-based)
+	f.spotColorMap = make(map[string]int)
+	f.patternList = make([]patternType, 1)   // patternList[0] is unused (1-based)
+	f.softMaskList = make([]softMaskType, 1) // softMaskList[0] is unused (1-based)
+	f.iccObjMap = make(map[string]int)
 	f.blendMode = "Normal"
 	f.alpha = 1
 	f.gradientList = make([]gradientType, 0, 8)
@@ -328,6 +334,28 @@ func (f *Fpdf) SetCellMargin(margin float64) {
 	f.cMargin = margin
 }
 
+// SetCellRounding sets the radius r, in units passed to New(), of the rounded
+// corners of cells that are printed by CellFormat() with a full border ("1")
+// or a background fill. cornersStr specifies which corners are rounded, as it
+// does for RoundedRect(); an empty string rounds all four. Borders made of
+// individual sides, such as those of MultiCell() lines, are not affected. A
+// radius of 0, the default, prints square cells.
+//
+// The RoundedRect() example demonstrates this method.
+func (f *Fpdf) SetCellRounding(r float64, cornersStr string) {
+	if r < 0 {
+		r = 0
+	}
+	f.cRadius = r
+	f.cCornersStr = cornersStr
+}
+
+// GetCellRounding returns the radius and the corners set with
+// SetCellRounding().
+func (f *Fpdf) GetCellRounding() (r float64, cornersStr string) {
+	return f.cRadius, f.cCornersStr
+}
+
 // SetFontLocation sets the location in the file system of the font and font
 // definition files.
 func (f *Fpdf) SetFontLocation(fontDirStr string) {
@@ -367,6 +395,26 @@ func (f *Fpdf) SetFooterFunc(fnc func()) {
 	f.footerFnc = fnc
 }
 
+// SetPageStampFunc sets the function that lets the application stamp each
+// page of the document, for example with a watermark, a Bates number or a
+// barcode. The specified function is called by Close() for each page in turn
+// after all content has been generated and the footer of the last page has
+// been rendered, so the total number of pages is known. pageNum is the number
+// of the page being stamped, pageCount is the total number of pages, and size
+// is the size of the page in the units established in New().
+//
+// Output produced by fnc is placed on top of the existing page content. The
+// graphics state established by the content of the page, for example a
+// transformation or clipping region, does not affect it. The font, colors and
+// line width that are in effect when the document is closed are selected
+// before fnc is called for each page. Automatic page breaks are suppressed and
+// pages cannot be added while stamping.
+//
+// The SetPageStampFunc() example demonstrates this method.
+func (f *Fpdf) SetPageStampFunc(fnc func(pageNum, pageCount int, size SizeType)) {
+	f.pageStampFnc = fnc
+}
+
 // SetTopMargin defines the top margin. The method can be called before
 // creating the first page.
 func (f *Fpdf) SetTopMargin(margin float64) {
@@ -534,14 +582,14 @@ func (f *Fpdf) Close() {
 			return
 		}
 	}
-	// Page footer
-	if f.footerFnc != nil {
-		f.inFooter = true
-		f.footerFnc()
-		f.inFooter = false
+	f.finishpage()
+	// Stamp pages
+	if f.pageStampFnc != nil {
+		f.stamppages()
+		if f.err != nil {
+			return
+		}
 	}
-	// Close page
-	f.endpage()
 	// Close document
 	f.enddoc()
 	return
@@ -574,6 +622,14 @@ func (f *Fpdf) AddPageFormat(orientationStr string, size SizeType) {
 	if f.err != nil {
 		return
 	}
+	if f.measure != nil {
+		f.measurePage(orientationStr, size)
+		return
+	}
+	if f.inStamp {
+		f.err = fmt.Errorf("pages cannot be added while stamping")
+		return
+	}
 	if f.state == 0 {
 		f.open()
 	}
@@ -589,14 +645,7 @@ func (f *Fpdf) AddPageFormat(orientationStr string, size SizeType) {
 	tc := f.color.text
 	cf := f.colorFlag
 	if f.page > 0 {
-		// Page footer
-		if f.footerFnc != nil {
-			f.inFooter = true
-			f.footerFnc()
-			f.inFooter = false
-		}
-		// Close page
-		f.endpage()
+		f.finishpage()
 	}
 	// Start new page
 	f.beginpage(orientationStr, size)
@@ -692,11 +741,16 @@ func (f *Fpdf) PageNo() int {
 	return f.page
 }
 
+// clrType is a color in the RGB or, if cmyk is true, the CMYK color model.
+// The RGB components of a CMYK color are an approximation used by the color
+// getters and by HTML and SVG rendering.
 type clrType struct {
-	r, g, b    float64
-	ir, ig, ib int
-	gray       bool
-	str        string
+	r, g, b        float64
+	ir, ig, ib     int
+	ic, im, iy, ik int
+	gray           bool
+	cmyk           bool
+	str            string
 }
 
 func colorComp(v int) (int, float64) {
@@ -725,6 +779,52 @@ func colorValue(r, g, b int, grayStr, fullStr string) (clr clrType) {
 	return
 }
 
+func colorCompCMYK(v int) int {
+	if v < 0 {
+		return 0
+	} else if v > 100 {
+		return 100
+	}
+	return v
+}
+
+// colorValueCMYK returns the CMYK color with the specified components, which
+// range from 0 to 100. The operator opStr is appended to the string
+// representation unless it is empty.
+func colorValueCMYK(c, m, y, k int, opStr string) (clr clrType) {
+	clr.cmyk = true
+	clr.ic, clr.im, clr.iy, clr.ik = colorCompCMYK(c), colorCompCMYK(m), colorCompCMYK(y), colorCompCMYK(k)
+	rgb := func(v int) (int, float64) {
+		return colorComp(int(math.Floor(255*(1-float64(v)/100)*(1-float64(clr.ik)/100) + 0.5)))
+	}
+	clr.ir, clr.r = rgb(clr.ic)
+	clr.ig, clr.g = rgb(clr.im)
+	clr.ib, clr.b = rgb(clr.iy)
+	clr.str = sprintf("%.3f %.3f %.3f %.3f", float64(clr.ic)/100, float64(clr.im)/100,
+		float64(clr.iy)/100, float64(clr.ik)/100)
+	if len(opStr) > 0 {
+		clr.str += " " + opStr
+	}
+	return
+}
+
+// cmykComps returns the CMYK components of clr. The components of an RGB
+// color are derived with the naive conversion that uses no black generation
+// or undercolor removal.
+func (clr clrType) cmykComps() (c, m, y, k int) {
+	if clr.cmyk {
+		return clr.ic, clr.im, clr.iy, clr.ik
+	}
+	mx := math.Max(clr.r, math.Max(clr.g, clr.b))
+	if mx == 0 {
+		return 0, 0, 0, 100
+	}
+	pct := func(v float64) int {
+		return int(math.Floor(100*v + 0.5))
+	}
+	return pct((mx - clr.r) / mx), pct((mx - clr.g) / mx), pct((mx - clr.b) / mx), pct(1 - mx)
+}
+
 // SetDrawColor defines the color used for all drawing operations (lines,
 // rectangles and cell borders). It is expressed in RGB components (0 - 255).
 // The method can be called before the first page is created. The value is
@@ -737,10 +837,30 @@ func (f *Fpdf) SetDrawColor(r, g, b int) {
 }
 
 // GetDrawColor returns the current draw color as RGB components (0 - 255).
+// If the color was set with SetDrawColorCMYK(), an approximation is returned.
 func (f *Fpdf) GetDrawColor() (int, int, int) {
 	return f.color.draw.ir, f.color.draw.ig, f.color.draw.ib
 }
 
+// SetDrawColorCMYK defines the color used for all drawing operations with
+// cyan, magenta, yellow and black components that range from 0 to 100
+// percent. The color is specified in the DeviceCMYK color space, as print
+// shops commonly require. In every other respect it behaves like
+// SetDrawColor().
+func (f *Fpdf) SetDrawColorCMYK(c, m, y, k int) {
+	f.color.draw = colorValueCMYK(c, m, y, k, "K")
+	if f.page > 0 {
+		f.out(f.color.draw.str)
+	}
+}
+
+// GetDrawColorCMYK returns the current draw color as CMYK components (0 -
+// 100). If the color was set with SetDrawColor(), a naive conversion is
+// returned.
+func (f *Fpdf) GetDrawColorCMYK() (int, int, int, int) {
+	return f.color.draw.cmykComps()
+}
+
 // SetFillColor defines the color used for all filling operations (filled
 // rectangles and cell backgrounds). It is expressed in RGB components (0
 // -255). The method can be called before the first page is created and the
@@ -754,10 +874,31 @@ func (f *Fpdf) SetFillColor(r, g, b int) {
 }
 
 // GetFillColor returns the current fill color as RGB components (0 - 255).
+// If the color was set with SetFillColorCMYK(), an approximation is returned.
 func (f *Fpdf) GetFillColor() (int, int, int) {
 	return f.color.fill.ir, f.color.fill.ig, f.color.fill.ib
 }
 
+// SetFillColorCMYK defines the color used for all filling operations with
+// cyan, magenta, yellow and black components that range from 0 to 100
+// percent. In every other respect it behaves like SetFillColor().
+//
+// The SetDrawColorCMYK() example demonstrates this method.
+func (f *Fpdf) SetFillColorCMYK(c, m, y, k int) {
+	f.color.fill = colorValueCMYK(c, m, y, k, "k")
+	f.colorFlag = f.color.fill.str != f.color.text.str
+	if f.page > 0 {
+		f.out(f.color.fill.str)
+	}
+}
+
+// GetFillColorCMYK returns the current fill color as CMYK components (0 -
+// 100). If the color was set with SetFillColor(), a naive conversion is
+// returned.
+func (f *Fpdf) GetFillColorCMYK() (int, int, int, int) {
+	return f.color.fill.cmykComps()
+}
+
 // SetTextColor defines the color used for text. It is expressed in RGB
 // components (0 - 255). The method can be called before the first page is
 // created. The value is retained from page to page.
@@ -767,16 +908,74 @@ func (f *Fpdf) SetTextColor(r, g, b int) {
 }
 
 // GetTextColor returns the current text color as RGB components (0 - 255).
+// If the color was set with SetTextColorCMYK(), an approximation is returned.
 func (f *Fpdf) GetTextColor() (int, int, int) {
 	return f.color.text.ir, f.color.text.ig, f.color.text.ib
 }
 
+// SetTextColorCMYK defines the color used for text with cyan, magenta, yellow
+// and black components that range from 0 to 100 percent. In every other
+// respect it behaves like SetTextColor().
+//
+// The SetDrawColorCMYK() example demonstrates this method.
+func (f *Fpdf) SetTextColorCMYK(c, m, y, k int) {
+	f.color.text = colorValueCMYK(c, m, y, k, "k")
+	f.colorFlag = f.color.fill.str != f.color.text.str
+}
+
+// GetTextColorCMYK returns the current text color as CMYK components (0 -
+// 100). If the color was set with SetTextColor(), a naive conversion is
+// returned.
+func (f *Fpdf) GetTextColorCMYK() (int, int, int, int) {
+	return f.color.text.cmykComps()
+}
+
+// setDrawClr makes clr, which has been saved from f.color.draw, the current
+// draw color. Unlike SetDrawColor(), it retains the color space of clr, so
+// CMYK, spot and pattern colors survive being saved and restored.
+func (f *Fpdf) setDrawClr(clr clrType) {
+	f.color.draw = clr
+	if f.page > 0 {
+		f.out(f.color.draw.str)
+	}
+}
+
+// setFillClr makes clr, which has been saved from f.color.fill, the current
+// fill color in its own color space
+func (f *Fpdf) setFillClr(clr clrType) {
+	f.color.fill = clr
+	f.colorFlag = f.color.fill.str != f.color.text.str
+	if f.page > 0 {
+		f.out(f.color.fill.str)
+	}
+}
+
+// setTextClr makes clr, which has been saved from f.color.text, the current
+// text color in its own color space
+func (f *Fpdf) setTextClr(clr clrType) {
+	f.color.text = clr
+	f.colorFlag = f.color.fill.str != f.color.text.str
+}
+
+// strokeClr returns the draw color that corresponds to clr, a fill or text
+// color, by substituting the stroking operators for the nonstroking ones
+func strokeClr(clr clrType) clrType {
+	clr.str = strokeOpReplacer.Replace(clr.str)
+	return clr
+}
+
+var strokeOpReplacer = strings.NewReplacer(" g", " G", " rg", " RG", " k", " K",
+	" cs ", " CS ", " scn", " SCN")
+
 // GetStringWidth returns the length of a string in user units. A font must be
 // currently selected.
 func (f *Fpdf) GetStringWidth(s string) float64 {
 	if f.err != nil {
 		return 0
 	}
+	if f.crossRefReserved(s) {
+		return f.crossRefStringWidth(s)
+	}
 	w := 0
 	for _, ch := range []byte(s) {
 		if ch == 0 {
@@ -886,6 +1085,7 @@ func (f *Fpdf) outputDashPattern() {
 // Line draws a line between points (x1, y1) and (x2, y2) using the current
 // draw color, line width and cap style.
 func (f *Fpdf) Line(x1, y1, x2, y2 float64) {
+	f.measureRect(x1, y1, x2-x1, y2-y1)
 	f.outf("%.2f %.2f m %.2f %.2f l S", x1*f.k, (f.h-y1)*f.k, x2*f.k, (f.h-y2)*f.k)
 }
 
@@ -922,9 +1122,100 @@ func fillDrawOp(styleStr string) (opStr string) {
 // draw color and line width centered on the rectangle's perimeter. Filling
 // uses the current fill color.
 func (f *Fpdf) Rect(x, y, w, h float64, styleStr string) {
+	f.measureRect(x, y, w, h)
 	f.outf("%.2f %.2f %.2f %.2f re %s", x*f.k, (f.h-y)*f.k, w*f.k, -h*f.k, fillDrawOp(styleStr))
 }
 
+// RoundedRect outputs a rectangle of width w and height h with the upper left
+// corner positioned at point (x, y). The corners listed in cornersStr are
+// rounded with radius r: "1" is the upper left corner, "2" the upper right,
+// "3" the lower right and "4" the lower left. For example, "12" rounds the
+// top corners only. An empty string rounds all four corners.
+//
+// styleStr is interpreted as it is by Rect().
+//
+// The RoundedRect() example demonstrates this method.
+func (f *Fpdf) RoundedRect(x, y, w, h, r float64, cornersStr, styleStr string) {
+	rTL, rTR, rBR, rBL := cornerRadii(r, cornersStr)
+	f.RoundedRectExt(x, y, w, h, rTL, rTR, rBR, rBL, styleStr)
+}
+
+// RoundedRectExt behaves like RoundedRect() but specifies the radius of each
+// corner individually: rTL for the upper left corner, rTR for the upper
+// right, rBR for the lower right and rBL for the lower left. A radius of 0
+// leaves its corner square. Radii that are too large for the rectangle are
+// reduced proportionally so that adjacent corners do not overlap.
+//
+// The RoundedRect() example demonstrates this method.
+func (f *Fpdf) RoundedRectExt(x, y, w, h, rTL, rTR, rBR, rBL float64, styleStr string) {
+	f.measureRect(x, y, w, h)
+	f.outf("%s%s", f.roundedRectPath(x, y, w, h, rTL, rTR, rBR, rBL), fillDrawOp(styleStr))
+}
+
+// cornerRadii returns the radii of the upper left, upper right, lower right
+// and lower left corners for the corners listed in cornersStr.
+func cornerRadii(r float64, cornersStr string) (rTL, rTR, rBR, rBL float64) {
+	if cornersStr == "" {
+		cornersStr = "1234"
+	}
+	radius := func(c string) float64 {
+		if strings.Contains(cornersStr, c) {
+			return r
+		}
+		return 0
+	}
+	return radius("1"), radius("2"), radius("3"), radius("4")
+}
+
+// roundedRectPath returns the operators that construct a closed rectangular
+// path with rounded corners, followed by a space. The path runs clockwise on
+// the page from the upper left corner.
+func (f *Fpdf) roundedRectPath(x, y, w, h, rTL, rTR, rBR, rBL float64) string {
+	if w < 0 {
+		x, w = x+w, -w
+	}
+	if h < 0 {
+		y, h = y+h, -h
+	}
+	// Reduce radii that do not fit along any side
+	scale := 1.0
+	for _, side := range [][3]float64{{rTL, rTR, w}, {rBL, rBR, w}, {rTL, rBL, h}, {rTR, rBR, h}} {
+		if sum := side[0] + side[1]; sum > side[2] && sum > 0 {
+			scale = math.Min(scale, side[2]/sum)
+		}
+	}
+	rTL, rTR, rBR, rBL = math.Max(rTL*scale, 0), math.Max(rTR*scale, 0),
+		math.Max(rBR*scale, 0), math.Max(rBL*scale, 0)
+	k := f.k
+	hp := f.h
+	myArc := (4.0 / 3.0) * (math.Sqrt2 - 1.0)
+	var s fmtBuffer
+	pt := func(op string, px, py float64) {
+		s.printf("%.5f %.5f %s ", px*k, (hp-py)*k, op)
+	}
+	// corner adds the arc with radius r from (x1, y1) to (x2, y2) around the
+	// corner at (cx, cy)
+	corner := func(r, x1, y1, cx, cy, x2, y2 float64) {
+		if r > 0 {
+			s.printf("%.5f %.5f %.5f %.5f %.5f %.5f c ",
+				(x1+(cx-x1)*myArc)*k, (hp-(y1+(cy-y1)*myArc))*k,
+				(x2+(cx-x2)*myArc)*k, (hp-(y2+(cy-y2)*myArc))*k,
+				x2*k, (hp-y2)*k)
+		}
+	}
+	pt("m", x+rTL, y)
+	pt("l", x+w-rTR, y)
+	corner(rTR, x+w-rTR, y, x+w, y, x+w, y+rTR)
+	pt("l", x+w, y+h-rBR)
+	corner(rBR, x+w, y+h-rBR, x+w, y+h, x+w-rBR, y+h)
+	pt("l", x+rBL, y+h)
+	corner(rBL, x+rBL, y+h, x, y+h, x, y+h-rBL)
+	pt("l", x, y+rTL)
+	corner(rTL, x, y+rTL, x, y, x+rTL, y)
+	s.printf("h ")
+	return s.String()
+}
+
 // Circle draws a circle centered on point (x, y) with radius r.
 //
 // styleStr can be "F" for filled, "D" for outlined only, or "DF" or "FD" for
@@ -962,6 +1253,7 @@ func (f *Fpdf) Ellipse(x, y, rx, ry, degRotate float64, styleStr string) {
 // Filling uses the current fill color.
 func (f *Fpdf) Polygon(points []PointType, styleStr string) {
 	if len(points) > 2 {
+		f.measurePoints(points)
 		for j, pt := range points {
 			if j == 0 {
 				f.point(pt.X, pt.Y)
@@ -992,6 +1284,7 @@ func (f *Fpdf) Beziergon(points []PointType, styleStr string) {
 	if len(points) < 4 {
 		return
 	}
+	f.measurePoints(points)
 	f.point(points[0].XY())
 
 	points = points[1:]
@@ -1152,13 +1445,27 @@ func (f *Fpdf) gradientClipEnd() {
 	f.out("Q")
 }
 
-func (f *Fpdf) gradient(tp int, r1, g1, b1 int, r2, g2, b2 int, x1, y1 float64, x2, y2 float64, r float64) {
-	pos := len(f.gradientList)
-	clr1 := colorValue(r1, g1, b1, "", "")
-	clr2 := colorValue(r2, g2, b2, "", "")
-	f.gradientList = append(f.gradientList, gradientType{tp, clr1.str, clr2.str,
-		x1, y1, x2, y2, r, 0})
-	f.outf("/Sh%d sh", pos)
+func (f *Fpdf) gradient(tp int, clr1, clr2 clrType, x1, y1 float64, x2, y2 float64, r float64) {
+	gr := gradientType{tp: tp, stops: []gradientStopType{{0, clr1.str}, {1, clr2.str}},
+		x1: x1, y1: y1, x2: x2, y2: y2, r: r, extend: [2]bool{true, true}}
+	if clr1.cmyk {
+		gr.spaceStr = "DeviceCMYK"
+	}
+	f.shade(gr)
+}
+
+// shade paints the current clipping area with the specified gradient. The
+// stops of the gradient must be in increasing order, beginning at 0 and
+// ending at 1.
+func (f *Fpdf) shade(gr gradientType) {
+	f.outf("/Sh%d sh", f.addShading(gr))
+}
+
+// addShading adds the specified gradient to the shadings of the document and
+// returns its position in the list
+func (f *Fpdf) addShading(gr gradientType) int {
+	f.gradientList = append(f.gradientList, gr)
+	return len(f.gradientList) - 1
 }
 
 // LinearGradient draws a rectangular area with a blending of one color to
@@ -1177,9 +1484,26 @@ func (f *Fpdf) gradient(tp int, r1, g1, b1 int, r2, g2, b2 int, x1, y1 float64,
 // anchored on the rectangle edge. Color 1 is used up to the origin of the
 // vector and color 2 is used beyond the vector's end point. Between the points
 // the colors are gradually blended.
+//
+// AddLinearGradient() and AddRadialGradient() define gradients with any
+// number of colors that can fill arbitrary shapes and text.
 func (f *Fpdf) LinearGradient(x, y, w, h float64, r1, g1, b1 int, r2, g2, b2 int, x1, y1, x2, y2 float64) {
 	f.gradientClipStart(x, y, w, h)
-	f.gradient(2, r1, g1, b1, r2, g2, b2, x1, y1, x2, y2, 0)
+	f.gradient(2, colorValue(r1, g1, b1, "", ""), colorValue(r2, g2, b2, "", ""), x1, y1, x2, y2, 0)
+	f.gradientClipEnd()
+}
+
+// LinearGradientCMYK draws a rectangular area with a blending of one color to
+// another in the DeviceCMYK color space. Each color is specified with cyan,
+// magenta, yellow and black components that range from 0 to 100 percent. In
+// every other respect it behaves like LinearGradient().
+//
+// The SetDrawColorCMYK() example demonstrates this method.
+func (f *Fpdf) LinearGradientCMYK(x, y, w, h float64, cyan1, magenta1, yellow1, black1 int,
+	cyan2, magenta2, yellow2, black2 int, x1, y1, x2, y2 float64) {
+	f.gradientClipStart(x, y, w, h)
+	f.gradient(2, colorValueCMYK(cyan1, magenta1, yellow1, black1, ""),
+		colorValueCMYK(cyan2, magenta2, yellow2, black2, ""), x1, y1, x2, y2, 0)
 	f.gradientClipEnd()
 }
 
@@ -1203,7 +1527,21 @@ func (f *Fpdf) LinearGradient(x, y, w, h float64, r1, g1, b1 int, r2, g2, b2 int
 // The LinearGradient() example demonstrates this method.
 func (f *Fpdf) RadialGradient(x, y, w, h float64, r1, g1, b1 int, r2, g2, b2 int, x1, y1, x2, y2, r float64) {
 	f.gradientClipStart(x, y, w, h)
-	f.gradient(3, r1, g1, b1, r2, g2, b2, x1, y1, x2, y2, r)
+	f.gradient(3, colorValue(r1, g1, b1, "", ""), colorValue(r2, g2, b2, "", ""), x1, y1, x2, y2, r)
+	f.gradientClipEnd()
+}
+
+// RadialGradientCMYK draws a rectangular area with a radial blending of one
+// color to another in the DeviceCMYK color space. Each color is specified with
+// cyan, magenta, yellow and black components that range from 0 to 100
+// percent. In every other respect it behaves like RadialGradient().
+//
+// The SetDrawColorCMYK() example demonstrates this method.
+func (f *Fpdf) RadialGradientCMYK(x, y, w, h float64, cyan1, magenta1, yellow1, black1 int,
+	cyan2, magenta2, yellow2, black2 int, x1, y1, x2, y2, r float64) {
+	f.gradientClipStart(x, y, w, h)
+	f.gradient(3, colorValueCMYK(cyan1, magenta1, yellow1, black1, ""),
+		colorValueCMYK(cyan2, magenta2, yellow2, black2, ""), x1, y1, x2, y2, r)
 	f.gradientClipEnd()
 }
 
@@ -1346,10 +1684,30 @@ func (f *Fpdf) ClipPolygon(points []PointType, outline bool) {
 	f.out(s.String())
 }
 
+// ClipPath begins a clipping operation with an arbitrary path, which is
+// built by fn with MoveTo(), LineTo(), CurveTo(), CurveBezierCubicTo(),
+// ArcTo() and ClosePath(). The path may consist of several subpaths, each
+// begun with MoveTo(), and open subpaths are implicitly closed. If evenOdd
+// is true, the even-odd rule determines the inside of the path, so that
+// subpaths within other subpaths are holes; otherwise the nonzero winding
+// number rule is used, as it is for the other clipping methods. outline is
+// true to draw the path with the current draw color and line width. After
+// calling this method, all rendering operations will be clipped by the
+// path. Call ClipEnd() to restore unclipped operations.
+//
+// The ClipPath() example demonstrates this method.
+func (f *Fpdf) ClipPath(fn func(), evenOdd, outline bool) {
+	f.clipNest++
+	f.out("q")
+	fn()
+	f.outf("%s %s", strIf(evenOdd, "W*", "W"), strIf(outline, "S", "n"))
+}
+
 // ClipEnd ends a clipping operation that was started with a call to
-// ClipRect(), ClipRoundedRect(), ClipText(), ClipEllipse(), ClipCircle() or
-// ClipPolygon(). Clipping operations can be nested. The document cannot be
-// successfully output while a clipping operation is active.
+// ClipRect(), ClipRoundedRect(), ClipText(), ClipEllipse(), ClipCircle(),
+// ClipPolygon(), ClipPath() or ClipSoftMask(). Clipping operations can be
+// nested. The document cannot be successfully output while a clipping
+// operation is active.
 //
 // The ClipText() example demonstrates this method.
 func (f *Fpdf) ClipEnd() {
@@ -1706,6 +2064,9 @@ func (f *Fpdf) AddLink() int {
 
 // SetLink defines the page and position a link points to. See AddLink().
 func (f *Fpdf) SetLink(link int, y float64, page int) {
+	if f.measure != nil {
+		return
+	}
 	if y == -1 {
 		y = f.y
 	}
@@ -1717,11 +2078,15 @@ func (f *Fpdf) SetLink(link int, y float64, page int) {
 
 // Add a new clickable link on current page
 func (f *Fpdf) newLink(x, y, w, h float64, link int, linkStr string) {
+	if f.measure != nil {
+		return
+	}
 	// linkList, ok := f.pageLinks[f.page]
 	// if !ok {
 	// linkList = make([]linkType, 0, 8)
 	// f.pageLinks[f.page] = linkList
 	// }
+	x, y = x+f.linkOffset.X, y+f.linkOffset.Y
 	f.pageLinks[f.page] = append(f.pageLinks[f.page],
 		linkType{x * f.k, f.hPt - y*f.k, w * f.k, h * f.k, link, linkStr})
 }
@@ -1748,6 +2113,9 @@ func (f *Fpdf) LinkString(x, y, w, h float64, linkStr string) {
 // vertical position of the bookmark destination in the current page; -1
 // indicates the current position.
 func (f *Fpdf) Bookmark(txtStr string, level int, y float64) {
+	if f.measure != nil {
+		return
+	}
 	if y == -1 {
 		y = f.y
 	}
@@ -1759,13 +2127,14 @@ func (f *Fpdf) Bookmark(txtStr string, level int, y float64) {
 // precisely on the page, but it is usually easier to use Cell(), MultiCell()
 // or Write() which are the standard methods to print text.
 func (f *Fpdf) Text(x, y float64, txtStr string) {
-	s := sprintf("BT %.2f %.2f Td (%s) Tj ET", x*f.k, (f.h-y)*f.k, f.escape(txtStr))
+	s := sprintf("BT %.2f %.2f Td (%s) Tj ET", x*f.k, (f.h-y)*f.k, f.crossRefText(f.escape(txtStr)))
 	if f.underline && txtStr != "" {
 		s += " " + f.dounderline(x, y, txtStr)
 	}
 	if f.colorFlag {
 		s = sprintf("q %s %s Q", f.color.text.str, s)
 	}
+	f.measureRect(x, y-f.fontSize, f.GetStringWidth(txtStr), f.fontSize)
 	f.out(s)
 }
 
@@ -1816,6 +2185,9 @@ func (f *Fpdf) SetAcceptPageBreakFunc(fnc func() bool) {
 //
 // fill is true to paint the cell background or false to leave it transparent.
 //
+// The background and a full border have rounded corners if a radius has been
+// set with SetCellRounding().
+//
 // link is the identifier returned by AddLink() or 0 for no internal link.
 //
 // linkStr is a target URL or empty for no external link. A non--zero value for
@@ -1827,7 +2199,7 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr string, borderStr string, ln int,
 	}
 	borderStr = strings.ToUpper(borderStr)
 	k := f.k
-	if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.acceptPageBreak() {
+	if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.pageBreakAllowed() && f.acceptPageBreak() {
 		// Automatic page break
 		x := f.x
 		ws := f.ws
@@ -1849,8 +2221,17 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr string, borderStr string, ln int,
 	if w == 0 {
 		w = f.w - f.rMargin - f.x
 	}
+	f.measureRect(f.x, f.y, w, h)
 	var s fmtBuffer
-	if fill || borderStr == "1" {
+	if f.cRadius > 0 && (fill || borderStr == "1") {
+		rTL, rTR, rBR, rBL := cornerRadii(f.cRadius, f.cCornersStr)
+		s.printf("%s", f.roundedRectPath(f.x, f.y, w, h, rTL, rTR, rBR, rBL))
+		if fill {
+			s.printf("%s ", strIf(borderStr == "1", "B", "f"))
+		} else {
+			s.printf("S ")
+		}
+	} else if fill || borderStr == "1" {
 		var op string
 		if fill {
 			if borderStr == "1" {
@@ -1922,6 +2303,7 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr string, borderStr string, ln int,
 		txt2 := strings.Replace(txtStr, "\\", "\\\\", -1)
 		txt2 = strings.Replace(txt2, "(", "\\(", -1)
 		txt2 = strings.Replace(txt2, ")", "\\)", -1)
+		txt2 = f.crossRefText(txt2)
 		// if strings.Contains(txt2, "end of excerpt") {
 		// dbg("f.h %.2f, f.y %.2f, h %.2f, f.fontSize %.2f, k %.2f", f.h, f.y, h, f.fontSize, k)
 		// }
@@ -1985,13 +2367,18 @@ func (f *Fpdf) SplitLines(txt []byte, w float64) [][]byte {
 		nb--
 	}
 	s = s[0:nb]
+	rw := f.crossRefWidths(string(s))
 	sep := -1
 	i := 0
 	j := 0
 	l := 0
 	for i < nb {
 		c := s[i]
-		l += cw[c]
+		if rw != nil {
+			l += rw[i]
+		} else {
+			l += cw[c]
+		}
 		if c == ' ' || c == '\t' || c == '\n' {
 			sep = i
 		}
@@ -2047,6 +2434,7 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 		s = s[0:nb]
 	}
 	// dbg("[%s]\n", s)
+	rw := f.crossRefWidths(s)
 	var b, b2 string
 	b = "0"
 	if len(borderStr) > 0 {
@@ -2102,7 +2490,11 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 			ls = l
 			ns++
 		}
-		l += float64(cw[c])
+		if rw != nil {
+			l += float64(rw[i])
+		} else {
+			l += float64(cw[c])
+		}
 		if l > wmax {
 			// Automatic line break
 			if sep == -1 {
@@ -2158,6 +2550,7 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 	wmax := (w - 2*f.cMargin) * 1000 / f.fontSize
 	s := strings.Replace(txtStr, "\r", "", -1)
 	nb := len(s)
+	rw := f.crossRefWidths(s)
 	sep := -1
 	i := 0
 	j := 0
@@ -2184,7 +2577,11 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 		if c == ' ' {
 			sep = i
 		}
-		l += float64(cw[c])
+		if rw != nil {
+			l += float64(rw[i])
+		} else {
+			l += float64(cw[c])
+		}
 		if l > wmax {
 			// Automatic line break
 			if sep == -1 {
@@ -2362,7 +2759,7 @@ func (f *Fpdf) imageOut(info *ImageInfoType, x, y, w, h float64, flow bool, link
 	}
 	// Flowing mode
 	if flow {
-		if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.acceptPageBreak() {
+		if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.pageBreakAllowed() && f.acceptPageBreak() {
 			// Automatic page break
 			x2 := f.x
 			f.AddPageFormat(f.curOrientation, f.curPageSize)
@@ -2378,6 +2775,7 @@ func (f *Fpdf) imageOut(info *ImageInfoType, x, y, w, h float64, flow bool, link
 		x = f.x
 	}
 	// dbg("h %.2f", h)
+	f.measureRect(x, y, w, h)
 	// q 85.04 0 0 NaN 28.35 NaN cm /I2 Do Q
 	f.outf("q %.5f 0 0 %.5f %.5f %.5f cm /I%d Do Q", w*f.k, h*f.k, x*f.k, (f.h-(y+h))*f.k, info.i)
 	if link > 0 || len(linkStr) > 0 {
@@ -2733,9 +3131,10 @@ func (f *Fpdf) beginpage(orientationStr string, size SizeType) {
 	if f.err != nil {
 		return
 	}
-	f.page++
 	f.pages = append(f.pages, bytes.NewBufferString(""))
 	f.pageLinks = append(f.pageLinks, make([]linkType, 0, 0))
+	f.page = len(f.pages) - 1
+	f.openPage = f.page
 	f.state = 2
 	f.x = f.lMargin
 	f.y = f.tMargin
@@ -2767,11 +3166,83 @@ func (f *Fpdf) beginpage(orientationStr string, size SizeType) {
 	return
 }
 
+// finishpage outputs the footer of the open page, that is, the page most
+// recently added, and closes the page. The open page is selected first if an
+// other page has been selected with SetPage().
+func (f *Fpdf) finishpage() {
+	if f.openPage > 0 {
+		f.SetPage(f.openPage)
+		f.openPage = 0
+		// Page footer
+		if f.footerFnc != nil {
+			f.inFooter = true
+			f.footerFnc()
+			f.inFooter = false
+		}
+	}
+	// Close page
+	f.endpage()
+}
+
 func (f *Fpdf) endpage() {
 	f.EndLayer()
+	if f.alpha != 1 || (f.blendMode != "Normal" && f.blendMode != "") {
+		// Record transparency in effect at end of page for SetPage()
+		if f.pageStates == nil {
+			f.pageStates = make(map[int]pageStateType)
+		}
+		f.pageStates[f.page] = pageStateType{alpha: f.alpha, blendMode: f.blendMode}
+	}
 	f.state = 1
 }
 
+// Call the page stamp function for each page of the closed document
+func (f *Fpdf) stamppages() {
+	familyStr, styleStr, underline, fontsize := f.fontFamily, f.fontStyle, f.underline, f.fontSizePt
+	lw := f.lineWidth
+	dc, fc, tc, cf := f.color.draw, f.color.fill, f.color.text, f.colorFlag
+	alpha, blendModeStr := f.alpha, f.blendMode
+	w, h, wPt, hPt := f.w, f.h, f.wPt, f.hPt
+	pageBreakTrigger := f.pageBreakTrigger
+	acceptPageBreak := f.acceptPageBreak
+	f.acceptPageBreak = func() bool { return false }
+	f.inStamp = true
+	nb := f.PageCount()
+	for n := 1; n <= nb && f.err == nil; n++ {
+		f.page = n
+		f.state = 2
+		f.setpagedims(n)
+		// Isolate the existing page content in its own graphics state
+		content := f.pages[n].String()
+		f.pages[n].Truncate(0)
+		f.pages[n].WriteString("q\n")
+		f.pages[n].WriteString(content)
+		f.pages[n].WriteString("Q\n")
+		f.fontFamily, f.fontStyle, f.underline, f.fontSizePt = familyStr, styleStr, underline, fontsize
+		f.lineWidth = lw
+		f.color.draw, f.color.fill, f.color.text, f.colorFlag = dc, fc, tc, cf
+		f.alpha, f.blendMode = alpha, blendModeStr
+		f.outpagestate(1, "Normal")
+		f.x = f.lMargin
+		f.y = f.tMargin
+		f.pageStampFnc(n, nb, SizeType{Wd: f.w, Ht: f.h})
+		if f.err == nil {
+			if f.clipNest > 0 {
+				f.err = fmt.Errorf("clip procedure must be explicitly ended")
+			} else if f.transformNest > 0 {
+				f.err = fmt.Errorf("transformation procedure must be explicitly ended")
+			}
+		}
+		f.EndLayer()
+	}
+	f.inStamp = false
+	f.acceptPageBreak = acceptPageBreak
+	f.page = nb
+	f.state = 1
+	f.w, f.h, f.wPt, f.hPt = w, h, wPt, hPt
+	f.pageBreakTrigger = pageBreakTrigger
+}
+
 // Load a font definition file from the given Reader
 func (f *Fpdf) loadfont(r io.Reader) (def fontDefType) {
 	if f.err != nil {
@@ -2878,6 +3349,9 @@ func (f *Fpdf) parsejpg(r io.Reader) (info *ImageInfoType) {
 		f.err = fmt.Errorf("image JPEG buffer has unsupported color space (%v)", config.ColorModel)
 		return
 	}
+	if profile := jpegICCProfile(info.data); iccComponents(profile) == imageComponents(info.cs) {
+		info.icc = profile
+	}
 	return
 }
 
@@ -2950,8 +3424,32 @@ func (f *Fpdf) putstream(b []byte) {
 	f.out("endstream")
 }
 
+// Replace cross-references and the alias for the total number of pages in
+// the specified page content
+func (f *Fpdf) resolvepage(page *bytes.Buffer, nb int) {
+	if f.crossRef {
+		s := page.String()
+		if strings.Contains(s, "{ref") {
+			s = f.crossRefResolve(s)
+			page.Truncate(0)
+			page.WriteString(s)
+		}
+	}
+	if len(f.aliasNbPagesStr) > 0 {
+		s := page.String()
+		if strings.Contains(s, f.aliasNbPagesStr) {
+			s = strings.Replace(s, f.aliasNbPagesStr, sprintf("%d", nb), -1)
+			page.Truncate(0)
+			page.WriteString(s)
+		}
+	}
+}
+
 // Add a line to the document
 func (f *Fpdf) out(s string) {
+	if f.measure != nil {
+		return
+	}
 	if f.state == 2 {
 		f.pages[f.page].WriteString(s)
 		f.pages[f.page].WriteString("\n")
@@ -2963,6 +3461,9 @@ func (f *Fpdf) out(s string) {
 
 // Add a buffered line to the document
 func (f *Fpdf) outbuf(b *bytes.Buffer) {
+	if f.measure != nil {
+		return
+	}
 	if f.state == 2 {
 		f.pages[f.page].ReadFrom(b)
 		f.pages[f.page].WriteString("\n")
@@ -3027,18 +3528,9 @@ func (f *Fpdf) putpages() {
 	var pageSize SizeType
 	// var linkList []linkType
 	var ok bool
-	nb := f.page
-	if len(f.aliasNbPagesStr) > 0 {
-		// Replace number of pages
-		nbStr := sprintf("%d", nb)
-		for n := 1; n <= nb; n++ {
-			s := f.pages[n].String()
-			if strings.Contains(s, f.aliasNbPagesStr) {
-				s = strings.Replace(s, f.aliasNbPagesStr, nbStr, -1)
-				f.pages[n].Truncate(0)
-				f.pages[n].WriteString(s)
-			}
-		}
+	nb := f.PageCount()
+	for n := 1; n <= nb; n++ {
+		f.resolvepage(f.pages[n], nb)
 	}
 	if f.defOrientation == "P" {
 		wPt = f.defPageSize.Wd * f.k
@@ -3295,6 +3787,10 @@ func (f *Fpdf) putimages() {
 }
 
 func (f *Fpdf) putimage(info *ImageInfoType) {
+	var iccStr string
+	if len(info.icc) > 0 {
+		iccStr = sprintf("[/ICCBased %d 0 R]", f.iccPutProfile(info.icc))
+	}
 	f.newobj()
 	info.n = f.n
 	f.out("<</Type /XObject")
@@ -3302,9 +3798,16 @@ func (f *Fpdf) putimage(info *ImageInfoType) {
 	f.outf("/Width %d", int(info.w))
 	f.outf("/Height %d", int(info.h))
 	if info.cs == "Indexed" {
-		f.outf("/ColorSpace [/Indexed /DeviceRGB %d %d 0 R]", len(info.pal)/3-1, f.n+1)
+		if iccStr == "" {
+			iccStr = "/DeviceRGB"
+		}
+		f.outf("/ColorSpace [/Indexed %s %d %d 0 R]", iccStr, len(info.pal)/3-1, f.n+1)
 	} else {
-		f.outf("/ColorSpace /%s", info.cs)
+		if iccStr != "" {
+			f.outf("/ColorSpace %s", iccStr)
+		} else {
+			f.outf("/ColorSpace /%s", info.cs)
+		}
 		if info.cs == "DeviceCMYK" {
 			f.out("/Decode [1 0 1 0 1 0 1 0]")
 		}
@@ -3413,11 +3916,12 @@ func (f *Fpdf) putresourcedict() {
 	f.putxobjectdict()
 	f.out(">>")
 	count := len(f.blendList)
-	if count > 1 {
+	if count > 1 || len(f.softMaskList) > 1 {
 		f.out("/ExtGState <<")
 		for j := 1; j < count; j++ {
 			f.outf("/GS%d %d 0 R", j, f.blendList[j].objNum)
 		}
+		f.softMaskPutExtGStates()
 		f.out(">>")
 	}
 	count = len(f.gradientList)
@@ -3428,6 +3932,10 @@ func (f *Fpdf) putresourcedict() {
 		}
 		f.out(">>")
 	}
+	// Spot colors
+	f.spotColorPutResourceDict()
+	// Tiling patterns
+	f.patternPutResourceDict()
 	// Layers
 	f.layerPutResourceDict()
 }
@@ -3451,24 +3959,74 @@ func (f *Fpdf) putGradients() {
 		gr := f.gradientList[j]
 		if gr.tp == 2 || gr.tp == 3 {
 			f.newobj()
-			f.outf("<</FunctionType 2 /Domain [0.0 1.0] /C0 [%s] /C1 [%s] /N 1>>", gr.clr1Str, gr.clr2Str)
+			f.out(gradientFunction(gr.stops))
 			f.out("endobj")
 			f1 = f.n
 		}
+		spaceStr := gr.spaceStr
+		if spaceStr == "" {
+			spaceStr = "DeviceRGB"
+		}
+		r1Str := "0"
+		if gr.r1 != 0 {
+			r1Str = sprintf("%.5f", gr.r1)
+		}
 		f.newobj()
-		f.outf("<</ShadingType %d /ColorSpace /DeviceRGB", gr.tp)
+		f.outf("<</ShadingType %d /ColorSpace /%s", gr.tp, spaceStr)
 		if gr.tp == 2 {
-			f.outf("/Coords [%.5f %.5f %.5f %.5f] /Function %d 0 R /Extend [true true]>>",
-				gr.x1, gr.y1, gr.x2, gr.y2, f1)
+			f.outf("/Coords [%.5f %.5f %.5f %.5f] /Function %d 0 R /Extend [%t %t]>>",
+				gr.x1, gr.y1, gr.x2, gr.y2, f1, gr.extend[0], gr.extend[1])
 		} else if gr.tp == 3 {
-			f.outf("/Coords [%.5f %.5f 0 %.5f %.5f %.5f] /Function %d 0 R /Extend [true true]>>",
-				gr.x1, gr.y1, gr.x2, gr.y2, gr.r, f1)
+			f.outf("/Coords [%.5f %.5f %s %.5f %.5f %.5f] /Function %d 0 R /Extend [%t %t]>>",
+				gr.x1, gr.y1, r1Str, gr.x2, gr.y2, gr.r, f1, gr.extend[0], gr.extend[1])
+		} else {
+			// Mesh with 32-bit coordinates and 8-bit flags and color components
+			f.out("/BitsPerCoordinate 32 /BitsPerComponent 8")
+			if gr.tp == 5 {
+				f.outf("/VerticesPerRow %d", gr.perRow)
+			} else {
+				f.out("/BitsPerFlag 8")
+			}
+			f.outf("/Decode [%s]", gr.decodeStr)
+			data := gr.data
+			if f.compress {
+				data = sliceCompress(data)
+				f.outf("/Filter /FlateDecode /Length %d>>", len(data))
+			} else {
+				f.outf("/Length %d>>", len(data))
+			}
+			f.putstream(data)
 		}
 		f.out("endobj")
 		f.gradientList[j].objNum = f.n
 	}
 }
 
+// gradientFunction returns the function dictionary that interpolates between
+// the specified stops. Each pair of adjacent stops is interpolated by an
+// exponential function, and the functions are combined with a stitching
+// function if there are more than two stops. Stops at the same position
+// produce an abrupt change of color.
+func gradientFunction(stops []gradientStopType) string {
+	var funcs, bounds []string
+	for j := 1; j < len(stops); j++ {
+		if stops[j].pos <= stops[j-1].pos && len(stops) > 2 {
+			continue
+		}
+		if len(funcs) > 0 {
+			bounds = append(bounds, sprintf("%.5f", stops[j-1].pos))
+		}
+		funcs = append(funcs, sprintf("<</FunctionType 2 /Domain [0.0 1.0] /C0 [%s] /C1 [%s] /N 1>>",
+			stops[j-1].clrStr, stops[j].clrStr))
+	}
+	if len(funcs) == 1 {
+		return funcs[0]
+	}
+	encode := strings.Repeat("0 1 ", len(funcs))
+	return sprintf("<</FunctionType 3 /Domain [0.0 1.0] /Functions [%s] /Bounds [%s] /Encode [%s]>>",
+		strings.Join(funcs, " "), strings.Join(bounds, " "), encode[:len(encode)-1])
+}
+
 func (f *Fpdf) putresources() {
 	if f.err != nil {
 		return
@@ -3476,12 +4034,16 @@ func (f *Fpdf) putresources() {
 	f.layerPutLayers()
 	f.putBlendModes()
 	f.putGradients()
+	f.spotColorPutColorSpaces()
 	f.putfonts()
 	if f.err != nil {
 		return
 	}
 	f.putimages()
 	f.putTemplates()
+	f.patternPutPatterns()
+	f.softMaskPutSoftMasks()
+	f.iccPutOutputIntent()
 	// 	Resource dictionary
 	f.offsets[2] = f.buffer.Len()
 	f.out("2 0 obj")
@@ -3566,6 +4128,8 @@ func (f *Fpdf) putcatalog() {
 	}
 	// Layers
 	f.layerPutCatalog()
+	// Output intent
+	f.iccPutCatalog()
 }
 
 func (f *Fpdf) putheader() {
@@ -3749,7 +4313,11 @@ func (f *Fpdf) ClosePath() {
 // DrawPath actually draws the path on the page.
 //
 // styleStr can be "F" for filled, "D" for outlined only, or "DF" or "FD" for
-// outlined and filled. An empty string will be replaced with "D".
+// outlined and filled. An empty string will be replaced with "D". Filling
+// uses the nonzero winding number rule, unless "*" is appended to the style,
+// as in "F*" or "FD*", to select the even-odd rule. With the even-odd rule, a
+// subpath that lies within another one is a hole regardless of its
+// direction, which makes shapes such as rings and letterforms easy to draw.
 // Path-painting operators as defined in the PDF specification are also
 // allowed: "S" (Stroke the path), "s" (Close and stroke the path),
 // "f" (fill the path, using the nonzero winding number), "f*"
@@ -3765,7 +4333,7 @@ func (f *Fpdf) ClosePath() {
 //
 // The MoveTo() example demonstrates this method.
 func (f *Fpdf) DrawPath(styleStr string) {
-	f.outf(fillDrawOp(styleStr))
+	f.out(fillDrawOp(styleStr))
 }
 
 // ArcTo draws an elliptical arc centered at point (x, y). rx and ry specify its
@@ -3789,11 +4357,21 @@ func (f *Fpdf) ArcTo(x, y, rx, ry, degRotate, degStart, degEnd float64) {
 
 func (f *Fpdf) arc(x, y, rx, ry, degRotate, degStart, degEnd float64,
 	styleStr string, path bool) {
+	if !path {
+		if degRotate == 0 {
+			f.measureRect(x-rx, y-ry, 2*rx, 2*ry)
+		} else {
+			r := math.Max(rx, ry)
+			f.measureRect(x-r, y-r, 2*r, 2*r)
+		}
+	}
+
 	x *= f.k
 	y = (f.h - y) * f.k
 	rx *= f.k
 	ry *= f.k
 	segments := int(degEnd-degStart) / 60
+
 	if segments < 2 {
 		segments = 2
 	}
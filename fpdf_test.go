@@ -19,6 +19,7 @@ package gofpdf_test
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/jung-kurt/gofpdf/internal/example"
@@ -86,6 +87,78 @@ func lorem() string {
 		"officia deserunt mollit anim id est laborum."
 }
 
+// srgbProfile returns a minimal ICC display profile with the primaries and
+// white point of sRGB and a simple gamma of 2.2 in place of the sRGB tone
+// curve. It is built here so that no third-party profile needs to be
+// distributed with the examples.
+func srgbProfile() []byte {
+	var tags bytes.Buffer
+	type tagType struct {
+		sig      string
+		pos, len int
+	}
+	var table []tagType
+	fixed := func(buf *bytes.Buffer, vals ...float64) {
+		for _, v := range vals {
+			binary.Write(buf, binary.BigEndian, int32(math.Floor(v*65536+0.5)))
+		}
+	}
+	add := func(data func(), sigs ...string) {
+		pos := tags.Len()
+		data()
+		for tags.Len()%4 != 0 {
+			tags.WriteByte(0)
+		}
+		for _, sig := range sigs {
+			table = append(table, tagType{sig, pos, tags.Len() - pos})
+		}
+	}
+	xyz := func(x, y, z float64) func() {
+		return func() {
+			tags.WriteString("XYZ \x00\x00\x00\x00")
+			fixed(&tags, x, y, z)
+		}
+	}
+	add(func() {
+		const descStr = "sRGB primaries, gamma 2.2"
+		tags.WriteString("desc\x00\x00\x00\x00")
+		binary.Write(&tags, binary.BigEndian, uint32(len(descStr)+1))
+		tags.WriteString(descStr + "\x00")
+		// Empty Unicode and ScriptCode descriptions
+		tags.Write(make([]byte, 8+3+67))
+	}, "desc")
+	add(func() {
+		tags.WriteString("text\x00\x00\x00\x00No copyright, use freely\x00")
+	}, "cprt")
+	add(xyz(0.9642, 1.0, 0.8249), "wtpt")
+	add(xyz(0.4361, 0.2225, 0.0139), "rXYZ")
+	add(xyz(0.3851, 0.7169, 0.0971), "gXYZ")
+	add(xyz(0.1431, 0.0606, 0.7141), "bXYZ")
+	add(func() {
+		// A single entry is a gamma value in u8Fixed8Number format
+		tags.WriteString("curv\x00\x00\x00\x00\x00\x00\x00\x01\x02\x33")
+	}, "rTRC", "gTRC", "bTRC")
+	base := 128 + 4 + 12*len(table)
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(base+tags.Len()))
+	buf.Write(make([]byte, 4))
+	buf.WriteString("\x02\x10\x00\x00mntrRGB XYZ ")
+	buf.Write(make([]byte, 12))
+	buf.WriteString("acsp")
+	buf.Write(make([]byte, 28))
+	// Illuminant of the profile connection space
+	fixed(&buf, 0.9642, 1.0, 0.8249)
+	buf.Write(make([]byte, 128-buf.Len()))
+	binary.Write(&buf, binary.BigEndian, uint32(len(table)))
+	for _, tag := range table {
+		buf.WriteString(tag.sig)
+		binary.Write(&buf, binary.BigEndian, uint32(base+tag.pos))
+		binary.Write(&buf, binary.BigEndian, uint32(tag.len))
+	}
+	buf.Write(tags.Bytes())
+	return buf.Bytes()
+}
+
 // This example demonstrates the generation of a simple PDF document. Note that
 // since only core fonts are used (in this case Arial, a synonym for
 // Helvetica), an empty string can be specified for the font directory in the
@@ -1171,6 +1244,164 @@ func ExampleFpdf_SVGBasicWrite() {
 	// Successfully generated pdf/Fpdf_SVGBasicWrite.pdf
 }
 
+// This example demonstrates the SVG path grammar, including horizontal and
+// vertical lines, smooth and quadratic curves, elliptical arcs, closed paths
+// and the compact number syntax that is common in icons.
+func ExampleFpdf_SVGBasicWrite_paths() {
+	svgStr := `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100">
+	<path d="M10,30A20,20,0,0,1,50,30A20,20,0,0,1,90,30Q90,60,50,90Q10,60,10,30z"/>
+	<path d="m110 10h80v80h-80zm10 10v60h60V20z"/>
+	<path d="M120 50c10-20 20-20 30 0s20 20 30 0"/>
+	<path d="M120 70q7.5-15 15 0t15 0 15 0 15 0"/>
+	<path d="M100 95a95 5 0 1 0 1e-3 0"/>
+	</svg>`
+	sig, err := gofpdf.SVGBasicParse([]byte(svgStr))
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	if err == nil {
+		for _, path := range sig.Segments {
+			for _, seg := range path {
+				fmt.Printf("%c", seg.Cmd)
+			}
+			fmt.Println()
+		}
+		pdf.SetLineWidth(0.5)
+		pdf.SetDrawColor(128, 0, 0)
+		pdf.SetXY(10, 20)
+		pdf.SVGBasicWrite(&sig, 190/sig.Wd)
+	} else {
+		pdf.SetError(err)
+	}
+	fileStr := example.Filename("Fpdf_SVGBasicWrite_paths")
+	err = pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// MCCCCCCZ
+	// MLLLZMLLLZ
+	// MCC
+	// MCCCC
+	// MCCCC
+	// Successfully generated pdf/Fpdf_SVGBasicWrite_paths.pdf
+}
+
+// This example demonstrates the basic SVG shapes, groups with
+// transformations, and the styling of shapes with presentation attributes
+// and style attributes.
+func ExampleFpdf_SVGBasicWrite_shapes() {
+	svgStr := `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="120">
+	<g stroke="navy" stroke-width="2">
+	  <rect x="5" y="5" width="50" height="30" rx="6" fill="#9cf"/>
+	  <circle cx="85" cy="20" r="15" fill="orange" fill-opacity="0.5"/>
+	  <ellipse cx="135" cy="20" rx="25" ry="12" style="fill:rgb(60,180,75)"/>
+	  <line x1="165" y1="5" x2="195" y2="35" stroke-linecap="round" stroke-width="4"/>
+	</g>
+	<polyline points="5,75 20,50 35,75 50,50 65,75" fill="none" stroke="crimson"
+	  stroke-width="3" stroke-linejoin="round" stroke-dasharray="6 3"/>
+	<polygon points="100,45 107,66 129,66 111,79 118,100 100,87 82,100 89,79 71,66 93,66"
+	  fill="gold" stroke="black" fill-rule="evenodd"/>
+	<g transform="translate(160 75)" fill="purple">
+	  <rect x="-10" y="-10" width="20" height="20" opacity="0.3"/>
+	  <rect x="-10" y="-10" width="20" height="20" transform="rotate(30)" opacity="0.3"/>
+	  <rect x="-10" y="-10" width="20" height="20" transform="rotate(60)" opacity="0.3"/>
+	  <g transform="scale(0.5) skewX(20)">
+	    <rect x="-10" y="-10" width="20" height="20" fill="white" display="none"/>
+	    <circle r="8" fill="white"/>
+	  </g>
+	</g>
+	<path d="M10 90h50v25h-50z" stroke="teal" stroke-width="1" fill="none"/>
+	</svg>`
+	sig, err := gofpdf.SVGBasicParse([]byte(svgStr))
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	if err == nil {
+		fmt.Printf("%d shapes\n", len(sig.Segments))
+		pdf.SetXY(10, 20)
+		pdf.SVGBasicWrite(&sig, 190/sig.Wd)
+	} else {
+		pdf.SetError(err)
+	}
+	fileStr := example.Filename("Fpdf_SVGBasicWrite_shapes")
+	err = pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// 11 shapes
+	// Successfully generated pdf/Fpdf_SVGBasicWrite_shapes.pdf
+}
+
+// This example demonstrates SVG gradients, clipping paths, references to
+// definitions with use elements, and text. A badge that uses several of
+// these features is rendered below the chart.
+func ExampleFpdf_SVGBasicWrite_defs() {
+	svgStr := `<svg xmlns="http://www.w3.org/2000/svg"
+	  xmlns:xlink="http://www.w3.org/1999/xlink" width="200" height="130">
+	<defs>
+	  <linearGradient id="bar" x1="0" y1="1" x2="0" y2="0">
+	    <stop offset="0" stop-color="#036"/>
+	    <stop offset="0.6" stop-color="#39c"/>
+	    <stop offset="1" style="stop-color:#cef"/>
+	  </linearGradient>
+	  <linearGradient id="hot" xlink:href="#bar">
+	    <stop offset="0" stop-color="darkred"/>
+	    <stop offset="1" stop-color="orange"/>
+	  </linearGradient>
+	  <radialGradient id="ball" cx="0.5" cy="0.5" r="0.5" fx="0.35" fy="0.3">
+	    <stop offset="0" stop-color="white"/>
+	    <stop offset="0.3" stop-color="gold"/>
+	    <stop offset="1" stop-color="#a60"/>
+	  </radialGradient>
+	  <clipPath id="frame">
+	    <rect x="10" y="10" width="120" height="100" rx="8"/>
+	  </clipPath>
+	  <clipPath id="disc" clipPathUnits="objectBoundingBox">
+	    <circle cx="0.5" cy="0.5" r="0.5"/>
+	  </clipPath>
+	  <g id="marker">
+	    <circle r="4" fill="url(#ball)" stroke="#a60" stroke-width="0.5"/>
+	  </g>
+	</defs>
+	<g clip-path="url(#frame)">
+	  <rect x="10" y="10" width="120" height="100" fill="#eee"/>
+	  <path d="M0 30H140M0 50H140M0 70H140M0 90H140" stroke="#ccc"/>
+	  <rect x="25" y="50" width="20" height="60" fill="url(#bar)"/>
+	  <rect x="60" y="30" width="20" height="80" fill="url(#hot)"/>
+	  <rect x="95" y="70" width="20" height="40" fill="url(#bar)"/>
+	  <circle cx="120" cy="100" r="30" fill="none" stroke="crimson" stroke-width="3"/>
+	</g>
+	<use xlink:href="#marker" x="35" y="50"/>
+	<use xlink:href="#marker" x="70" y="30"/>
+	<use xlink:href="#marker" x="105" y="70"/>
+	<circle cx="170" cy="40" r="25" fill="url(#ball)"/>
+	<g clip-path="url(#disc)">
+	  <rect x="145" y="75" width="50" height="50" fill="url(#hot)"/>
+	</g>
+	<g font-family="Helvetica, sans-serif" font-size="8" text-anchor="middle">
+	  <text x="35" y="125">North</text>
+	  <text x="70" y="125" font-weight="bold" fill="darkred">Central</text>
+	  <text x="105" y="125">South</text>
+	  <text x="170" y="105" font-size="7" fill="white"><tspan
+	    font-style="italic">Q</tspan>4 <tspan font-weight="bold">+12%</tspan></text>
+	</g>
+	</svg>`
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	sig, err := gofpdf.SVGBasicParse([]byte(svgStr))
+	if err == nil {
+		pdf.SetXY(10, 20)
+		pdf.SVGBasicWrite(&sig, 190/sig.Wd)
+		sig, err = gofpdf.SVGBasicFileParse(example.ImageFile("mit.svg"))
+		if err == nil {
+			pdf.SetXY(10, 150)
+			pdf.SVGBasicWrite(&sig, 60/sig.Wd)
+		}
+	}
+	pdf.SetError(err)
+	fileStr := example.Filename("Fpdf_SVGBasicWrite_defs")
+	err = pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_SVGBasicWrite_defs.pdf
+}
+
 // This example demonstrates Stefan Schroeder's code to control vertical
 // alignment.
 func ExampleFpdf_CellFormat_align() {
@@ -1761,3 +1992,1453 @@ func ExampleFpdf_AddFontFromBytes() {
 	// Output:
 	// Successfully generated pdf/Fpdf_EmbeddedFont.pdf
 }
+
+// This example demonstrates how to determine the extent of output before it is
+// rendered. A paragraph is measured with a dry run so that a shaded box of the
+// proper height can be drawn behind it.
+func ExampleFpdf_Measure() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Times", "", 12)
+	pdf.AddPage()
+	txtStr := lorem()
+	pdf.SetXY(30, 40)
+	m := pdf.Measure(func() {
+		pdf.MultiCell(150, 5, txtStr, "", "", false)
+	})
+	sz := m.Extent()
+	fmt.Printf("Pages %d, width %.2f, height %.2f, final y %.2f\n", m.Pages, sz.Wd, sz.Ht, m.Y)
+	x, y := pdf.GetXY()
+	fmt.Printf("Position after measuring %.2f, %.2f\n", x, y)
+	pdf.SetFillColor(230, 230, 200)
+	pdf.Rect(m.Min.X-2, m.Min.Y-2, sz.Wd+4, sz.Ht+4, "F")
+	pdf.MultiCell(150, 5, txtStr, "", "", false)
+	fileStr := example.Filename("Fpdf_Measure")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Pages 1, width 150.00, height 30.00, final y 70.00
+	// Position after measuring 30.00, 40.00
+	// Successfully generated pdf/Fpdf_Measure.pdf
+}
+
+// This example demonstrates bulleted and numbered lists, both with the list
+// methods and with basic HTML.
+func ExampleFpdf_ListNew() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	_, lineHt := pdf.GetFontSize()
+	lineHt *= 1.5
+	list := pdf.ListNew()
+	list.Levels = []gofpdf.ListLevelType{
+		{Numbering: "1."},
+		{Numbering: "a)"},
+		{Bullet: "l", FontFamily: "ZapfDingbats", FontSize: 6},
+		{Numbering: "(i)"},
+	}
+	list.Align = "J"
+	list.Item(lineHt, 0, "Numbered items wrap with a hanging indent. "+lorem())
+	list.Item(lineHt, 1, "Second level items are lettered.")
+	list.Item(lineHt, 2, "Bullets can be taken from the ZapfDingbats font.")
+	list.Item(lineHt, 2, "A second bullet.")
+	list.Item(lineHt, 3, "Roman numerals are available too.")
+	list.Item(lineHt, 3, "Another one.")
+	list.Item(lineHt, 1, "Numbering of a level continues until an item at a shallower level intervenes.")
+	list.Item(lineHt, 0, "Back at the top level.")
+	pdf.Ln(lineHt)
+	html := pdf.HTMLBasicNew()
+	html.Write(lineHt, `Lists can also be written with <b>basic HTML</b>:`+
+		`<ul>`+
+		`<li>An <i>unordered</i> list item</li>`+
+		`<li>An item with a nested ordered list`+
+		`<ol>`+
+		`<li>First</li>`+
+		`<li>Second, with text long enough to wrap onto the following line `+
+		`so that the hanging indent is evident</li>`+
+		`</ol>`+
+		`</li>`+
+		`<li>A <a href="http://www.fpdf.org">linked</a> item</li>`+
+		`</ul>`+
+		`Text after the list.`)
+
+	fileStr := example.Filename("Fpdf_ListNew")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_ListNew.pdf
+}
+
+// This example demonstrates a back-of-book index. Index terms are recorded
+// as the document is generated and are written in columns at the end.
+func ExampleFpdf_Index() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Times", "", 12)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.CellFormat(0, 10, fmt.Sprintf("%d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	colors := []string{"Blue", "Green", "Red", "Yellow", "Orange", "Violet"}
+	shapes := []string{"Circles", "Squares", "Triangles", "gadgets", "Widgets",
+		"Ellipses", "Hexagons", "Stars"}
+	for j := 0; j < 16; j++ {
+		pdf.AddPage()
+		pdf.IndexEntry("Sections", fmt.Sprintf("Section %c", 'A'+j))
+		for k := 0; k < 3; k++ {
+			shape := shapes[(j+k)%len(shapes)]
+			color := colors[(j*k)%len(colors)]
+			pdf.IndexEntry(shape)
+			pdf.IndexEntry(shape, color)
+			if j < 4 || j == 9 {
+				pdf.IndexEntry("Lorem ipsum")
+			}
+			pdf.MultiCell(0, 5, fmt.Sprintf("%s %s. %s", color, shape, lorem()), "", "", false)
+			pdf.Ln(5)
+		}
+	}
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Index", "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.Index(gofpdf.IndexOptions{LineHt: 6, ColCount: 3})
+	fileStr := example.Filename("Fpdf_Index")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_Index.pdf
+}
+
+// This example demonstrates forward cross-references. References to pages
+// are written before the pages they refer to exist, and are resolved when the
+// document is closed.
+func ExampleFpdf_SetAnchor() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AliasCrossRefs(6)
+	pdf.SetFont("Times", "", 12)
+	pdf.AddPage()
+	pdf.CellFormat(0, 6, "Results are summarized on page {ref:summary}.", "", 1, "R", false, 0, "")
+	pdf.Ln(4)
+	pdf.MultiCell(0, 6, "The method is described in the appendix, starting on page "+
+		"{ref:appendix}, and the results are summarized on page {ref:summary}. "+lorem(), "", "J", false)
+	pdf.AddPage()
+	pdf.SetAnchor("summary")
+	pdf.Write(6, "Summary. See also page {ref:appendix}.")
+	pdf.AddPage()
+	pdf.AddPage()
+	pdf.SetAnchor("appendix")
+	pdf.Write(6, "Appendix")
+	fileStr := example.Filename("Fpdf_SetAnchor")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	pdf = gofpdf.New("P", "mm", "A4", "")
+	pdf.AliasCrossRefs(0)
+	pdf.SetFont("Times", "", 12)
+	pdf.AddPage()
+	pdf.Write(6, "See page {ref:missing}.")
+	fmt.Println(pdf.Output(ioutil.Discard))
+	// Output:
+	// Successfully generated pdf/Fpdf_SetAnchor.pdf
+	// cross-reference to undefined anchor "missing"
+}
+
+// This example demonstrates the stamping of every page of a document after
+// its content has been generated. Each page receives a watermark and a Bates
+// number that includes the total number of pages.
+func ExampleFpdf_SetPageStampFunc() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetPageStampFunc(func(pageNum, pageCount int, size gofpdf.SizeType) {
+		pdf.SetFont("Helvetica", "B", 80)
+		pdf.SetTextColor(220, 50, 50)
+		pdf.SetAlpha(0.25, "Normal")
+		wd := pdf.GetStringWidth("DRAFT")
+		pdf.TransformBegin()
+		pdf.TransformRotate(45, size.Wd/2, size.Ht/2)
+		pdf.Text((size.Wd-wd)/2, size.Ht/2+10, "DRAFT")
+		pdf.TransformEnd()
+		pdf.SetAlpha(1, "Normal")
+		pdf.SetFont("Courier", "", 9)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetXY(size.Wd-70, size.Ht-12)
+		pdf.CellFormat(60, 6, fmt.Sprintf("ACME-%06d (%d of %d)", 1000+pageNum, pageNum, pageCount),
+			"", 0, "R", false, 0, "")
+	})
+	pdf.SetFont("Times", "", 12)
+	for j := 0; j < 3; j++ {
+		pdf.AddPage()
+		pdf.MultiCell(0, 5, lorem(), "", "", false)
+	}
+	pdf.AddPageFormat("L", gofpdf.SizeType{Wd: 148, Ht: 210})
+	pdf.MultiCell(0, 5, lorem(), "", "", false)
+	fileStr := example.Filename("Fpdf_SetPageStampFunc")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_SetPageStampFunc.pdf
+}
+
+// This example demonstrates how content is added to earlier pages. A summary
+// box on the first page is filled in after the totals it reports have been
+// computed, and each page is marked with a note that refers to the page that
+// follows it.
+func ExampleFpdf_SetPage() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Quarterly figures", "", 1, "C", false, 0, "")
+	summaryY := pdf.GetY() + 5
+	pdf.SetY(summaryY + 30)
+	pdf.SetFont("Helvetica", "", 11)
+	var total, count int
+	for j := 1; j <= 120; j++ {
+		value := (j * 7919) % 1000
+		total += value
+		count++
+		if pdf.GetY()+6 > 270 {
+			pdf.AddPage()
+		}
+		pdf.CellFormat(60, 6, fmt.Sprintf("Item %d", j), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 6, fmt.Sprintf("%d", value), "B", 1, "R", false, 0, "")
+	}
+	pageCount := pdf.PageCount()
+	for n := 1; n < pageCount; n++ {
+		pdf.SetPage(n)
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.SetXY(110, 272)
+		pdf.CellFormat(90, 6, fmt.Sprintf("Continued on page %d", n+1), "", 0, "R", false, 0, "")
+	}
+	pdf.SetPage(1)
+	pdf.SetFillColor(230, 240, 255)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetXY(10, summaryY)
+	pdf.CellFormat(0, 8, "Summary", "1", 2, "C", true, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 8, fmt.Sprintf("%d items on %d pages, total %d, average %.1f",
+		count, pageCount, total, float64(total)/float64(count)), "1", 1, "C", false, 0, "")
+	pdf.SetPage(pageCount)
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.Ln(6)
+	pdf.Write(6, fmt.Sprintf("End of report, page %d of %d.", pdf.PageNo(), pdf.PageCount()))
+	fileStr := example.Filename("Fpdf_SetPage")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_SetPage.pdf
+}
+
+// This example demonstrates the rearrangement of pages. The chapters of a
+// document are generated first. The cover page, which reports the number of
+// chapters, is generated last and moved to the front. A table of contents is
+// inserted after it, and a page that is not needed is deleted. Bookmarks and
+// internal links follow the pages they refer to.
+func ExampleFpdf_MovePage() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	type chapterType struct {
+		title string
+		link  int
+	}
+	var chapters []chapterType
+	for j := 1; j <= 4; j++ {
+		pdf.AddPage()
+		title := fmt.Sprintf("Chapter %d", j)
+		pdf.Bookmark(title, 0, 0)
+		link := pdf.AddLink()
+		pdf.SetLink(link, 0, -1)
+		pdf.SetFont("Helvetica", "B", 18)
+		pdf.CellFormat(0, 12, title, "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 12)
+		pdf.MultiCell(0, 6, lorem(), "", "", false)
+		chapters = append(chapters, chapterType{title, link})
+	}
+	pdf.AddPage()
+	pdf.Write(6, "This page is discarded.")
+	discard := pdf.PageNo()
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 28)
+	pdf.SetY(100)
+	pdf.CellFormat(0, 20, "Annual Report", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 14)
+	pdf.CellFormat(0, 10, fmt.Sprintf("%d chapters", len(chapters)), "", 1, "C", false, 0, "")
+	pdf.MovePage(pdf.PageNo(), 1)
+	pdf.DeletePage(discard + 1)
+	pdf.InsertPageBefore(2)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 12, "Contents", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 12)
+	for _, ch := range chapters {
+		pdf.CellFormat(0, 8, ch.title, "", 1, "L", false, ch.link, "")
+	}
+	fmt.Printf("%d pages, contents on page %d\n", pdf.PageCount(), pdf.PageNo())
+	fileStr := example.Filename("Fpdf_MovePage")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// 6 pages, contents on page 2
+	// Successfully generated pdf/Fpdf_MovePage.pdf
+}
+
+// This example demonstrates the imposition of a finished document. An A5
+// document of seven pages is arranged as a saddle-stitched booklet on A4
+// sheets with crop marks, and another copy of it is arranged four pages to a
+// sheet.
+func ExampleFpdf_Impose() {
+	generate := func() *gofpdf.Fpdf {
+		pdf := gofpdf.New("P", "mm", "A5", "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.SetFooterFunc(func() {
+			pdf.SetY(-15)
+			pdf.CellFormat(0, 10, fmt.Sprintf("%d", pdf.PageNo()), "T", 0, "C", false, 0, "")
+		})
+		for j := 1; j <= 7; j++ {
+			pdf.AddPage()
+			pdf.Bookmark(fmt.Sprintf("Page %d", j), 0, 0)
+			pdf.SetFont("Helvetica", "B", 48)
+			pdf.CellFormat(0, 30, fmt.Sprintf("%d", j), "", 1, "C", false, 0, "")
+			pdf.SetFont("Helvetica", "", 11)
+			pdf.MultiCell(0, 5, lorem(), "", "", false)
+		}
+		return pdf
+	}
+	pdf := generate()
+	pdf.Impose(gofpdf.ImposeOptions{
+		Sheet:     gofpdf.SizeType{Wd: 297, Ht: 210},
+		Booklet:   true,
+		Creep:     0.2,
+		CropMarks: true,
+		Margin:    0,
+	})
+	fmt.Printf("Booklet: %d sheet sides\n", pdf.PageCount())
+	fileStr := example.Filename("Fpdf_Impose_booklet")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	pdf = generate()
+	pdf.Impose(gofpdf.ImposeOptions{
+		Sheet:  gofpdf.SizeType{Wd: 297, Ht: 420},
+		Cols:   2,
+		Rows:   2,
+		Margin: 10,
+		Gap:    5,
+	})
+	fmt.Printf("4-up: %d sheets\n", pdf.PageCount())
+	fileStr = example.Filename("Fpdf_Impose_4up")
+	err = pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Booklet: 4 sheet sides
+	// Successfully generated pdf/Fpdf_Impose_booklet.pdf
+	// 4-up: 2 sheets
+	// Successfully generated pdf/Fpdf_Impose_4up.pdf
+}
+
+// This example demonstrates printing address labels on an Avery L7163 sheet
+// and business cards on a custom sheet. The outline of each label is drawn
+// to show the layout; on preprinted sheets it would normally be omitted.
+func ExampleFpdf_Labels() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 11)
+	sheet, ok := gofpdf.LabelSheetPreset("Avery L7163")
+	if !ok {
+		fmt.Println("unknown label sheet")
+		return
+	}
+	pdf.SetDrawColor(160, 160, 160)
+	pdf.Labels(sheet, 3, 20, func(index int, size gofpdf.SizeType) {
+		pdf.Rect(0, 0, size.Wd, size.Ht, "D")
+		pdf.SetXY(6, 6)
+		pdf.SetLeftMargin(6)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.Cell(0, 5, fmt.Sprintf("Customer %03d", index+1))
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(0, 5, "1234 Industrial Parkway\nSpringfield, ST 99999", "", "", false)
+		// Text that does not fit is clipped at the edge of the label
+		pdf.Text(size.Wd-20, size.Ht-4, fmt.Sprintf("Route %d overflow", index%4+1))
+	})
+	fmt.Printf("Address labels: %d pages\n", pdf.PageCount())
+	pdf.Labels(gofpdf.LabelSheetType{
+		UnitStr: "in",
+		Page:    gofpdf.SizeType{Wd: 8.5, Ht: 11},
+		Cols:    2,
+		Rows:    5,
+		Label:   gofpdf.SizeType{Wd: 3.5, Ht: 2},
+		Left:    0.75,
+		Top:     0.5,
+	}, 0, 10, func(index int, size gofpdf.SizeType) {
+		pdf.SetFillColor(230, 240, 255)
+		pdf.Rect(0, 0, size.Wd, size.Ht, "DF")
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(size.Wd, size.Ht/2, "Warehouse Staff", "", 1, "CB", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(size.Wd, 6, fmt.Sprintf("Badge %d", index+1), "", 1, "C", false, 0, "")
+	})
+	fmt.Printf("With cards: %d pages\n", pdf.PageCount())
+	fileStr := example.Filename("Fpdf_Labels")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Address labels: 2 pages
+	// With cards: 3 pages
+	// Successfully generated pdf/Fpdf_Labels.pdf
+}
+
+// This example demonstrates the rendering of HTML styled with CSS, including
+// headings, nested lists, a table whose header row is repeated on each page,
+// an image and a decorated block that spans a page break.
+func ExampleFpdf_HTMLNew() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Times", "", 12)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	html := pdf.HTMLNew()
+	html.StyleSheet(`
+		h1 { font-family: Helvetica; color: #203060; border-bottom: 2px solid #203060 }
+		h2 { font-family: Helvetica; color: #203060 }
+		.note { background-color: #fff4d0; border: 1px solid #e0b040;
+			padding: 4pt 8pt; margin: 12pt 0 }
+		table.stock { width: 100%; font-family: Helvetica; font-size: 10pt }
+		table.stock th { background-color: #203060; color: white; padding: 3pt }
+		table.stock td { border-bottom: 0.5pt solid silver; padding: 2pt 3pt }
+		td.qty { text-align: right }
+	`)
+	var rows string
+	for j := 1; j <= 45; j++ {
+		rows += fmt.Sprintf(`<tr><td>W-%04d</td><td>Widget, type %d</td>`+
+			`<td class="qty">%d</td><td>Aisle %d</td></tr>`, j*7, j, j*37%500, j%12+1)
+	}
+	html.Write(`<h1>Warehouse report</h1>
+		<p style="text-align: justify">This report was produced from
+		<b>HTML</b> content with <i>inline styles</i>, <u>underlining</u>,
+		<span style="background-color: #d0ffd0">highlighted text</span>,
+		<span style="color: red; font-size: 14pt">large red text</span>,
+		H<sub>2</sub>O and E = mc<sup>2</sup>. Lines are justified and wrap at
+		the right margin. See the <a href="#stock">stock table</a> or visit
+		<a href="https://github.com/jung-kurt/gofpdf">the project page</a>.</p>
+		<img src="` + example.ImageFile("logo.png") + `" width="120" style="margin: 4px">
+		<h2>Checklist</h2>
+		<ol>
+			<li>Receive goods
+				<ul><li>Check the delivery note</li><li>Inspect packaging</li></ul>
+			<li>Store goods
+			<li style="list-style-type: lower-roman">Update inventory
+		</ol>
+		<blockquote>Quoted text is indented from both margins.</blockquote>
+		<pre>
+func main() {
+	fmt.Println("preformatted")
+}</pre>
+		<hr>
+		<h2 id="stock">Stock</h2>
+		<table class="stock">
+			<thead><tr><th>Item</th><th>Description</th><th>Quantity</th><th>Location</th></tr></thead>
+			<tbody>` + rows + `</tbody>
+		</table>
+		<div class="note"><b>Note:</b> ` + strings.Repeat(lorem()+" ", 6) + `</div>
+		<table border="1" cellpadding="4" align="center">
+			<tr><td colspan="2" style="text-align: center"><b>Totals</b></td></tr>
+			<tr><td>Items</td><td>45</td></tr>
+		</table>`)
+	fmt.Printf("%d pages\n", pdf.PageCount())
+	fileStr := example.Filename("Fpdf_HTMLNew")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// 3 pages
+	// Successfully generated pdf/Fpdf_HTMLNew.pdf
+}
+
+// This example demonstrates the HTML tokenizer. Attribute values may contain
+// spaces, tags may be self-closing, comments are skipped and character
+// references are converted to the encoding of the current font.
+func ExampleFpdf_HTMLTokenizerNew() {
+	pdf := gofpdf.New("P", "mm", "A4", example.FontDir())
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 14)
+	_, lineHt := pdf.GetFontSize()
+	htmlStr := `<!-- Menu --><b title="Caf&eacute; menu">Caf&eacute; &amp; ` +
+		`cr&egrave;me</b><br/>Espresso &#8211; 2,50&nbsp;&euro; ` +
+		`<a href="http://www.fpdf.org/?q=1&amp;r=2">order</a>`
+	tk := pdf.HTMLTokenizerNew(htmlStr)
+	for seg, ok := tk.Next(); ok; seg, ok = tk.Next() {
+		switch seg.Cat {
+		case 'O':
+			fmt.Printf("open %s %q\n", seg.Str, seg.Attr)
+		case 'C':
+			fmt.Printf("close %s\n", seg.Str)
+		case 'T':
+			fmt.Printf("text %q\n", seg.Str)
+		}
+	}
+	html := pdf.HTMLBasicNew()
+	html.Write(lineHt, htmlStr)
+	pdf.Ln(2 * lineHt)
+	// The Cyrillic font uses the cp1251 encoding
+	pdf.AddFont("Helvetica-1251", "", "helvetica_1251.json")
+	pdf.SetFont("Helvetica-1251", "", 14)
+	html.Write(lineHt, "&#1055;&#1088;&#1080;&#1074;&#1077;&#1090; &mdash; &laquo;Hello&raquo;")
+	fileStr := example.Filename("Fpdf_HTMLTokenizerNew")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// open b map["title":"Caf\xe9 menu"]
+	// text "Caf\xe9 & cr\xe8me"
+	// close b
+	// open br map[]
+	// text "Espresso \x96 2,50\xa0\x80 "
+	// open a map["href":"http://www.fpdf.org/?q=1&r=2"]
+	// text "order"
+	// close a
+	// Successfully generated pdf/Fpdf_HTMLTokenizerNew.pdf
+}
+
+// This example demonstrates the rendering of Markdown, as used for release
+// notes. Headings are bookmarked and can be the targets of internal links.
+func ExampleFpdf_MarkdownNew() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 11)
+	md := pdf.MarkdownNew()
+	md.Headings[0].FontFamily = "Times"
+	mdStr := "# Release notes\n\n" +
+		"Version **2.1** brings *faster* output, a [new tutorial][tut] and " +
+		"the `Markdown` renderer. See [known issues](#known-issues) below.\n\n" +
+		"[tut]: http://www.fpdf.org/en/tutorial/\n\n" +
+		"## New features\n\n" +
+		"1. Markdown with *emphasis*, **strong emphasis** and ***both***\n" +
+		"2. Lists that nest:\n" +
+		"   - bullets\n" +
+		"   - and numbers\n" +
+		"3. Caf&eacute; &amp; cr&egrave;me &#8211; character references\n\n" +
+		"> Upgrading is recommended for all users. This quote is long enough to " +
+		"wrap onto a second line so that the bar at its left spans both.\n\n" +
+		"```go\n" +
+		"pdf := gofpdf.New(\"P\", \"mm\", \"A4\", \"\")\n" +
+		"md := pdf.MarkdownNew()\n" +
+		"md.Write(5, mdStr)\n" +
+		"```\n\n" +
+		"| Method | Purpose | Since |\n" +
+		"|:-------|:-------:|------:|\n" +
+		"| `MarkdownNew` | Renderer for Markdown | 2.1 |\n" +
+		"| `HTMLNew` | Renderer for HTML with style sheets | 2.0 |\n\n" +
+		"![Logo](" + example.ImageFile("logo.png") + ")\n\n" +
+		"---\n\n" +
+		"## Known issues\n\n" +
+		"Report problems at <https://github.com/jung-kurt/gofpdf/issues>.\\\n" +
+		"Thank you!\n"
+	md.Write(5, mdStr)
+	fileStr := example.Filename("Fpdf_MarkdownNew")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_MarkdownNew.pdf
+}
+
+// This example demonstrates SVG images that are rendered once and drawn many
+// times. The star is registered from a string and the badge from a file. The
+// view box of the star is square, so it is centered in areas of other
+// proportions, unless its preserveAspectRatio attribute specifies otherwise.
+func ExampleFpdf_RegisterSVG() {
+	starStr := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="-50 -50 100 100"
+	  width="24" height="24" %s>
+	<defs>
+	  <radialGradient id="glow">
+	    <stop offset="0" stop-color="#ff0"/>
+	    <stop offset="1" stop-color="#f80"/>
+	  </radialGradient>
+	</defs>
+	<rect x="-50" y="-50" width="100" height="100" fill="#036"/>
+	<polygon fill="url(#glow)" stroke="#820" stroke-width="3"
+	  points="0,-45 11,-15 43,-14 17,6 26,36 0,18 -26,36 -17,6 -43,-14 -11,-15"/>
+	</svg>`
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.RegisterSVG("star", strings.NewReader(fmt.Sprintf(starStr, "")))
+	pdf.RegisterSVG("star-slice", strings.NewReader(fmt.Sprintf(starStr,
+		`preserveAspectRatio="xMidYMid slice"`)))
+	pdf.RegisterSVG("star-none", strings.NewReader(fmt.Sprintf(starStr,
+		`preserveAspectRatio="none"`)))
+	fl, err := os.Open(example.ImageFile("mit.svg"))
+	if err == nil {
+		pdf.RegisterSVG("badge", fl)
+		fl.Close()
+	} else {
+		pdf.SetError(err)
+	}
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.Write(6, "A grid of 300 stars that share a single form:")
+	for row := 0; row < 15; row++ {
+		for col := 0; col < 20; col++ {
+			pdf.SVG("star", 10+float64(col)*9.5, 20+float64(row)*9.5, 8, 0)
+		}
+	}
+	y := 170.0
+	pdf.SetDrawColor(160, 160, 160)
+	for j, name := range []string{"star", "star-slice", "star-none"} {
+		x := 10 + float64(j)*65
+		pdf.Rect(x, y, 50, 25, "D")
+		pdf.SVG(name, x, y, 50, 25)
+		pdf.Text(x, y+31, name)
+	}
+	// Intrinsic size and a scaled copy of the badge
+	pdf.SVG("badge", 10, 215, 0, 0)
+	pdf.SVG("badge", 10, 230, 0, 20)
+	fileStr := example.Filename("Fpdf_RegisterSVG")
+	err = pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_RegisterSVG.pdf
+}
+
+// This example demonstrates colors in the CMYK color model, which print shops
+// commonly require. The process inks are shown as swatches, each with its
+// components as reported by GetFillColorCMYK().
+func ExampleFpdf_SetDrawColorCMYK() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.SetTextColorCMYK(100, 60, 0, 20)
+	pdf.Cell(0, 12, "CMYK colors")
+	pdf.Ln(16)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColorCMYK(0, 0, 0, 100)
+	pdf.SetDrawColorCMYK(0, 0, 0, 60)
+	pdf.SetLineWidth(0.3)
+	inks := [][4]int{{100, 0, 0, 0}, {0, 100, 0, 0}, {0, 0, 100, 0}, {0, 0, 0, 100},
+		{100, 100, 0, 0}, {0, 100, 100, 0}, {100, 0, 100, 0}, {0, 0, 0, 30}}
+	for j, ink := range inks {
+		x := 10 + float64(j%4)*48
+		y := 30 + float64(j/4)*40
+		pdf.SetFillColorCMYK(ink[0], ink[1], ink[2], ink[3])
+		pdf.Rect(x, y, 40, 25, "FD")
+		c, m, yl, k := pdf.GetFillColorCMYK()
+		pdf.SetXY(x, y+26)
+		pdf.Cell(40, 5, fmt.Sprintf("C %d  M %d  Y %d  K %d", c, m, yl, k))
+	}
+	pdf.LinearGradientCMYK(10, 115, 184, 40, 100, 0, 0, 0, 0, 100, 0, 0, 0, 0, 1, 0)
+	pdf.Rect(10, 115, 184, 40, "D")
+	pdf.RadialGradientCMYK(10, 165, 88, 88, 0, 0, 100, 0, 0, 80, 100, 10,
+		0.5, 0.5, 0.5, 0.5, 0.5)
+	pdf.Rect(10, 165, 88, 88, "D")
+	pdf.SetFillColorCMYK(0, 0, 0, 10)
+	pdf.SetTextColorCMYK(0, 100, 100, 0)
+	pdf.SetXY(106, 165)
+	pdf.MultiCell(88, 6, "Text, lines and fills that are set with the CMYK "+
+		"methods are written with the k and K operators, so the separations "+
+		"contain no converted RGB values.", "1", "L", true)
+	fileStr := example.Filename("Fpdf_SetDrawColorCMYK")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_SetDrawColorCMYK.pdf
+}
+
+// This example demonstrates spot colors. Each ink is registered once with its
+// CMYK alternate and is then used at various tints for lines, fills and text.
+func ExampleFpdf_AddSpotColor() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddSpotColor("PANTONE 145 CVC", 0, 42, 100, 25)
+	pdf.AddSpotColor("PANTONE 2945 C", 100, 45, 0, 14)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.SetTextSpotColor("PANTONE 2945 C", 100)
+	pdf.Cell(0, 12, "Spot colors")
+	pdf.Ln(16)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColorCMYK(0, 0, 0, 100)
+	pdf.SetDrawSpotColor("PANTONE 145 CVC", 100)
+	pdf.SetLineWidth(0.5)
+	for j, nameStr := range []string{"PANTONE 145 CVC", "PANTONE 2945 C"} {
+		y := 30 + float64(j)*40
+		for k := 0; k < 5; k++ {
+			tint := 100 - 20*k
+			x := 10 + float64(k)*38
+			pdf.SetFillSpotColor(nameStr, tint)
+			pdf.Rect(x, y, 34, 25, "FD")
+			pdf.SetXY(x, y+26)
+			pdf.Cell(34, 5, fmt.Sprintf("%s %d%%", nameStr, tint))
+		}
+	}
+	pdf.SetXY(10, 110)
+	pdf.SetTextSpotColor("PANTONE 145 CVC", 100)
+	pdf.SetFillSpotColor("PANTONE 2945 C", 20)
+	pdf.MultiCell(184, 6, "Each spot color is printed on a separate plate with "+
+		"its own ink. Devices that do not have the ink, such as displays and "+
+		"office printers, use the CMYK alternate instead.", "", "L", true)
+	fileStr := example.Filename("Fpdf_AddSpotColor")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_AddSpotColor.pdf
+}
+
+// This example demonstrates color management. The output intent identifies
+// sRGB as the color space of the intended output device, and the image of the
+// gopher is written with the ICC profile that is embedded in its PNG file.
+func ExampleFpdf_SetOutputIntent() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetOutputIntent("GTS_PDFA1", "sRGB IEC61966-2.1", "sRGB IEC61966-2.1",
+		bytes.NewReader(srgbProfile()))
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.MultiCell(0, 6, "Colors in this document are intended for an sRGB "+
+		"display. The image below carries its own ICC profile.", "", "L", false)
+	pdf.ImageOptions(example.ImageFile("golang-gopher.png"), 10, 30, 80, 0,
+		false, gofpdf.ImageOptions{}, 0, "")
+	fileStr := example.Filename("Fpdf_SetOutputIntent")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_SetOutputIntent.pdf
+}
+
+// This example demonstrates tiling patterns. Each pattern cell is drawn once
+// and repeated to fill shapes, cell backgrounds, text and thick lines.
+func ExampleFpdf_AddPattern() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetLineWidth(0.3)
+	hatch := pdf.AddPattern(3, 3, func() {
+		pdf.SetDrawColor(40, 40, 120)
+		pdf.SetLineWidth(0.3)
+		pdf.Line(-1, 4, 4, -1)
+		pdf.Line(-1, 1, 1, -1)
+		pdf.Line(2, 4, 4, 2)
+	})
+	cross := pdf.AddPattern(4, 4, func() {
+		pdf.SetDrawColor(160, 40, 40)
+		pdf.SetLineWidth(0.2)
+		pdf.Line(0, 2, 4, 2)
+		pdf.Line(2, 0, 2, 4)
+	})
+	dots := pdf.AddPattern(2.5, 2.5, func() {
+		pdf.SetFillColor(40, 120, 40)
+		pdf.Circle(1.25, 1.25, 0.5, "F")
+	})
+	checker := pdf.AddPattern(6, 6, func() {
+		pdf.SetFillColor(230, 230, 230)
+		pdf.Rect(0, 0, 6, 6, "F")
+		pdf.SetFillColor(80, 80, 80)
+		pdf.Rect(0, 0, 3, 3, "F")
+		pdf.Rect(3, 3, 3, 3, "F")
+	})
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.Cell(0, 12, "Tiling patterns")
+	pdf.SetFillPattern(hatch)
+	pdf.Rect(10, 30, 55, 40, "FD")
+	pdf.SetFillPattern(cross)
+	pdf.Polygon([]gofpdf.PointType{{X: 100, Y: 30}, {X: 130, Y: 70}, {X: 70, Y: 70}}, "FD")
+	pdf.SetFillPattern(dots)
+	pdf.Ellipse(165, 50, 28, 20, 0, "FD")
+	pdf.SetFillPattern(checker)
+	pdf.Beziergon([]gofpdf.PointType{{X: 10, Y: 110}, {X: 40, Y: 70}, {X: 70, Y: 150},
+		{X: 100, Y: 110}, {X: 70, Y: 90}, {X: 40, Y: 140}, {X: 10, Y: 110}}, "FD")
+	pdf.SetDrawPattern(hatch)
+	pdf.SetLineWidth(6)
+	pdf.Line(115, 95, 195, 135)
+	pdf.SetLineWidth(0.3)
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 60)
+	pdf.SetTextPattern(checker)
+	pdf.SetXY(10, 160)
+	pdf.Cell(0, 30, "PATTERN")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFillPattern(dots)
+	pdf.SetXY(10, 200)
+	pdf.CellFormat(90, 12, "Cell with a dotted background", "1", 0, "C", true, 0, "")
+	fileStr := example.Filename("Fpdf_AddPattern")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_AddPattern.pdf
+}
+
+// This example demonstrates gradients with several colors and mesh shadings,
+// which are used like patterns to fill arbitrary shapes and text.
+func ExampleFpdf_AddLinearGradient() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	rainbow := []gofpdf.ColorStopType{
+		{Pos: 0, Clr: gofpdf.RGBType{R: 220, G: 40, B: 40}},
+		{Pos: 0.25, Clr: gofpdf.RGBType{R: 240, G: 200, B: 40}},
+		{Pos: 0.5, Clr: gofpdf.RGBType{R: 40, G: 180, B: 80}},
+		{Pos: 0.75, Clr: gofpdf.RGBType{R: 40, G: 100, B: 220}},
+		{Pos: 1, Clr: gofpdf.RGBType{R: 140, G: 40, B: 180}},
+	}
+	// Text filled with a multi-stop gradient
+	pdf.SetFont("Helvetica", "B", 48)
+	pdf.SetTextPattern(pdf.AddLinearGradient(10, 0, 200, 0, rainbow, true, true))
+	pdf.SetXY(10, 10)
+	pdf.Cell(0, 20, "Gradient text")
+	// A star filled with a radial gradient that is not extended
+	pdf.SetFillPattern(pdf.AddRadialGradient(55, 80, 0, 55, 80, 40, rainbow, false, false))
+	var star []gofpdf.PointType
+	for j := 0; j < 10; j++ {
+		r := 40.0
+		if j%2 == 1 {
+			r = 16
+		}
+		a := float64(j) * math.Pi / 5
+		star = append(star, gofpdf.PointType{X: 55 + r*math.Sin(a), Y: 80 - r*math.Cos(a)})
+	}
+	pdf.SetDrawColor(60, 60, 60)
+	pdf.Polygon(star, "FD")
+	// Stripes with abrupt changes of color at repeated positions
+	stripes := []gofpdf.ColorStopType{
+		{Pos: 0, Clr: gofpdf.RGBType{R: 40, G: 60, B: 120}},
+		{Pos: 0.5, Clr: gofpdf.RGBType{R: 40, G: 60, B: 120}},
+		{Pos: 0.5, Clr: gofpdf.RGBType{R: 230, G: 230, B: 240}},
+	}
+	pdf.SetFillPattern(pdf.AddLinearGradient(110, 40, 150, 80, stripes, true, true))
+	pdf.Circle(150, 80, 40, "FD")
+	// Chart background shaded by a lattice mesh
+	var lattice []gofpdf.MeshVertexType
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			v := 255 - 60*((row+col)%3)
+			lattice = append(lattice, gofpdf.MeshVertexType{
+				Pt:  gofpdf.PointType{X: 10 + float64(col)*45, Y: 130 + float64(row)*30},
+				Clr: gofpdf.RGBType{R: v, G: 230, B: 255 - v/2}})
+		}
+	}
+	pdf.SetFillPattern(pdf.AddLatticeMesh(lattice, 3))
+	pdf.Rect(10, 130, 90, 60, "F")
+	pdf.SetLineWidth(0.8)
+	pdf.SetDrawColor(40, 40, 120)
+	pdf.Polygon([]gofpdf.PointType{{X: 10, Y: 180}, {X: 30, Y: 160}, {X: 50, Y: 170},
+		{X: 75, Y: 140}, {X: 100, Y: 150}}, "D")
+	pdf.SetLineWidth(0.2)
+	// Free-form triangles
+	pdf.SetFillPattern(pdf.AddTriangleMesh([][3]gofpdf.MeshVertexType{
+		{{Pt: gofpdf.PointType{X: 110, Y: 190}, Clr: gofpdf.RGBType{R: 255}},
+			{Pt: gofpdf.PointType{X: 150, Y: 130}, Clr: gofpdf.RGBType{G: 255}},
+			{Pt: gofpdf.PointType{X: 190, Y: 190}, Clr: gofpdf.RGBType{B: 255}}},
+	}))
+	pdf.Rect(110, 130, 80, 60, "F")
+	// A Coons patch and a tensor-product patch with curved edges
+	patch := gofpdf.MeshPatchType{
+		Points: [12]gofpdf.PointType{{X: 10, Y: 270}, {X: 30, Y: 250}, {X: 50, Y: 280},
+			{X: 90, Y: 270}, {X: 80, Y: 250}, {X: 100, Y: 230}, {X: 90, Y: 210},
+			{X: 60, Y: 220}, {X: 40, Y: 200}, {X: 10, Y: 210}, {X: 20, Y: 230}, {X: 0, Y: 250}},
+		Clrs: [4]gofpdf.RGBType{{R: 250, G: 80, B: 60}, {R: 250, G: 220, B: 60},
+			{R: 60, G: 160, B: 250}, {R: 120, G: 60, B: 200}},
+	}
+	pdf.SetFillPattern(pdf.AddCoonsPatchMesh([]gofpdf.MeshPatchType{patch}))
+	pdf.Rect(0, 195, 105, 95, "F")
+	for j := range patch.Points {
+		patch.Points[j].X += 100
+	}
+	// Interior control points opposite to their corners swirl the colors
+	patch.Inner = [4]gofpdf.PointType{{X: 170, Y: 220}, {X: 130, Y: 220}, {X: 130, Y: 260},
+		{X: 170, Y: 260}}
+	pdf.SetFillPattern(pdf.AddTensorPatchMesh([]gofpdf.MeshPatchType{patch}))
+	pdf.Rect(100, 195, 105, 95, "F")
+	fileStr := example.Filename("Fpdf_AddLinearGradient")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_AddLinearGradient.pdf
+}
+
+// This example demonstrates soft masks. A luminosity mask drawn with a radial
+// gradient fades the edges of an image, and an alpha mask lets a band of
+// stripes show through text only.
+func ExampleFpdf_AddSoftMask() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.Cell(0, 12, "Soft masks")
+	vignette := pdf.AddSoftMask("Luminosity", func() {
+		pdf.RadialGradient(20, 30, 170, 120, 255, 255, 255, 0, 0, 0,
+			0.5, 0.5, 0.5, 0.5, 0.5)
+	})
+	lettering := pdf.AddSoftMask("Alpha", func() {
+		pdf.SetFont("Helvetica", "B", 64)
+		pdf.SetXY(20, 170)
+		pdf.Cell(170, 40, "MASKED")
+	})
+	pdf.ClipSoftMask(vignette)
+	pdf.ImageOptions(example.ImageFile("logo.jpg"), 20, 30, 170, 120, false,
+		gofpdf.ImageOptions{}, 0, "")
+	pdf.ClipEnd()
+	pdf.ClipSoftMask(lettering)
+	for j := 0; j < 17; j++ {
+		pdf.SetFillColor(40+12*j, 80, 220-10*j)
+		pdf.Rect(20+float64(j)*10, 170, 10, 40, "F")
+	}
+	pdf.ClipEnd()
+	fileStr := example.Filename("Fpdf_AddSoftMask")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_AddSoftMask.pdf
+}
+
+// This example demonstrates clipping with arbitrary paths and the even-odd
+// rule, which treats subpaths within other subpaths as holes.
+func ExampleFpdf_ClipPath() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.Cell(0, 12, "Paths with holes")
+	ring := func(x, y, r1, r2 float64) {
+		pdf.MoveTo(x+r1, y)
+		pdf.ArcTo(x, y, r1, r1, 0, 0, 360)
+		pdf.ClosePath()
+		pdf.MoveTo(x+r2, y)
+		pdf.ArcTo(x, y, r2, r2, 0, 0, 360)
+		pdf.ClosePath()
+	}
+	// A donut chart filled with the even-odd rule
+	pdf.SetLineWidth(0.5)
+	pdf.SetDrawColor(40, 40, 40)
+	pdf.SetFillColor(100, 160, 220)
+	ring(55, 70, 40, 20)
+	pdf.DrawPath("FD*")
+	// A gradient clipped by a ring
+	pdf.ClipPath(func() { ring(150, 70, 40, 20) }, true, true)
+	pdf.LinearGradient(110, 30, 80, 80, 250, 200, 40, 200, 40, 80, 0, 0, 1, 1)
+	pdf.ClipEnd()
+	// A lake in a map region: the same outline with the nonzero rule and with
+	// the even-odd rule
+	region := func(x float64) {
+		pdf.MoveTo(x, 130)
+		pdf.CurveBezierCubicTo(x+30, 115, x+70, 140, x+80, 130)
+		pdf.LineTo(x+75, 200)
+		pdf.CurveTo(x+40, 185, x+5, 200)
+		pdf.ClosePath()
+		pdf.MoveTo(x+25, 155)
+		pdf.CurveTo(x+45, 140, x+55, 160)
+		pdf.CurveTo(x+40, 180, x+25, 155)
+		pdf.ClosePath()
+	}
+	for j, evenOdd := range []bool{false, true} {
+		x := 15 + float64(j)*95
+		pdf.ClipPath(func() { region(x) }, evenOdd, false)
+		for k := 0; k < 14; k++ {
+			pdf.SetFillColor(60+10*k, 160, 60)
+			pdf.Rect(x, 115+float64(k)*6, 80, 6, "F")
+		}
+		pdf.ClipEnd()
+		region(x)
+		pdf.DrawPath("D")
+	}
+	fileStr := example.Filename("Fpdf_ClipPath")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_ClipPath.pdf
+}
+
+// This example demonstrates a path that is built once, examined and then
+// drawn several times with different transformations.
+func ExampleFpdf_Path() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	// A leaf shaped outline centered at (0, 0)
+	var leaf gofpdf.PathType
+	leaf.MoveTo(0, -20)
+	leaf.CurveBezierCubicTo(15, -10, 15, 10, 0, 20)
+	leaf.CurveBezierCubicTo(-15, 10, -15, -10, 0, -20)
+	leaf.ClosePath()
+	leaf.MoveTo(0, -20)
+	leaf.LineTo(0, 20)
+	pdf.SetLineWidth(0.5)
+	pdf.SetDrawColor(30, 90, 30)
+	pdf.SetFillColor(120, 200, 120)
+	for j := 0; j < 8; j++ {
+		p := leaf.Translate(0, -25).Rotate(float64(j)*45, 0, 0).Translate(60, 80)
+		pdf.Path(p, "FD")
+	}
+	// The bounding box of a scaled and rotated path
+	p := leaf.Scale(2, 1.5, 0, 0).Rotate(30, 0, 0).Translate(150, 80)
+	corner, size := p.Bounds()
+	pdf.SetDrawColor(200, 60, 60)
+	pdf.Rect(corner.X, corner.Y, size.Wd, size.Ht, "D")
+	pdf.SetDrawColor(30, 90, 30)
+	pdf.Path(p, "FD")
+	pdf.SetXY(110, 130)
+	pdf.Cell(80, 6, fmt.Sprintf("Outline length: %.1f mm", p.Length()))
+	// A ring drawn as an outer circle and a reversed inner circle; with the
+	// nonzero winding rule the opposite directions leave a hole
+	var circle gofpdf.PathType
+	circle.ArcTo(0, 0, 1, 1, 0, 0, 360)
+	circle.ClosePath()
+	var ring gofpdf.PathType
+	ring.ArcTo(60, 200, 40, 40, 0, 0, 360)
+	ring.ClosePath()
+	inner := circle.Scale(20, 20, 0, 0).Translate(60, 200).Reverse()
+	pdf.SetFillColor(100, 160, 220)
+	pdf.SetDrawColor(40, 40, 40)
+	pdf.AppendPath(ring)
+	pdf.AppendPath(inner)
+	pdf.DrawPath("FD")
+	// The same ring, moved to the right, used as a clipping path
+	pdf.ClipPath(func() {
+		pdf.AppendPath(ring.Translate(90, 0))
+		pdf.AppendPath(inner.Translate(90, 0))
+	}, false, true)
+	pdf.LinearGradient(110, 160, 80, 80, 250, 200, 40, 200, 40, 80, 0, 0, 1, 1)
+	pdf.ClipEnd()
+	fileStr := example.Filename("Fpdf_Path")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_Path.pdf
+}
+
+// This example demonstrates rounded rectangles with uniform and individual
+// corner radii, and cells with rounded backgrounds and borders.
+func ExampleFpdf_RoundedRect() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetLineWidth(0.4)
+	pdf.SetDrawColor(60, 60, 60)
+	pdf.SetFillColor(220, 230, 245)
+	pdf.RoundedRect(10, 15, 55, 30, 5, "", "D")
+	pdf.RoundedRect(75, 15, 55, 30, 8, "13", "FD")
+	pdf.RoundedRect(140, 15, 55, 30, 10, "12", "F")
+	pdf.RoundedRectExt(10, 55, 55, 30, 0, 5, 10, 15, "FD")
+	// Radii that are too large are reduced to fit
+	pdf.RoundedRectExt(75, 55, 55, 30, 40, 40, 40, 40, "FD")
+	pdf.RoundedRectExt(140, 55, 55, 30, 15, 0, 15, 0, "D")
+	// An invoice style table with a rounded header and total
+	pdf.SetXY(10, 100)
+	pdf.SetCellRounding(2, "12")
+	pdf.SetFillColor(40, 80, 140)
+	pdf.SetTextColor(255, 255, 255)
+	for _, str := range []string{"Item", "Quantity", "Price"} {
+		pdf.CellFormat(60, 8, str, "", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetCellRounding(0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFillColor(235, 240, 250)
+	for j, row := range [][]string{{"Paper", "4", "12.00"}, {"Ink", "2", "48.50"}, {"Pens", "10", "9.90"}} {
+		for _, str := range row {
+			pdf.CellFormat(60, 7, str, "LR", 0, "C", j%2 == 1, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.SetCellRounding(2, "34")
+	pdf.CellFormat(180, 8, "Total: 70.40", "1", 1, "R", false, 0, "")
+	pdf.SetCellRounding(4, "")
+	pdf.SetY(pdf.GetY() + 10)
+	pdf.SetFillColor(250, 230, 200)
+	pdf.CellFormat(60, 10, "Paid", "1", 1, "C", true, 0, "")
+	fileStr := example.Filename("Fpdf_RoundedRect")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_RoundedRect.pdf
+}
+
+// This example demonstrates that a spot color registered while measuring
+// remains available after the dry run.
+func ExampleFpdf_AddSpotColor_measure() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	box := func() {
+		pdf.AddSpotColor("PANTONE 485 C", 0, 95, 100, 0)
+		pdf.SetFillSpotColor("PANTONE 485 C", 100)
+		pdf.CellFormat(60, 10, "Spot color box", "1", 1, "C", true, 0, "")
+	}
+	m := pdf.Measure(box)
+	fmt.Printf("Measured height %.2f\n", m.Extent().Ht)
+	pdf.SetFillSpotColor("PANTONE 485 C", 50)
+	pdf.CellFormat(60, 10, "Tinted spot color box", "1", 1, "C", true, 0, "")
+	fileStr := example.Filename("Fpdf_AddSpotColor_measure")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Measured height 10.00
+	// Successfully generated pdf/Fpdf_AddSpotColor_measure.pdf
+}
+
+// This example demonstrates that the ID of a pattern defined while measuring
+// remains valid after the dry run and is not reused by the next pattern.
+func ExampleFpdf_AddPattern_measure() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	var dotsID int
+	pdf.Measure(func() {
+		dotsID = pdf.AddPattern(4, 4, func() {
+			pdf.SetFillColor(200, 40, 40)
+			pdf.Circle(2, 2, 1, "F")
+		})
+		pdf.SetFillPattern(dotsID)
+		pdf.Rect(10, 10, 80, 40, "F")
+	})
+	stripesID := pdf.AddPattern(4, 4, func() {
+		pdf.SetFillColor(40, 40, 200)
+		pdf.Rect(0, 0, 2, 4, "F")
+	})
+	fmt.Printf("Distinct pattern IDs: %v\n", dotsID != stripesID)
+	pdf.SetFillPattern(dotsID)
+	pdf.Rect(10, 10, 80, 40, "F")
+	pdf.SetFillPattern(stripesID)
+	pdf.Rect(100, 10, 80, 40, "F")
+	fileStr := example.Filename("Fpdf_AddPattern_measure")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Distinct pattern IDs: true
+	// Successfully generated pdf/Fpdf_AddPattern_measure.pdf
+}
+
+// This example demonstrates that a soft mask defined while measuring keeps
+// its content and ID after the dry run.
+func ExampleFpdf_AddSoftMask_measure() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	var fadeID int
+	m := pdf.Measure(func() {
+		fadeID = pdf.AddSoftMask("Luminosity", func() {
+			pdf.LinearGradient(10, 10, 190, 60, 255, 255, 255, 0, 0, 0, 0, 0, 1, 0)
+		})
+		pdf.ClipSoftMask(fadeID)
+		pdf.Rect(10, 10, 190, 60, "F")
+		pdf.ClipEnd()
+	})
+	sz := m.Extent()
+	fmt.Printf("Measured %.2f by %.2f\n", sz.Wd, sz.Ht)
+	spotID := pdf.AddSoftMask("Alpha", func() {
+		pdf.Circle(105, 115, 30, "F")
+	})
+	fmt.Printf("Distinct mask IDs: %v\n", fadeID != spotID)
+	pdf.SetFillColor(40, 80, 200)
+	pdf.ClipSoftMask(fadeID)
+	pdf.Rect(10, 10, 190, 60, "F")
+	pdf.ClipEnd()
+	pdf.ClipSoftMask(spotID)
+	pdf.Rect(10, 80, 190, 70, "F")
+	pdf.ClipEnd()
+	fileStr := example.Filename("Fpdf_AddSoftMask_measure")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Measured 190.00 by 60.00
+	// Distinct mask IDs: true
+	// Successfully generated pdf/Fpdf_AddSoftMask_measure.pdf
+}
+
+// This example demonstrates that the footer of the page most recently added
+// is output exactly once, on that page, after it has been moved or after an
+// earlier page has been deleted.
+func ExampleFpdf_MovePage_footer() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	var footers []string
+	pdf.SetFooterFunc(func() {
+		footers = append(footers, fmt.Sprintf("%d/%d", pdf.PageNo(), pdf.PageCount()))
+		pdf.SetY(-15)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	for j := 1; j <= 3; j++ {
+		pdf.AddPage()
+		pdf.Cell(0, 10, fmt.Sprintf("Body %d", j))
+	}
+	// Move the open page to the front; its footer is output on page 1 when
+	// the next page is added
+	pdf.MovePage(pdf.PageNo(), 1)
+	pdf.AddPage()
+	pdf.Cell(0, 10, "Body 4")
+	// Delete the open page from an earlier page; no footer is output for it
+	pdf.SetPage(1)
+	pdf.DeletePage(pdf.PageCount())
+	fileStr := example.Filename("Fpdf_MovePage_footer")
+	err := pdf.OutputFileAndClose(fileStr)
+	fmt.Printf("Footers (page/count): %s\n", strings.Join(footers, " "))
+	fmt.Printf("%d pages\n", pdf.PageCount())
+	example.Summary(err, fileStr)
+	// Output:
+	// Footers (page/count): 1/1 2/2 1/3
+	// 3 pages
+	// Successfully generated pdf/Fpdf_MovePage_footer.pdf
+}
+
+// This example demonstrates that the HTML and Markdown renderers leave CMYK
+// colors set by the caller in their color space.
+func ExampleFpdf_SetTextColorCMYK() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.SetTextColorCMYK(20, 30, 40, 10)
+	pdf.SetFillColorCMYK(5, 0, 10, 0)
+	pdf.SetDrawColorCMYK(0, 60, 80, 20)
+	report := func(label string) {
+		c, m, y, k := pdf.GetTextColorCMYK()
+		fmt.Printf("%s: text %d %d %d %d", label, c, m, y, k)
+		c, m, y, k = pdf.GetFillColorCMYK()
+		fmt.Printf(", fill %d %d %d %d", c, m, y, k)
+		c, m, y, k = pdf.GetDrawColorCMYK()
+		fmt.Printf(", draw %d %d %d %d\n", c, m, y, k)
+	}
+	html := pdf.HTMLNew()
+	html.Write(`<p>Body text in <a href="https://github.com">CMYK</a></p>
+		<ul><li>An item</li></ul>`)
+	report("HTML")
+	md := pdf.MarkdownNew()
+	md.Write(6, "Text with a [link](https://github.com)\n\n    code block\n\n> quote\n")
+	report("Markdown")
+	basic := pdf.HTMLBasicNew()
+	basic.Write(6, `A <a href="https://github.com">basic link</a>`)
+	report("HTMLBasic")
+	fileStr := example.Filename("Fpdf_SetTextColorCMYK")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// HTML: text 20 30 40 10, fill 5 0 10 0, draw 0 60 80 20
+	// Markdown: text 20 30 40 10, fill 5 0 10 0, draw 0 60 80 20
+	// HTMLBasic: text 20 30 40 10, fill 5 0 10 0, draw 0 60 80 20
+	// Successfully generated pdf/Fpdf_SetTextColorCMYK.pdf
+}
+
+// This example demonstrates reversing a path in which a segment follows
+// ClosePath() without an intervening MoveTo(). Such a segment begins a new
+// subpath at the start of the closed one.
+func ExamplePathType_Reverse() {
+	var p gofpdf.PathType
+	p.MoveTo(20, 20)
+	p.LineTo(30, 20)
+	p.LineTo(30, 30)
+	p.ClosePath()
+	p.LineTo(20, 40)
+	r := p.Reverse()
+	corner, size := r.Bounds()
+	fmt.Printf("Corner (%.2f, %.2f), size %.2f by %.2f\n", corner.X, corner.Y, size.Wd, size.Ht)
+	fmt.Printf("Length %.2f, reversed %.2f\n", p.Length(), r.Length())
+	// Output:
+	// Corner (20.00, 20.00), size 10.00 by 20.00
+	// Length 54.14, reversed 54.14
+}
+
+// This example demonstrates the bounds of a path in which a curve follows
+// ClosePath(). The curve begins at the start of the closed subpath.
+func ExamplePathType_Bounds() {
+	var p gofpdf.PathType
+	p.MoveTo(10, 10)
+	p.LineTo(40, 10)
+	p.LineTo(40, 20)
+	p.ClosePath()
+	p.CurveBezierCubicTo(10, 40, 40, 40, 40, 10)
+	corner, size := p.Bounds()
+	fmt.Printf("Corner (%.2f, %.2f), size %.2f by %.2f\n", corner.X, corner.Y, size.Wd, size.Ht)
+	// Output:
+	// Corner (10.00, 10.00), size 30.00 by 22.50
+}
+
+// This example demonstrates a table row that is too tall to fit on a page.
+// Its cells are continued on the following pages rather than drawn past the
+// bottom margin.
+func ExampleFpdf_HTMLNew_tallRow() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	var buf bytes.Buffer
+	for j := 1; j <= 150; j++ {
+		fmt.Fprintf(&buf, "Line %d<br>", j)
+	}
+	htmlStr := `<p>A row of a table with a long cell</p>
+		<table border="1" cellpadding="4">
+		<tr><td style="background-color: #f0e0d0">` + buf.String() + `</td>
+		<td>A short cell</td></tr>
+		<tr><td>The next row</td><td>follows the tall one</td></tr></table>`
+	html := pdf.HTMLNew()
+	m := pdf.Measure(func() { html.Write(htmlStr) })
+	html.Write(htmlStr)
+	fmt.Printf("Measured %d pages, rendered %d pages\n", m.Pages, pdf.PageCount())
+	fileStr := example.Filename("Fpdf_HTMLNew_tallRow")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Measured 3 pages, rendered 3 pages
+	// Successfully generated pdf/Fpdf_HTMLNew_tallRow.pdf
+}
+
+// This example demonstrates measuring output that begins with the first page
+// of a document.
+func ExampleFpdf_Measure_firstPage() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	m := pdf.Measure(func() {
+		pdf.AddPage()
+		pdf.Cell(10, 10, "x")
+	})
+	fmt.Printf("Pages %d, document pages %d\n", m.Pages, pdf.PageCount())
+	pdf.AddPage()
+	pdf.Cell(10, 10, "x")
+	fileStr := example.Filename("Fpdf_Measure_firstPage")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Pages 1, document pages 0
+	// Successfully generated pdf/Fpdf_Measure_firstPage.pdf
+}
+
+// This example demonstrates that an SVG image registered during a dry run
+// performed by Measure() remains registered, with its content intact, so that
+// it can be drawn afterward.
+func ExampleFpdf_RegisterSVG_measure() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	m := pdf.Measure(func() {
+		pdf.RegisterSVG("box", strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg"
+		  viewBox="0 0 100 50" width="100" height="50">
+		<rect x="5" y="5" width="90" height="40" fill="#036" stroke="#820"/>
+		</svg>`))
+		pdf.SVG("box", 10, 10, 100, 0)
+	})
+	sz := m.Extent()
+	fmt.Printf("Measured %.2f by %.2f\n", sz.Wd, sz.Ht)
+	pdf.SVG("box", 10, 10, 100, 0)
+	pdf.SVG("box", 10, 70, 50, 0)
+	fileStr := example.Filename("Fpdf_RegisterSVG_measure")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Measured 100.00 by 50.00
+	// Successfully generated pdf/Fpdf_RegisterSVG_measure.pdf
+}
+
+// This example demonstrates a pattern cell that uses transparency and a
+// gradient, which are defined as resources of the document.
+func ExampleFpdf_AddPattern_transparency() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	glow := pdf.AddPattern(10, 10, func() {
+		pdf.LinearGradient(0, 0, 10, 10, 255, 200, 0, 200, 40, 0, 0, 0, 1, 1)
+		pdf.SetAlpha(0.5, "Normal")
+		pdf.SetFillColor(0, 60, 160)
+		pdf.Circle(5, 5, 3, "F")
+		pdf.SetAlpha(1, "Normal")
+	})
+	pdf.AddPage()
+	pdf.SetFillPattern(glow)
+	pdf.Rect(10, 10, 190, 100, "F")
+	fileStr := example.Filename("Fpdf_AddPattern_transparency")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Successfully generated pdf/Fpdf_AddPattern_transparency.pdf
+}
+
+// This example demonstrates an index entry that is taller than a column. It
+// continues at the top of the next column, and then on a new page, instead
+// of running past the bottom margin.
+func ExampleFpdf_Index_tallEntry() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 11)
+	for j := 0; j < 300; j++ {
+		pdf.AddPage()
+		if j%2 == 0 {
+			pdf.IndexEntry("Everywhere")
+		}
+	}
+	pdf.IndexEntry("Last")
+	pdf.SetY(250)
+	pdf.Index(gofpdf.IndexOptions{LineHt: 6, ColCount: 3})
+	_, pageHt := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	fmt.Printf("Index ends on page %d within the margin: %v\n", pdf.PageNo(),
+		pdf.GetY() <= pageHt-bottom)
+	fileStr := example.Filename("Fpdf_Index_tallEntry")
+	err := pdf.OutputFileAndClose(fileStr)
+	example.Summary(err, fileStr)
+	// Output:
+	// Index ends on page 301 within the margin: true
+	// Successfully generated pdf/Fpdf_Index_tallEntry.pdf
+}
+
+// This example demonstrates numbering formats in which the number follows a
+// word. Only a marker that stands apart from other letters and digits is
+// replaced with the item number.
+func ExampleFpdf_ListNew_wordPrefix() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	list := pdf.ListNew()
+	list.LabelWd = 20
+	list.Levels = []gofpdf.ListLevelType{{Numbering: "Item 1."}, {Numbering: "Task a)"}}
+	list.Item(6, 0, "First item")
+	list.Item(6, 1, "First task")
+	list.Item(6, 1, "Second task")
+	list.Item(6, 0, "Second item")
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err == nil {
+		for _, labelStr := range []string{"Item 1.", "Task a\\)", "Task b\\)", "Item 2."} {
+			fmt.Printf("%s: %v\n", labelStr, strings.Contains(buf.String(), "("+labelStr+") Tj"))
+		}
+	} else {
+		fmt.Println(err)
+	}
+	// Output:
+	// Item 1.: true
+	// Task a\): true
+	// Task b\): true
+	// Item 2.: true
+}
+
+// This example demonstrates that text resembling the placeholders used
+// internally for cross-references is output unchanged.
+func ExampleFpdf_SetAnchor_literal() {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	pdf.AliasCrossRefs(6)
+	pdf.SetFont("Times", "", 12)
+	pdf.AddPage()
+	pdf.SetAnchor("start")
+	pdf.Cell(0, 6, "Page {ref:start}, literal {ref#0} and {ref(0)}")
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err == nil {
+		for _, str := range []string{"[(1) ", "literal {ref#0} and {ref\\(0\\)}) Tj"} {
+			fmt.Printf("%s: %v\n", str, strings.Contains(buf.String(), str))
+		}
+	} else {
+		fmt.Println(err)
+	}
+	// Output:
+	// [(1) : true
+	// literal {ref#0} and {ref\(0\)}) Tj: true
+}
+
+// This example demonstrates that links created within a label are placed on
+// that label rather than at the corner of the sheet.
+func ExampleFpdf_Labels_links() {
+	pdf := gofpdf.New("P", "pt", "Letter", "")
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 10)
+	sheet := gofpdf.LabelSheetType{UnitStr: "pt", Page: gofpdf.SizeType{Wd: 612, Ht: 792},
+		Cols: 2, Rows: 2, Label: gofpdf.SizeType{Wd: 200, Ht: 100}, Left: 50, Top: 100,
+		Pitch: gofpdf.SizeType{Wd: 300, Ht: 200}}
+	pdf.Labels(sheet, 0, 2, func(index int, size gofpdf.SizeType) {
+		pdf.SetXY(10, 10)
+		pdf.CellFormat(100, 20, "Visit", "", 0, "", false, 0, "http://www.fpdf.org")
+	})
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err == nil {
+		for _, str := range []string{"/Rect [62.84 677.00 81.72 667.00]",
+			"/Rect [362.83 677.00 381.72 667.00]"} {
+			fmt.Printf("%s: %v\n", str, strings.Contains(buf.String(), str))
+		}
+	} else {
+		fmt.Println(err)
+	}
+	// Output:
+	// /Rect [62.84 677.00 81.72 667.00]: true
+	// /Rect [362.83 677.00 381.72 667.00]: true
+}
@@ -0,0 +1,1638 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// htmlNodeType is an element or a run of text in an HTML document tree
+type htmlNodeType struct {
+	tag      string // lower case element name, or empty for text
+	text     string
+	attr     map[string]string
+	parent   *htmlNodeType
+	children []*htmlNodeType
+}
+
+// htmlColorType is a color specified in a style; set is false if the color
+// is transparent or unspecified. clr is the document color that a color
+// inherited from the caller of Write() retains, so that it keeps its color
+// space; its str is empty for colors specified in RGB by a style sheet.
+type htmlColorType struct {
+	r, g, b int
+	set     bool
+	clr     clrType
+}
+
+type htmlBorderType struct {
+	width float64
+	style string
+	color htmlColorType
+}
+
+// htmlStyleType holds the computed style of an element. Lengths are in the
+// unit of measure of the document except for the font size, which is in
+// points. The four-element arrays are in the order top, right, bottom, left.
+type htmlStyleType struct {
+	display       string
+	fontFamily    string
+	fontSize      float64
+	bold          bool
+	italic        bool
+	underline     bool
+	color         htmlColorType
+	background    htmlColorType
+	textAlign     string // "L", "C", "R" or "J"
+	lineHeight    float64
+	whiteSpace    string
+	listStyle     string
+	verticalAlign string
+	margin        [4]float64
+	autoMargin    [4]bool
+	padding       [4]float64
+	border        [4]htmlBorderType
+	width, height float64 // zero if not specified
+}
+
+// hextra returns the combined width of the horizontal padding and borders
+func (st *htmlStyleType) hextra() float64 {
+	return st.padding[1] + st.padding[3] + st.border[1].width + st.border[3].width
+}
+
+// vextra returns the combined height of the vertical padding and borders
+func (st *htmlStyleType) vextra() float64 {
+	return st.padding[0] + st.padding[2] + st.border[0].width + st.border[2].width
+}
+
+// decorated returns true if the element has a background or a border
+func (st *htmlStyleType) decorated() bool {
+	for _, b := range st.border {
+		if b.width > 0 {
+			return true
+		}
+	}
+	return st.background.set
+}
+
+// htmlBoxType specifies the horizontal extent of a box as distances from the
+// left and right page margins
+type htmlBoxType struct {
+	left, right float64
+}
+
+// htmlSegType is the part of a decorated box that lies on one page or in one
+// column
+type htmlSegType struct {
+	page, offset       int // page and position in its content for the decoration
+	x, wd, top, bottom float64
+	pageHt             float64
+}
+
+// htmlDecorType tracks the background and borders of a box that is being
+// laid out. They are inserted into the page content beneath the content of
+// the box once its extent is known.
+type htmlDecorType struct {
+	st   *htmlStyleType
+	box  htmlBoxType
+	segs []htmlSegType
+}
+
+// htmlMarkerType is the label of a list item that is pending output with the
+// first line of the item
+type htmlMarkerType struct {
+	st      *htmlStyleType
+	textStr string // empty for a graphical marker
+	shape   string // "disc", "circle" or "square"
+}
+
+// Kinds of inline content
+const (
+	htmlItemWord   = iota // text without white space
+	htmlItemSpace         // white space between words
+	htmlItemBreak         // forced line break
+	htmlItemBlock         // boundary of a nested block; ends a line that is not empty
+	htmlItemImage         // image
+	htmlItemAnchor        // destination of internal links
+)
+
+// htmlItemType is an element of inline content
+type htmlItemType struct {
+	kind    int
+	textStr string // text, image name or anchor name
+	st      *htmlStyleType
+	link    string
+	wd, ht  float64
+	keep    bool // preserved white space
+}
+
+// htmlRowType is a table row with its cells
+type htmlRowType struct {
+	st    htmlStyleType
+	cells []htmlCellType
+	head  bool
+}
+
+// htmlCellType is a table cell that spans span columns beginning with col
+type htmlCellType struct {
+	node      *htmlNodeType
+	st        htmlStyleType
+	col, span int
+}
+
+// htmlPlaceType is the horizontal extent of a table cell, the box of its
+// content and the height of its content
+type htmlPlaceType struct {
+	x, wd float64
+	box   htmlBoxType
+	ht    float64
+}
+
+// htmlRowPageType is a page occupied by a table row that is split between
+// pages. top is the vertical position at which the row, or its continuation,
+// begins, bottom is the lowest position at which a cell has left the page
+// and offset is the position in the page content at which the backgrounds
+// and borders of its cells are inserted.
+type htmlRowPageType struct {
+	page        int
+	top, bottom float64
+	offset      int
+	pageHt      float64
+}
+
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"body": true, "caption": true, "center": true, "dd": true, "dir": true,
+	"div": true, "dl": true, "dt": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"html": true, "li": true, "main": true, "menu": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "table": true,
+	"tbody": true, "td": true, "tfoot": true, "th": true, "thead": true,
+	"tr": true, "ul": true,
+}
+
+var htmlVoidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "wbr": true,
+}
+
+// htmlDefaultCSS is the default style sheet, which approximates the
+// rendering of web browsers
+const htmlDefaultCSS = `
+h1 { font-size: 2em; font-weight: bold; margin: 0.67em 0 }
+h2 { font-size: 1.5em; font-weight: bold; margin: 0.83em 0 }
+h3 { font-size: 1.17em; font-weight: bold; margin: 1em 0 }
+h4 { font-weight: bold; margin: 1.33em 0 }
+h5 { font-size: 0.83em; font-weight: bold; margin: 1.67em 0 }
+h6 { font-size: 0.67em; font-weight: bold; margin: 2.33em 0 }
+p, dl, pre, table { margin: 1em 0 }
+table table { margin: 0 }
+blockquote { margin: 1em 40px }
+ul, ol, menu, dir { margin: 1em 0; padding-left: 40px }
+li ul, li ol { margin: 0 }
+ol { list-style-type: decimal }
+ul ul, ol ul { list-style-type: circle }
+ul ul ul, ul ol ul, ol ul ul, ol ol ul { list-style-type: square }
+dd { margin-left: 40px }
+pre, code, kbd, samp, tt { font-family: monospace }
+pre { white-space: pre }
+b, strong, th { font-weight: bold }
+i, em, cite, var, dfn, address { font-style: italic }
+u, ins { text-decoration: underline }
+center, th, caption { text-align: center }
+small { font-size: smaller }
+big { font-size: larger }
+sub { font-size: smaller; vertical-align: sub }
+sup { font-size: smaller; vertical-align: super }
+td, th { padding: 1px; vertical-align: middle }
+hr { border-top: 1px solid gray; margin: 0.5em 0 }
+`
+
+// HTMLType is used for rendering a subset of HTML that is styled with CSS.
+// Unlike HTMLBasicType, it lays out the content of the document as a web
+// browser would: as a sequence of blocks, such as headings, paragraphs,
+// lists and tables, that are filled with lines of text and images.
+//
+// The following elements are supported: headings (H1 through H6), P, DIV,
+// SPAN, BLOCKQUOTE, PRE, CENTER and the HTML5 sectioning elements; the
+// inline elements A, B, STRONG, I, EM, U, INS, CODE, SMALL, BIG, SUB, SUP and
+// BR; ordered and unordered lists (OL, UL and LI) and definition lists (DL,
+// DT and DD); tables (TABLE, CAPTION, THEAD, TBODY, TFOOT, TR, TH and TD);
+// IMG and HR. Other elements are rendered as their content. The content of
+// HEAD, SCRIPT, STYLE and TITLE elements is not rendered.
+//
+// Elements are styled with the default style sheet, which approximates the
+// rendering of web browsers, style sheets added with StyleSheet(), STYLE
+// elements within the HTML and the style attributes of elements. Selectors
+// consist of element names, classes (".note") and identifiers ("#intro"),
+// optionally combined with descendant and child combinators, as in "div.note
+// > p". The following properties are supported: font-family, font-size,
+// font-weight, font-style, text-decoration, color, background-color,
+// text-align, line-height, white-space, list-style-type, vertical-align,
+// display (block, inline, list-item and none), width, height, and the
+// margin, padding and border properties including their shorthands. Lengths
+// may be specified in px (a px is 0.75 pt), pt, pc, in, cm, mm, em, ex and
+// percent.
+//
+// The generic font families serif, sans-serif and monospace and the names
+// Times, Arial, Helvetica and Courier select the corresponding core fonts.
+// Other names select fonts that have been added to the document with names
+// such as AddFont(). The font used for text that is not otherwise styled is
+// the current font of the document.
+//
+// Text is written without translation, so it needs to be in the encoding of
+// the fonts that are used, as it is for Write(). Character references such as
+// "&eacute;" are converted to the encoding of the current font; see
+// HTMLTokenizerType.
+type HTMLType struct {
+	pdf             *Fpdf
+	defRules        []cssRuleType
+	rules           []cssRuleType
+	order           int
+	links           map[string]int
+	acceptPageBreak func() bool
+	baseSize        float64
+	noBreak         int
+	fresh           bool // nothing has been placed since the last page break
+	placed          bool // something has been placed since the beginning or the last page break
+	pendingMargin   float64
+	decors          []*htmlDecorType
+	anchors         []string
+	counters        []int
+	marker          *htmlMarkerType
+	rowPages        []htmlRowPageType // pages of a table row being split, nil otherwise
+	rowPage         int               // index into rowPages of the current page
+	rowDecors       int               // number of decorated boxes that enclose a split table row
+}
+
+// HTMLNew returns an instance that renders HTML in the specified PDF
+// document. See HTMLType for details.
+//
+// The HTMLNew() example demonstrates this method.
+func (f *Fpdf) HTMLNew() (html HTMLType) {
+	html.pdf = f
+	html.defRules = cssParse(htmlDefaultCSS, &html.order)
+	html.links = make(map[string]int)
+	return
+}
+
+// StyleSheet adds the rules of the specified CSS style sheet to those used
+// for subsequent calls to Write(). Rules that are added later take
+// precedence over earlier rules with the same specificity. At-rules, such as
+// @media, and selectors with features that are not supported, such as
+// attribute selectors and pseudo-classes, are ignored.
+//
+// The HTMLNew() example demonstrates this method.
+func (html *HTMLType) StyleSheet(cssStr string) {
+	html.rules = append(html.rules, cssParse(cssStr, &html.order)...)
+}
+
+// Write renders the specified HTML beginning at the left margin of the
+// current vertical position. Lines fill the space between the left and right
+// margins, and page breaks occur as needed in the same way as for
+// MultiCell(). A table row is moved to the next page rather than split,
+// unless it is too tall to fit on a page by itself, in which case each of
+// its cells is continued on the following pages. The header rows (THEAD) of
+// a table that spans pages are repeated at the top of each page, except on
+// the pages occupied by such a tall row. The vertical margins of the first
+// and last blocks are suppressed. Upon method exit, the current position is
+// at the left margin below the rendered content, and the font, colors and
+// line width are restored.
+//
+// Links to fragment identifiers, such as href="#summary", are internal links
+// to the element with the corresponding id attribute or the A element with
+// the corresponding name attribute. The identifiers are retained from one
+// call to Write() to the next, so a document can be rendered in parts. Rules
+// in STYLE elements apply only to the HTML in which they appear.
+//
+// The HTMLNew() example demonstrates this method.
+func (html *HTMLType) Write(htmlStr string) {
+	f := html.pdf
+	if f.err != nil {
+		return
+	}
+	root := htmlParse(f.HTMLTokenizerNew(htmlStr))
+	rules := html.rules
+	var addStyles func(n *htmlNodeType)
+	addStyles = func(n *htmlNodeType) {
+		if n.tag == "style" {
+			for _, c := range n.children {
+				html.StyleSheet(c.text)
+			}
+		}
+		for _, c := range n.children {
+			addStyles(c)
+		}
+	}
+	addStyles(root)
+	familyStr, styleStr, sizePt := f.fontFamily, f.fontStyle, f.fontSizePt
+	if f.underline {
+		styleStr += "U"
+	}
+	draw, fill, text := f.color.draw, f.color.fill, f.color.text
+	lw, cMargin := f.lineWidth, f.cMargin
+	html.acceptPageBreak = f.acceptPageBreak
+	f.acceptPageBreak = func() bool { return false }
+	f.cMargin = 0
+	html.fresh, html.placed, html.pendingMargin = false, false, 0
+	html.noBreak, html.decors, html.counters, html.marker = 0, nil, nil, nil
+	html.anchors = nil
+	st := html.rootStyle()
+	f.x = f.lMargin
+	html.flow(root, &st, htmlBoxType{})
+	html.putAnchors()
+	f.acceptPageBreak = html.acceptPageBreak
+	f.cMargin = cMargin
+	if familyStr != "" {
+		f.SetFont(familyStr, styleStr, sizePt)
+	}
+	f.setDrawClr(draw)
+	f.setFillClr(fill)
+	f.setTextClr(text)
+	f.SetLineWidth(lw)
+	f.x = f.lMargin
+	html.rules = rules
+}
+
+// rootStyle returns the style of the document, which is based on the current
+// font and text color
+func (html *HTMLType) rootStyle() (st htmlStyleType) {
+	f := html.pdf
+	st.display = "block"
+	st.fontFamily = f.fontFamily
+	st.fontSize = f.fontSizePt
+	if st.fontFamily == "" {
+		st.fontFamily = "helvetica"
+		st.fontSize = 12
+	}
+	st.bold = strings.Contains(f.fontStyle, "B")
+	st.italic = strings.Contains(f.fontStyle, "I")
+	st.underline = f.underline
+	text := f.color.text
+	st.color = htmlColorType{r: text.ir, g: text.ig, b: text.ib, set: true, clr: text}
+	st.textAlign = "L"
+	st.lineHeight = 1.2
+	st.whiteSpace = "normal"
+	st.listStyle = "disc"
+	html.baseSize = st.fontSize
+	return
+}
+
+// flow lays out the content of the block container n, whose content box is
+// box
+func (html *HTMLType) flow(n *htmlNodeType, st *htmlStyleType, box htmlBoxType) {
+	_, wd := html.boxX(box)
+	var inline []*htmlNodeType
+	flush := func() {
+		if len(inline) > 0 {
+			html.inlines(inline, st, box)
+			inline = inline[:0]
+		}
+	}
+	for _, c := range n.children {
+		if c.tag == "" || c.tag == "img" {
+			inline = append(inline, c)
+			continue
+		}
+		cs := html.computeStyle(c, st, wd)
+		switch cs.display {
+		case "none":
+		case "inline":
+			inline = append(inline, c)
+		default:
+			flush()
+			html.block(c, &cs, box)
+		}
+		if html.pdf.err != nil {
+			return
+		}
+	}
+	flush()
+}
+
+// block lays out the block-level element n within the content box of its
+// container
+func (html *HTMLType) block(n *htmlNodeType, st *htmlStyleType, box htmlBoxType) {
+	f := html.pdf
+	_, wd := html.boxX(box)
+	if n.tag == "table" && st.width == 0 {
+		avail := wd - st.margin[1] - st.margin[3] - st.hextra()
+		rows, cols := html.tableRows(n, st, avail)
+		for _, w := range html.tableWidths(rows, cols, avail, false) {
+			st.width += w
+		}
+	}
+	// Horizontal extent of the border box
+	outer := box
+	outer.left += st.margin[3]
+	outer.right += st.margin[1]
+	if st.width > 0 {
+		bw := st.width + st.hextra()
+		free := wd - st.margin[1] - st.margin[3] - bw
+		if free > 0 && st.autoMargin[3] {
+			if st.autoMargin[1] {
+				free /= 2
+			}
+			outer.left += free
+		}
+		outer.right = box.left + wd + box.right - outer.left - bw
+	}
+	inner := outer
+	inner.left += st.border[3].width + st.padding[3]
+	inner.right += st.border[1].width + st.padding[1]
+	top := st.border[0].width + st.padding[0]
+	bottom := st.border[2].width + st.padding[2]
+	html.margin(st.margin[0])
+	var d *htmlDecorType
+	if st.decorated() {
+		html.applyMargin()
+		html.ensure(top + bottom + st.fontSize/f.k*st.lineHeight)
+		d = &htmlDecorType{st: st, box: outer}
+		d.segs = append(d.segs, html.segment(outer))
+		html.decors = append(html.decors, d)
+	}
+	if top > 0 {
+		html.applyMargin()
+		html.putAnchors()
+		f.y += top
+		html.fresh, html.placed = false, true
+	}
+	if id := n.attr["id"]; id != "" {
+		html.anchors = append(html.anchors, id)
+	}
+	page, contentTop := f.page, f.y
+	if st.display == "list-item" {
+		html.startItem(n, st)
+	}
+	switch n.tag {
+	case "table":
+		html.table(n, st, inner)
+	case "ul", "ol", "menu", "dir":
+		start := 1
+		if n.tag == "ol" {
+			if v, err := strconv.Atoi(n.attr["start"]); err == nil {
+				start = v
+			}
+		}
+		html.counters = append(html.counters, start-1)
+		html.flow(n, st, inner)
+		html.counters = html.counters[:len(html.counters)-1]
+	default:
+		html.flow(n, st, inner)
+	}
+	html.marker = nil
+	if bottom > 0 || st.height > 0 {
+		// The bottom margin of the last child does not collapse through
+		// padding and borders
+		html.applyMargin()
+	}
+	if st.height > 0 && f.page == page && f.y < contentTop+st.height {
+		f.y = contentTop + st.height
+		html.fresh, html.placed = false, true
+	}
+	if bottom > 0 {
+		f.y += bottom
+		html.fresh, html.placed = false, true
+	}
+	if d != nil {
+		html.closeDecor(d)
+	}
+	html.margin(st.margin[2])
+}
+
+// inlines lays out a sequence of inline content as lines of text
+func (html *HTMLType) inlines(nodes []*htmlNodeType, st *htmlStyleType, box htmlBoxType) {
+	_, wd := html.boxX(box)
+	var items []htmlItemType
+	for _, n := range nodes {
+		items = html.collect(n, st, wd, "", items)
+	}
+	visible := false
+	for _, it := range items {
+		switch it.kind {
+		case htmlItemWord, htmlItemImage, htmlItemBreak:
+			visible = true
+		}
+	}
+	if !visible {
+		// Content made up of white space produces no lines
+		for _, it := range items {
+			if it.kind == htmlItemAnchor {
+				html.anchors = append(html.anchors, it.textStr)
+			}
+		}
+		return
+	}
+	html.layout(items, st, box)
+}
+
+// collect appends the inline content of node n, whose parent has style st,
+// to items. wd is the width of the containing block and link is the target
+// of the enclosing hyperlink, if any.
+func (html *HTMLType) collect(n *htmlNodeType, st *htmlStyleType, wd float64, link string, items []htmlItemType) []htmlItemType {
+	f := html.pdf
+	if n.tag == "" {
+		return html.collectText(n, st, link, items)
+	}
+	cs := html.computeStyle(n, st, wd)
+	if cs.display == "none" {
+		return items
+	}
+	if id := n.attr["id"]; id != "" {
+		items = append(items, htmlItemType{kind: htmlItemAnchor, textStr: id})
+	}
+	if name := n.attr["name"]; name != "" && n.tag == "a" {
+		items = append(items, htmlItemType{kind: htmlItemAnchor, textStr: name})
+	}
+	block := cs.display != "inline"
+	if block {
+		items = append(items, htmlItemType{kind: htmlItemBlock, st: &cs})
+	}
+	switch n.tag {
+	case "br":
+		items = append(items, htmlItemType{kind: htmlItemBreak, st: &cs})
+	case "img":
+		srcStr := n.attr["src"]
+		info := f.GetImageInfo(srcStr)
+		if info == nil {
+			info = f.RegisterImageOptions(srcStr, ImageOptions{})
+		}
+		if f.err != nil {
+			return items
+		}
+		imgWd, imgHt := info.Extent()
+		w, h := cs.width, cs.height
+		switch {
+		case w == 0 && h == 0:
+			w, h = imgWd, imgHt
+		case w == 0:
+			w = h * imgWd / imgHt
+		case h == 0:
+			h = w * imgHt / imgWd
+		}
+		if w > wd && wd > 0 {
+			w, h = wd, h*wd/w
+		}
+		items = append(items, htmlItemType{kind: htmlItemImage, textStr: srcStr,
+			st: &cs, link: link, wd: w, ht: h})
+	default:
+		if href := n.attr["href"]; n.tag == "a" && href != "" {
+			link = href
+		}
+		for _, c := range n.children {
+			items = html.collect(c, &cs, wd, link, items)
+		}
+	}
+	if block {
+		items = append(items, htmlItemType{kind: htmlItemBlock, st: &cs})
+	}
+	return items
+}
+
+// collectText appends the words and white space of text node n to items
+func (html *HTMLType) collectText(n *htmlNodeType, st *htmlStyleType, link string, items []htmlItemType) []htmlItemType {
+	textStr := strings.Replace(n.text, "\r", "", -1)
+	isSpace := func(ch byte) bool {
+		return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\f'
+	}
+	word := func(str string) {
+		items = append(items, htmlItemType{kind: htmlItemWord, textStr: str, st: st,
+			link: link, wd: html.stringWidth(st, str)})
+	}
+	space := func(keep bool) {
+		items = append(items, htmlItemType{kind: htmlItemSpace, textStr: " ", st: st,
+			link: link, wd: html.stringWidth(st, " "), keep: keep})
+	}
+	if st.whiteSpace == "pre" || st.whiteSpace == "pre-wrap" {
+		if p := n.parent; p != nil && p.tag == "pre" && len(p.children) > 0 && p.children[0] == n {
+			// A line break that immediately follows the start tag is ignored
+			textStr = strings.TrimPrefix(textStr, "\n")
+		}
+		for j, line := range strings.Split(textStr, "\n") {
+			if j > 0 {
+				items = append(items, htmlItemType{kind: htmlItemBreak, st: st})
+			}
+			line = strings.Replace(line, "\t", "    ", -1)
+			for k, wordStr := range strings.Split(line, " ") {
+				if k > 0 {
+					space(true)
+				}
+				if wordStr != "" {
+					word(wordStr)
+				}
+			}
+		}
+		return items
+	}
+	for pos := 0; pos < len(textStr); {
+		if isSpace(textStr[pos]) {
+			if len(items) == 0 || items[len(items)-1].kind != htmlItemSpace {
+				space(false)
+			}
+			for pos < len(textStr) && isSpace(textStr[pos]) {
+				pos++
+			}
+			continue
+		}
+		end := pos
+		for end < len(textStr) && !isSpace(textStr[end]) {
+			end++
+		}
+		word(textStr[pos:end])
+		pos = end
+	}
+	return items
+}
+
+// layout breaks the specified inline content into lines that fit the content
+// box of the containing block and outputs them
+func (html *HTMLType) layout(items []htmlItemType, st *htmlStyleType, box htmlBoxType) {
+	f := html.pdf
+	for len(items) > 0 && f.err == nil {
+		// White space at the start of a line is dropped
+		for len(items) > 0 && items[0].kind == htmlItemSpace && !items[0].keep {
+			items = items[1:]
+		}
+		if len(items) == 0 {
+			break
+		}
+		_, wd := html.boxX(box)
+		var lineWd float64
+		end, next := len(items), len(items)
+		breakAt := -1
+		forced := false
+		split := false
+		for j := 0; j < len(items); j++ {
+			it := items[j]
+			if it.kind == htmlItemBreak || it.kind == htmlItemBlock {
+				end, next = j, j+1
+				forced = it.kind == htmlItemBreak || j > 0
+				break
+			}
+			if it.kind == htmlItemSpace {
+				if it.st.whiteSpace != "nowrap" {
+					breakAt = j
+				}
+				lineWd += it.wd
+				continue
+			}
+			if lineWd+it.wd > wd+1e-9 && it.st.whiteSpace != "nowrap" {
+				if breakAt > 0 {
+					end, next = breakAt, breakAt
+					break
+				}
+				if j > 0 {
+					end, next = j, j
+					break
+				}
+				if it.kind == htmlItemWord && len(it.textStr) > 1 {
+					// A word that is wider than the line is broken
+					items = html.splitWord(items, wd)
+					split = true
+					break
+				}
+			}
+			lineWd += it.wd
+		}
+		if split {
+			continue
+		}
+		if end == 0 && !forced {
+			// Boundary of a nested block at the start of a line
+			items = items[next:]
+			continue
+		}
+		justify := st.textAlign == "J" && !forced && next < len(items)
+		html.putLine(items[:end], st, box, justify)
+		items = items[next:]
+	}
+}
+
+// splitWord breaks the first item, a word, after as many characters as fit
+// in width wd
+func (html *HTMLType) splitWord(items []htmlItemType, wd float64) []htmlItemType {
+	it := items[0]
+	pos := 1
+	for pos < len(it.textStr)-1 && html.stringWidth(it.st, it.textStr[:pos+1]) <= wd {
+		pos++
+	}
+	head, tail := it, it
+	head.textStr, tail.textStr = it.textStr[:pos], it.textStr[pos:]
+	head.wd, tail.wd = html.stringWidth(it.st, head.textStr), html.stringWidth(it.st, tail.textStr)
+	list := make([]htmlItemType, 0, len(items)+2)
+	list = append(list, head, htmlItemType{kind: htmlItemBlock, st: it.st}, tail)
+	return append(list, items[1:]...)
+}
+
+// putLine outputs a line of inline content at the current vertical position
+func (html *HTMLType) putLine(line []htmlItemType, st *htmlStyleType, box htmlBoxType, justify bool) {
+	f := html.pdf
+	// White space at the end of the line is dropped
+	for len(line) > 0 && line[len(line)-1].kind == htmlItemSpace && !line[len(line)-1].keep {
+		line = line[:len(line)-1]
+	}
+	// The baseline is placed so that the line accommodates the content
+	// with the largest extent above and below it, including the font of the
+	// containing block
+	fs := st.fontSize / f.k
+	lh := fs * st.lineHeight
+	asc, desc := 0.5*lh+0.3*fs, 0.5*lh-0.3*fs
+	var lineWd float64
+	spaces := 0
+	for _, it := range line {
+		lineWd += it.wd
+		var a, d float64
+		switch it.kind {
+		case htmlItemWord, htmlItemSpace:
+			fs := it.st.fontSize / f.k
+			lh := fs * it.st.lineHeight
+			a, d = 0.5*lh+0.3*fs, 0.5*lh-0.3*fs
+			if it.kind == htmlItemSpace {
+				spaces++
+			}
+		case htmlItemImage:
+			a = it.ht
+		default:
+			continue
+		}
+		shift := html.shift(it.st)
+		if a+shift > asc {
+			asc = a + shift
+		}
+		if d-shift > desc {
+			desc = d - shift
+		}
+	}
+	html.applyMargin()
+	html.ensure(asc + desc)
+	html.putAnchors()
+	top := f.y
+	baseline := top + asc
+	x, wd := html.boxX(box)
+	if html.marker != nil {
+		html.putMarker(x, baseline)
+	}
+	var extra float64
+	switch st.textAlign {
+	case "C":
+		x += (wd - lineWd) / 2
+	case "R":
+		x += wd - lineWd
+	case "J":
+		if justify && spaces > 0 && wd > lineWd {
+			extra = (wd - lineWd) / float64(spaces)
+		}
+	}
+	for j := 0; j < len(line); {
+		it := line[j]
+		switch it.kind {
+		case htmlItemImage:
+			linkID, linkStr := html.link(it.link)
+			f.ImageOptions(it.textStr, x, baseline-html.shift(it.st)-it.ht, it.wd, it.ht,
+				false, ImageOptions{}, linkID, linkStr)
+			x += it.wd
+			j++
+		case htmlItemAnchor:
+			f.y = top
+			html.anchor(it.textStr)
+			j++
+		default:
+			// Consecutive text with the same style and link is output
+			// together; when justifying, each space is output separately
+			textStr := it.textStr
+			w := it.wd
+			k := j + 1
+			if it.kind == htmlItemSpace && extra > 0 {
+				w += extra
+			} else {
+				for ; k < len(line); k++ {
+					nx := line[k]
+					if nx.st != it.st || nx.link != it.link || nx.kind == htmlItemImage ||
+						nx.kind == htmlItemAnchor || nx.kind == htmlItemSpace && extra > 0 {
+						break
+					}
+					textStr += nx.textStr
+					w += nx.wd
+				}
+			}
+			html.putText(it.st, it.link, x, baseline, textStr, w)
+			x += w
+			j = k
+		}
+	}
+	f.x = f.lMargin
+	f.y = top + asc + desc
+	html.fresh, html.placed = false, true
+}
+
+// putText outputs textStr in a cell of width w whose baseline is at the
+// specified position
+func (html *HTMLType) putText(st *htmlStyleType, link string, x, baseline float64, textStr string, w float64) {
+	f := html.pdf
+	fs := st.fontSize / f.k
+	lh := fs * st.lineHeight
+	html.setFont(st)
+	html.setTextColor(st.color)
+	fill := st.display == "inline" && st.background.set
+	if fill {
+		html.setFillColor(st.background)
+	}
+	f.x = x
+	f.y = baseline - html.shift(st) - 0.5*lh - 0.3*fs
+	linkID, linkStr := html.link(link)
+	f.CellFormat(w, lh, textStr, "", 0, "L", fill, linkID, linkStr)
+}
+
+// shift returns the distance by which the baseline of content with the
+// specified style is raised
+func (html *HTMLType) shift(st *htmlStyleType) float64 {
+	switch st.verticalAlign {
+	case "super":
+		return 0.4 * st.fontSize / html.pdf.k
+	case "sub":
+		return -0.25 * st.fontSize / html.pdf.k
+	}
+	return 0
+}
+
+// startItem prepares the marker of the list item n
+func (html *HTMLType) startItem(n *htmlNodeType, st *htmlStyleType) {
+	if len(html.counters) == 0 {
+		html.counters = append(html.counters, 0)
+	}
+	last := len(html.counters) - 1
+	html.counters[last]++
+	if v, err := strconv.Atoi(n.attr["value"]); err == nil {
+		html.counters[last] = v
+	}
+	m := &htmlMarkerType{st: st}
+	switch st.listStyle {
+	case "disc", "circle", "square":
+		m.shape = st.listStyle
+	case "decimal":
+		m.textStr = listNumber("1.", html.counters[last])
+	case "lower-alpha", "lower-latin":
+		m.textStr = listNumber("a.", html.counters[last])
+	case "upper-alpha", "upper-latin":
+		m.textStr = listNumber("A.", html.counters[last])
+	case "lower-roman":
+		m.textStr = listNumber("i.", html.counters[last])
+	case "upper-roman":
+		m.textStr = listNumber("I.", html.counters[last])
+	default:
+		m = nil
+	}
+	html.marker = m
+}
+
+// setTextColor makes clr the current text color
+func (html *HTMLType) setTextColor(clr htmlColorType) {
+	if clr.clr.str != "" {
+		html.pdf.setTextClr(clr.clr)
+	} else {
+		html.pdf.SetTextColor(clr.r, clr.g, clr.b)
+	}
+}
+
+// setFillColor makes clr the current fill color
+func (html *HTMLType) setFillColor(clr htmlColorType) {
+	if clr.clr.str != "" {
+		html.pdf.setFillClr(clr.clr)
+	} else {
+		html.pdf.SetFillColor(clr.r, clr.g, clr.b)
+	}
+}
+
+// setDrawColor makes clr the current draw color
+func (html *HTMLType) setDrawColor(clr htmlColorType) {
+	if clr.clr.str != "" {
+		html.pdf.setDrawClr(strokeClr(clr.clr))
+	} else {
+		html.pdf.SetDrawColor(clr.r, clr.g, clr.b)
+	}
+}
+
+// putMarker outputs the pending list item marker to the left of x
+func (html *HTMLType) putMarker(x, baseline float64) {
+	f := html.pdf
+	m := html.marker
+	html.marker = nil
+	fs := m.st.fontSize / f.k
+	clr := m.st.color
+	if m.textStr != "" {
+		w := html.stringWidth(m.st, m.textStr)
+		html.putText(m.st, "", x-0.5*fs-w, baseline, m.textStr, w)
+		return
+	}
+	r := 0.18 * fs
+	cx, cy := x-0.6*fs-r, baseline-0.3*fs
+	html.setFillColor(clr)
+	html.setDrawColor(clr)
+	switch m.shape {
+	case "disc":
+		f.Circle(cx, cy, r, "F")
+	case "circle":
+		f.SetLineWidth(0.07 * fs)
+		f.Circle(cx, cy, r, "D")
+	case "square":
+		f.Rect(cx-r, cy-r, 2*r, 2*r, "F")
+	}
+}
+
+// table lays out the table n within the specified content box
+func (html *HTMLType) table(n *htmlNodeType, st *htmlStyleType, box htmlBoxType) {
+	f := html.pdf
+	_, wd := html.boxX(box)
+	for _, c := range n.children {
+		if c.tag == "caption" {
+			cs := html.computeStyle(c, st, wd)
+			html.block(c, &cs, box)
+		}
+	}
+	rows, cols := html.tableRows(n, st, wd)
+	widths := html.tableWidths(rows, cols, wd, true)
+	heads := 0
+	for heads < len(rows) && rows[heads].head {
+		heads++
+	}
+	for j := range rows {
+		if html.tableRow(&rows[j], widths, box, j >= heads && heads > 0) {
+			// Header rows are repeated at the top of each page
+			for k := 0; k < heads; k++ {
+				html.tableRow(&rows[k], widths, box, false)
+			}
+			html.tableRow(&rows[j], widths, box, false)
+		}
+		if f.err != nil {
+			return
+		}
+	}
+}
+
+// tableRows returns the rows of table n. Header rows are placed first.
+func (html *HTMLType) tableRows(n *htmlNodeType, st *htmlStyleType, wd float64) (rows []htmlRowType, cols int) {
+	var addRow func(tr *htmlNodeType, parent *htmlStyleType, head bool)
+	addRow = func(tr *htmlNodeType, parent *htmlStyleType, head bool) {
+		row := htmlRowType{st: html.computeStyle(tr, parent, wd), head: head}
+		if row.st.display == "none" {
+			return
+		}
+		col := 0
+		for _, c := range tr.children {
+			if c.tag != "td" && c.tag != "th" {
+				continue
+			}
+			cell := htmlCellType{node: c, st: html.computeStyle(c, &row.st, wd), col: col, span: 1}
+			if v, err := strconv.Atoi(c.attr["colspan"]); err == nil && v > 1 {
+				cell.span = v
+			}
+			col += cell.span
+			row.cells = append(row.cells, cell)
+		}
+		if col > cols {
+			cols = col
+		}
+		rows = append(rows, row)
+	}
+	var head, body []htmlRowType
+	for _, c := range n.children {
+		switch c.tag {
+		case "tr":
+			addRow(c, st, false)
+		case "thead", "tbody", "tfoot":
+			sst := html.computeStyle(c, st, wd)
+			for _, tr := range c.children {
+				if tr.tag == "tr" {
+					addRow(tr, &sst, c.tag == "thead")
+				}
+			}
+		}
+	}
+	for _, row := range rows {
+		if row.head {
+			head = append(head, row)
+		} else {
+			body = append(body, row)
+		}
+	}
+	rows = append(head, body...)
+	return
+}
+
+// tableWidths determines the widths of the columns of a table whose width is
+// wd. If fixed is false, the table may be narrower if its content permits.
+func (html *HTMLType) tableWidths(rows []htmlRowType, cols int, wd float64, fixed bool) (widths []float64) {
+	if cols == 0 {
+		return
+	}
+	minWd := make([]float64, cols)
+	maxWd := make([]float64, cols)
+	for pass := 1; pass <= 2; pass++ {
+		// Cells that span a single column are considered first
+		for _, row := range rows {
+			for _, c := range row.cells {
+				if (c.span == 1) != (pass == 1) {
+					continue
+				}
+				mn, mx := html.prefWidths(c.node, &c.st, wd)
+				if c.st.width > 0 {
+					mx = c.st.width
+					if mn < mx {
+						mn = mx
+					}
+				}
+				mn += c.st.hextra()
+				mx += c.st.hextra()
+				if c.span == 1 {
+					minWd[c.col] = htmlMax(minWd[c.col], mn)
+					maxWd[c.col] = htmlMax(maxWd[c.col], mx)
+					continue
+				}
+				end := c.col + c.span
+				if end > cols {
+					end = cols
+				}
+				var sumMin, sumMax float64
+				for j := c.col; j < end; j++ {
+					sumMin += minWd[j]
+					sumMax += maxWd[j]
+				}
+				for j := c.col; j < end; j++ {
+					if mn > sumMin {
+						minWd[j] += (mn - sumMin) / float64(end-c.col)
+					}
+					if mx > sumMax {
+						maxWd[j] += (mx - sumMax) / float64(end-c.col)
+					}
+				}
+			}
+		}
+	}
+	var sumMin, sumMax float64
+	for j := range minWd {
+		maxWd[j] = htmlMax(maxWd[j], minWd[j])
+		sumMin += minWd[j]
+		sumMax += maxWd[j]
+	}
+	target := wd
+	if !fixed && sumMax < wd {
+		target = sumMax
+	}
+	widths = make([]float64, cols)
+	for j := range widths {
+		switch {
+		case sumMin >= target:
+			widths[j] = minWd[j] * target / sumMin
+		case sumMax <= target:
+			if sumMax > 0 {
+				widths[j] = maxWd[j] * target / sumMax
+			} else {
+				widths[j] = target / float64(cols)
+			}
+		default:
+			widths[j] = minWd[j] + (maxWd[j]-minWd[j])*(target-sumMin)/(sumMax-sumMin)
+		}
+	}
+	return
+}
+
+// prefWidths returns the width of the widest unbreakable part of the content
+// of element n and the width of its content when no lines are broken
+func (html *HTMLType) prefWidths(n *htmlNodeType, st *htmlStyleType, wd float64) (minWd, maxWd float64) {
+	var items []htmlItemType
+	for _, c := range n.children {
+		items = html.collect(c, st, wd, "", items)
+	}
+	var run, line float64
+	for _, it := range items {
+		switch it.kind {
+		case htmlItemWord, htmlItemImage:
+			run += it.wd
+			line += it.wd
+			minWd = htmlMax(minWd, run)
+		case htmlItemSpace:
+			run = 0
+			line += it.wd
+		case htmlItemBreak, htmlItemBlock:
+			maxWd = htmlMax(maxWd, line)
+			run, line = 0, 0
+		}
+	}
+	maxWd = htmlMax(maxWd, line)
+	return
+}
+
+// tableRow lays out a table row at the current vertical position. If repeat
+// is true and a page break occurs before the row, the row is not laid out
+// and true is returned so that the header rows can be repeated first.
+func (html *HTMLType) tableRow(row *htmlRowType, widths []float64, box htmlBoxType, repeat bool) (broken bool) {
+	f := html.pdf
+	x0, _ := html.boxX(box)
+	places := make([]htmlPlaceType, len(row.cells))
+	ht := row.st.height
+	for j := range row.cells {
+		c := &row.cells[j]
+		var p htmlPlaceType
+		p.x = x0
+		for k := 0; k < c.col+c.span && k < len(widths); k++ {
+			if k < c.col {
+				p.x += widths[k]
+			} else {
+				p.wd += widths[k]
+			}
+		}
+		left := p.x + c.st.border[3].width + c.st.padding[3]
+		right := p.x + p.wd - c.st.border[1].width - c.st.padding[1]
+		p.box = htmlBoxType{left: left - f.lMargin, right: f.w - f.rMargin - right}
+		f.Measure(func() {
+			y := f.y
+			html.cell(c, p.box, false)
+			p.ht = f.y - y
+		})
+		ht = htmlMax(ht, htmlMax(p.ht, c.st.height)+c.st.vextra())
+		places[j] = p
+	}
+	html.applyMargin()
+	if ht > f.pageBreakTrigger-f.tMargin && html.noBreak == 0 && html.rowPages == nil &&
+		!f.inHeader && !f.inFooter && f.pageBreakAllowed() {
+		// The row does not fit on any page
+		html.splitRow(row, places)
+		return false
+	}
+	if html.ensure(ht) && repeat {
+		return true
+	}
+	html.putAnchors()
+	top := f.y
+	for j := range row.cells {
+		c := &row.cells[j]
+		p := places[j]
+		if c.st.decorated() {
+			f.out(html.decorOps(&c.st, p.x, top, p.wd, ht, f.h, true, true))
+		}
+	}
+	for j := range row.cells {
+		c := &row.cells[j]
+		p := places[j]
+		f.y = top + c.st.border[0].width + c.st.padding[0]
+		switch free := ht - p.ht - c.st.vextra(); c.st.verticalAlign {
+		case "middle":
+			f.y += free / 2
+		case "bottom":
+			f.y += free
+		}
+		html.cell(c, p.box, false)
+	}
+	f.x = f.lMargin
+	f.y = top + ht
+	html.fresh, html.placed = false, true
+	return false
+}
+
+// splitRow lays out a table row that is too tall to fit on a page by itself
+// at the current vertical position, breaking pages within its cells. Each
+// cell begins at the top of the row and continues on the pages that the
+// cells before it have added. The backgrounds and borders of the cells are
+// inserted beneath the content on each page once the extent of the row is
+// known.
+func (html *HTMLType) splitRow(row *htmlRowType, places []htmlPlaceType) {
+	f := html.pdf
+	html.putAnchors()
+	top := f.y
+	if f.measure != nil {
+		// Page breaks are simulated without changing the page, so the tallest
+		// cell alone determines the extent of the row
+		tallest := 0
+		for j := range row.cells {
+			if places[j].ht > places[tallest].ht {
+				tallest = j
+			}
+		}
+		c := &row.cells[tallest]
+		f.y = top + c.st.border[0].width + c.st.padding[0]
+		html.fresh = false
+		html.cell(c, places[tallest].box, true)
+		f.x = f.lMargin
+		f.y += c.st.padding[2] + c.st.border[2].width
+		html.fresh, html.placed = false, true
+		return
+	}
+	html.rowPages = []htmlRowPageType{{page: f.page, top: top,
+		offset: f.pages[f.page].Len(), pageHt: f.h}}
+	html.rowDecors = len(html.decors)
+	last, bottom := 0, top
+	for j := range row.cells {
+		c := &row.cells[j]
+		if f.page != html.rowPages[0].page {
+			f.SetPage(html.rowPages[0].page)
+		}
+		html.rowPage = 0
+		f.y = top + c.st.border[0].width + c.st.padding[0]
+		html.fresh = false
+		html.cell(c, places[j].box, true)
+		if f.err != nil {
+			html.rowPages = nil
+			return
+		}
+		y := f.y + c.st.padding[2] + c.st.border[2].width
+		if html.rowPage > last {
+			last, bottom = html.rowPage, y
+		} else if html.rowPage == last && y > bottom {
+			bottom = y
+		}
+	}
+	rowPages := html.rowPages
+	html.rowPages = nil
+	// The boxes that enclose the table end on each page where the row extends
+	// furthest
+	for _, d := range html.decors[:html.rowDecors] {
+		for j := range d.segs[:len(d.segs)-1] {
+			for _, rp := range rowPages[:last] {
+				if d.segs[j].page == rp.page {
+					d.segs[j].bottom = htmlMax(d.segs[j].bottom, rp.bottom)
+				}
+			}
+		}
+	}
+	for n, rp := range rowPages[:last+1] {
+		segBottom := rp.bottom
+		if n == last {
+			segBottom = bottom
+		}
+		var s fmtBuffer
+		for j := range row.cells {
+			c := &row.cells[j]
+			if c.st.decorated() {
+				s.printf("%s\n", html.decorOps(&c.st, places[j].x, rp.top, places[j].wd,
+					segBottom-rp.top, rp.pageHt, n == 0, n == last))
+			}
+		}
+		buf := f.pages[rp.page]
+		tail := append([]byte(nil), buf.Bytes()[rp.offset:]...)
+		buf.Truncate(rp.offset)
+		buf.WriteString(s.String())
+		buf.Write(tail)
+	}
+	if f.page != rowPages[last].page {
+		f.SetPage(rowPages[last].page)
+	}
+	f.x = f.lMargin
+	f.y = bottom
+	html.fresh, html.placed = false, true
+}
+
+// cell lays out the content of a table cell at the current vertical
+// position. Page breaks occur within the cell only if breaks is true.
+func (html *HTMLType) cell(c *htmlCellType, box htmlBoxType, breaks bool) {
+	pendingMargin, placed, fresh, marker := html.pendingMargin, html.placed, html.fresh, html.marker
+	html.pendingMargin, html.placed, html.marker = 0, false, nil
+	if !breaks {
+		html.noBreak++
+	}
+	html.flow(c.node, &c.st, box)
+	if !breaks {
+		html.noBreak--
+	}
+	html.pendingMargin, html.placed, html.fresh, html.marker = pendingMargin, placed, fresh, marker
+}
+
+// boxX returns the left edge and width of the specified box
+func (html *HTMLType) boxX(box htmlBoxType) (x, wd float64) {
+	f := html.pdf
+	x = f.lMargin + box.left
+	wd = f.w - f.rMargin - box.right - x
+	return
+}
+
+// margin records a vertical margin that collapses with adjacent margins
+func (html *HTMLType) margin(m float64) {
+	if m > html.pendingMargin {
+		html.pendingMargin = m
+	}
+}
+
+// applyMargin advances the current position by the pending vertical margin
+// unless nothing has been placed on the page or in the document yet
+func (html *HTMLType) applyMargin() {
+	if html.placed {
+		html.pdf.y += html.pendingMargin
+	}
+	html.pendingMargin = 0
+}
+
+// ensure performs a page break if content of height ht would not fit below
+// the current position. It returns true if a page break occurred.
+func (html *HTMLType) ensure(ht float64) bool {
+	f := html.pdf
+	if html.noBreak > 0 || html.fresh || f.y+ht <= f.pageBreakTrigger ||
+		f.inHeader || f.inFooter {
+		return false
+	}
+	if html.rowPage+1 < len(html.rowPages) {
+		// A cell of a split table row continues on a page that an earlier
+		// cell of the row has added. Only the decorated boxes within the
+		// cell continue, since the others have continued on the page already.
+		y := f.y
+		html.leaveRowPage(y)
+		html.rowPage++
+		rp := html.rowPages[html.rowPage]
+		f.SetPage(rp.page)
+		f.y = rp.top
+		html.continueDecors(html.decors[html.rowDecors:], y)
+		html.fresh, html.placed, html.pendingMargin = true, false, 0
+		return true
+	}
+	if !f.pageBreakAllowed() {
+		return false
+	}
+	page, y := f.page, f.y
+	if html.acceptPageBreak() {
+		f.AddPageFormat(f.curOrientation, f.curPageSize)
+		if f.err != nil {
+			return false
+		}
+	}
+	if f.page == page && f.y == y {
+		return false
+	}
+	if html.rowPages != nil {
+		html.leaveRowPage(y)
+		html.rowPage = len(html.rowPages)
+		html.rowPages = append(html.rowPages, htmlRowPageType{page: f.page, top: f.y,
+			offset: f.pages[f.page].Len(), pageHt: f.h})
+	}
+	// Decorated boxes continue on the new page or in the new column
+	html.continueDecors(html.decors, y)
+	html.fresh, html.placed, html.pendingMargin = true, false, 0
+	return true
+}
+
+// leaveRowPage records y, the position at which a cell of a split table row
+// leaves the current page, as the bottom of the row on that page if it is
+// lower than the recorded bottom
+func (html *HTMLType) leaveRowPage(y float64) {
+	rp := &html.rowPages[html.rowPage]
+	rp.bottom = htmlMax(rp.bottom, y)
+}
+
+// continueDecors ends the current segments of the specified decorated boxes
+// at y and begins new segments at the current position
+func (html *HTMLType) continueDecors(decors []*htmlDecorType, y float64) {
+	for _, d := range decors {
+		d.segs[len(d.segs)-1].bottom = y
+		d.segs = append(d.segs, html.segment(d.box))
+	}
+}
+
+// segment begins a segment of a decorated box at the current position
+func (html *HTMLType) segment(box htmlBoxType) (seg htmlSegType) {
+	f := html.pdf
+	seg.page = f.page
+	seg.x, seg.wd = html.boxX(box)
+	seg.top = f.y
+	seg.pageHt = f.h
+	if f.measure == nil && f.page > 0 && f.page < len(f.pages) {
+		seg.offset = f.pages[f.page].Len()
+	}
+	return
+}
+
+// closeDecor ends the decorated box d at the current position and inserts
+// its background and borders beneath its content
+func (html *HTMLType) closeDecor(d *htmlDecorType) {
+	f := html.pdf
+	d.segs[len(d.segs)-1].bottom = f.y
+	html.decors = html.decors[:len(html.decors)-1]
+	if f.measure != nil {
+		return
+	}
+	for j := len(d.segs) - 1; j >= 0; j-- {
+		seg := d.segs[j]
+		if seg.page < 1 || seg.page >= len(f.pages) {
+			continue
+		}
+		ops := html.decorOps(d.st, seg.x, seg.top, seg.wd, seg.bottom-seg.top, seg.pageHt,
+			j == 0, j == len(d.segs)-1)
+		buf := f.pages[seg.page]
+		tail := append([]byte(nil), buf.Bytes()[seg.offset:]...)
+		buf.Truncate(seg.offset)
+		buf.WriteString(ops)
+		buf.WriteString("\n")
+		buf.Write(tail)
+	}
+}
+
+// decorOps returns the content stream operators that paint the background
+// and borders of a box with the specified extent. The top and bottom borders
+// are omitted if top and bottom are false respectively.
+func (html *HTMLType) decorOps(st *htmlStyleType, x, y, w, h, pageHt float64, top, bottom bool) string {
+	k := html.pdf.k
+	var s fmtBuffer
+	s.printf("q")
+	if bg := st.background; bg.set {
+		s.printf(" %.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f", float64(bg.r)/255,
+			float64(bg.g)/255, float64(bg.b)/255, x*k, (pageHt-y)*k, w*k, -h*k)
+	}
+	for j, b := range st.border {
+		if b.width <= 0 || j == 0 && !top || j == 2 && !bottom {
+			continue
+		}
+		half := b.width / 2
+		var x1, y1, x2, y2 float64
+		switch j {
+		case 0:
+			x1, y1, x2, y2 = x, y+half, x+w, y+half
+		case 1:
+			x1, y1, x2, y2 = x+w-half, y, x+w-half, y+h
+		case 2:
+			x1, y1, x2, y2 = x, y+h-half, x+w, y+h-half
+		case 3:
+			x1, y1, x2, y2 = x+half, y, x+half, y+h
+		}
+		dash := "[] 0 d"
+		switch b.style {
+		case "dashed":
+			dash = sprintf("[%.2f %.2f] 0 d", 3*b.width*k, 2*b.width*k)
+		case "dotted":
+			dash = sprintf("[%.2f %.2f] 0 d", b.width*k, b.width*k)
+		}
+		clrStr := sprintf("%.3f %.3f %.3f RG",
+			float64(b.color.r)/255, float64(b.color.g)/255, float64(b.color.b)/255)
+		if b.color.clr.str != "" {
+			clrStr = strokeClr(b.color.clr).str
+		}
+		s.printf(" %s %.2f w 0 J %s %.2f %.2f m %.2f %.2f l S",
+			clrStr, b.width*k, dash, x1*k, (pageHt-y1)*k, x2*k, (pageHt-y2)*k)
+	}
+	s.printf(" Q")
+	return s.String()
+}
+
+// anchor makes the current position the destination of links to the
+// fragment identifier name
+func (html *HTMLType) anchor(name string) {
+	html.pdf.SetLink(html.linkID(name), html.pdf.y, -1)
+}
+
+// putAnchors makes the current position the destination of the fragment
+// identifiers of elements that begin before any of their content is placed
+func (html *HTMLType) putAnchors() {
+	for _, name := range html.anchors {
+		html.anchor(name)
+	}
+	html.anchors = html.anchors[:0]
+}
+
+// linkID returns the internal link for the fragment identifier name. Until
+// the destination is found, the link points to the position at which it is
+// first referenced.
+func (html *HTMLType) linkID(name string) int {
+	f := html.pdf
+	id, ok := html.links[name]
+	if !ok {
+		id = f.AddLink()
+		html.links[name] = id
+	}
+	if f.links[id].page == 0 {
+		f.SetLink(id, -1, -1)
+	}
+	return id
+}
+
+// link returns the arguments of CellFormat() for a hyperlink to href
+func (html *HTMLType) link(href string) (linkID int, linkStr string) {
+	if strings.HasPrefix(href, "#") {
+		return html.linkID(href[1:]), ""
+	}
+	return 0, href
+}
+
+// fontStyle returns the style of the font used for st, omitting styles for
+// which the font family has no variant
+func (html *HTMLType) fontStyle(st *htmlStyleType) string {
+	return html.pdf.cssFontStyle(st.fontFamily, st.bold, st.italic)
+}
+
+// setFont selects the font of style st
+func (html *HTMLType) setFont(st *htmlStyleType) {
+	styleStr := html.fontStyle(st)
+	if st.underline {
+		styleStr += "U"
+	}
+	html.pdf.SetFont(st.fontFamily, styleStr, st.fontSize)
+}
+
+// stringWidth returns the width of str in the font of style st
+func (html *HTMLType) stringWidth(st *htmlStyleType, str string) float64 {
+	f := html.pdf
+	key := st.fontFamily + html.fontStyle(st)
+	font, ok := f.fonts[key]
+	if !ok {
+		html.setFont(st)
+		if font, ok = f.fonts[key]; !ok {
+			return 0
+		}
+	}
+	w := 0
+	for _, ch := range []byte(str) {
+		w += font.Cw[ch]
+	}
+	return float64(w) * st.fontSize / f.k / 1000
+}
+
+// htmlMax returns the larger of a and b
+func htmlMax(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// htmlParse returns the document tree of the HTML split by t. End tags that
+// are omitted are implied as they are by web browsers, for example when a
+// list item begins while another is open.
+func htmlParse(t *HTMLTokenizerType) *htmlNodeType {
+	root := &htmlNodeType{tag: "#root"}
+	stack := []*htmlNodeType{root}
+	// find returns the position in the stack of the innermost element named
+	// in tags, searching no further than an element named in stops
+	find := func(tags, stops string) int {
+		for j := len(stack) - 1; j > 0; j-- {
+			tag := " " + stack[j].tag + " "
+			if strings.Contains(tags, tag) {
+				return j
+			}
+			if strings.Contains(stops, tag) {
+				break
+			}
+		}
+		return -1
+	}
+	closeTo := func(j int) {
+		if j > 0 {
+			stack = stack[:j]
+		}
+	}
+	for seg, ok := t.Next(); ok; seg, ok = t.Next() {
+		top := stack[len(stack)-1]
+		switch seg.Cat {
+		case 'T':
+			if n := len(top.children); n > 0 && top.children[n-1].tag == "" {
+				top.children[n-1].text += seg.Str
+			} else {
+				top.children = append(top.children, &htmlNodeType{text: seg.Str, parent: top})
+			}
+		case 'O':
+			if htmlBlockTags[seg.Str] {
+				// A block closes an open paragraph
+				for j := len(stack) - 1; j > 0; j-- {
+					if stack[j].tag == "p" {
+						closeTo(j)
+						break
+					}
+					if htmlDisplay(stack[j].tag) != "inline" {
+						break
+					}
+				}
+			}
+			switch seg.Str {
+			case "li":
+				closeTo(find(" li ", " ul ol menu dir table "))
+			case "dt", "dd":
+				closeTo(find(" dt dd ", " dl table "))
+			case "tr":
+				closeTo(find(" tr ", " table thead tbody tfoot "))
+			case "td", "th":
+				closeTo(find(" td th ", " tr table "))
+			case "thead", "tbody", "tfoot":
+				closeTo(find(" thead tbody tfoot ", " table "))
+			}
+			top = stack[len(stack)-1]
+			n := &htmlNodeType{tag: seg.Str, attr: seg.Attr, parent: top}
+			top.children = append(top.children, n)
+			if !htmlVoidTags[seg.Str] {
+				stack = append(stack, n)
+			}
+		case 'C':
+			closeTo(find(" "+seg.Str+" ", ""))
+		}
+	}
+	return root
+}
@@ -17,7 +17,6 @@
 package gofpdf
 
 import (
-	"regexp"
 	"strings"
 )
 
@@ -29,79 +28,51 @@ type HTMLBasicSegmentType struct {
 	Attr map[string]string // Attribute keys are lower case
 }
 
-// HTMLBasicTokenize returns a list of HTML tags and literal elements. This is
-// done with regular expressions, so the result is only marginally better than
-// useless.
+// HTMLBasicTokenize returns a list of HTML tags and literal elements. The
+// HTML is split with HTMLTokenizerType, so attribute values may be quoted and
+// contain spaces, comments are skipped and character references are decoded.
+// References to characters other than ASCII are converted to cp1252, the
+// encoding of the core fonts; use HTMLTokenizerNew() to convert them to the
+// encoding of the current font instead. Line feeds in text and attribute
+// values are replaced with spaces and carriage returns are removed.
 func HTMLBasicTokenize(htmlStr string) (list []HTMLBasicSegmentType) {
-	// This routine is adapted from http://www.fpdf.org/
+	return htmlBasicList(&HTMLTokenizerType{src: htmlStr, trNew: func() func(string) string {
+		return htmlTranslator("", "")
+	}})
+}
+
+// htmlBasicList returns the segments of t with line breaks replaced as
+// described for HTMLBasicTokenize()
+func htmlBasicList(t *HTMLTokenizerType) (list []HTMLBasicSegmentType) {
 	list = make([]HTMLBasicSegmentType, 0, 16)
-	htmlStr = strings.Replace(htmlStr, "\n", " ", -1)
-	htmlStr = strings.Replace(htmlStr, "\r", "", -1)
-	tagRe, _ := regexp.Compile(`(?U)<.*>`)
-	attrRe, _ := regexp.Compile(`([^=]+)=["']?([^"']+)`)
-	capList := tagRe.FindAllStringIndex(htmlStr, -1)
-	if capList != nil {
-		var seg HTMLBasicSegmentType
-		var parts []string
-		pos := 0
-		for _, cap := range capList {
-			if pos < cap[0] {
-				seg.Cat = 'T'
-				seg.Str = htmlStr[pos:cap[0]]
-				seg.Attr = nil
-				list = append(list, seg)
-			}
-			if htmlStr[cap[0]+1] == '/' {
-				seg.Cat = 'C'
-				seg.Str = strings.ToLower(htmlStr[cap[0]+2 : cap[1]-1])
-				seg.Attr = nil
-				list = append(list, seg)
-			} else {
-				// Extract attributes
-				parts = strings.Split(htmlStr[cap[0]+1:cap[1]-1], " ")
-				if len(parts) > 0 {
-					for j, part := range parts {
-						if j == 0 {
-							seg.Cat = 'O'
-							seg.Str = strings.ToLower(parts[0])
-							seg.Attr = make(map[string]string)
-						} else {
-							attrList := attrRe.FindAllStringSubmatch(part, -1)
-							if attrList != nil {
-								for _, attr := range attrList {
-									seg.Attr[strings.ToLower(attr[1])] = attr[2]
-								}
-							}
-						}
-					}
-					list = append(list, seg)
-				}
-			}
-			pos = cap[1]
+	rep := strings.NewReplacer("\n", " ", "\r", "")
+	for seg, ok := t.Next(); ok; seg, ok = t.Next() {
+		if seg.Cat == 'T' {
+			seg.Str = rep.Replace(seg.Str)
 		}
-		if len(htmlStr) > pos {
-			seg.Cat = 'T'
-			seg.Str = htmlStr[pos:]
-			seg.Attr = nil
-			list = append(list, seg)
+		for key, val := range seg.Attr {
+			seg.Attr[key] = rep.Replace(val)
 		}
-	} else {
-		list = append(list, HTMLBasicSegmentType{Cat: 'T', Str: htmlStr, Attr: nil})
+		list = append(list, seg)
 	}
 	return
 }
 
 // HTMLBasicType is used for rendering a very basic subset of HTML. It supports
-// only hyperlinks and bold, italic and underscore attributes. In the Link
-// structure, the ClrR, ClrG and ClrB fields (0 through 255) define the color
-// of hyperlinks. The Bold, Italic and Underscore values define the hyperlink
-// style.
+// only hyperlinks, bold, italic and underscore attributes, and bulleted and
+// numbered lists. In the Link structure, the ClrR, ClrG and ClrB fields (0
+// through 255) define the color of hyperlinks. The Bold, Italic and
+// Underscore values define the hyperlink style. The List field controls the
+// indentation and labels of lists; see ListType for details. Unordered lists
+// use the Bullet field of each level and ordered lists use the Numbering field
+// if it is not empty, otherwise "1.", "a." and "i." by nesting level.
 type HTMLBasicType struct {
 	pdf  *Fpdf
 	Link struct {
 		ClrR, ClrG, ClrB         int
 		Bold, Italic, Underscore bool
 	}
+	List ListType
 }
 
 // HTMLBasicNew returns an instance that facilitates writing basic HTML in the
@@ -110,6 +81,7 @@ func (f *Fpdf) HTMLBasicNew() (html HTMLBasicType) {
 	html.pdf = f
 	html.Link.ClrR, html.Link.ClrG, html.Link.ClrB = 0, 0, 128
 	html.Link.Bold, html.Link.Italic, html.Link.Underscore = false, false, true
+	html.List = f.ListNew()
 	return
 }
 
@@ -117,14 +89,16 @@ func (f *Fpdf) HTMLBasicNew() (html HTMLBasicType) {
 // font. See HTMLBasicNew() to create a receiver that is associated with the
 // PDF document instance. The text can be encoded with a basic subset of HTML
 // that includes hyperlinks and tags for italic (I), bold (B), underscore
-// (U) and center (CENTER) attributes. When the right margin is reached a line
-// break occurs and text continues from the left margin. Upon method exit, the
-// current position is left at the end of the text.
+// (U) and center (CENTER) attributes, and unordered (UL) and ordered (OL)
+// lists of items (LI). Lists may be nested. When the right margin is reached a
+// line break occurs and text continues from the left margin, or, within a list
+// item, from the hanging indent of the item. Upon method exit, the current
+// position is left at the end of the text.
 //
 // lineHt indicates the line height in the unit of measure specified in New().
 func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
 	var boldLvl, italicLvl, underscoreLvl, linkBold, linkItalic, linkUnderscore int
-	var textR, textG, textB = html.pdf.GetTextColor()
+	text := html.pdf.color.text
 	var hrefStr string
 	if html.Link.Bold {
 		linkBold = 1
@@ -157,14 +131,81 @@ func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
 		setStyle(linkBold, linkItalic, linkUnderscore)
 		html.pdf.WriteLinkString(lineHt, txtStr, urlStr)
 		setStyle(-linkBold, -linkItalic, -linkUnderscore)
-		html.pdf.SetTextColor(textR, textG, textB)
+		html.pdf.setTextClr(text)
+	}
+	type listType struct {
+		ordered bool
+		count   int
+	}
+	var lists []listType
+	var itemStart bool
+	marginLeft := html.pdf.lMargin
+	lineBreak := func() {
+		if html.pdf.x > html.pdf.lMargin {
+			html.pdf.Ln(lineHt)
+		}
+	}
+	// Set the left margin for text within the innermost open list; this is
+	// called at the start of a line
+	listMargin := func() {
+		if depth := len(lists); depth > 0 {
+			html.pdf.SetLeftMargin(marginLeft + html.List.Indent*float64(depth-1) + html.List.LabelWd)
+		} else {
+			html.pdf.SetLeftMargin(marginLeft)
+		}
+		html.pdf.x = html.pdf.lMargin
 	}
-	list := HTMLBasicTokenize(htmlStr)
+	putItem := func() {
+		lineBreak()
+		depth := len(lists) - 1
+		var ordered bool
+		var count int
+		if depth < 0 {
+			depth = 0
+		} else {
+			lists[depth].count++
+			ordered, count = lists[depth].ordered, lists[depth].count
+		}
+		lvl := html.List.level(depth)
+		var labelStr string
+		if ordered {
+			if lvl.Numbering == "" {
+				var olDepth int
+				for _, l := range lists[:depth] {
+					if l.ordered {
+						olDepth++
+					}
+				}
+				lvl.Numbering = []string{"1.", "a.", "i."}[olDepth%3]
+			}
+			labelStr = listNumber(lvl.Numbering, count)
+		} else {
+			labelStr = lvl.Bullet
+			if labelStr == "" {
+				labelStr = "\x95"
+			}
+		}
+		html.pdf.x = marginLeft + html.List.Indent*float64(depth)
+		html.List.label(lineHt, lvl, labelStr)
+		html.pdf.SetLeftMargin(html.pdf.x)
+		itemStart = true
+	}
+	list := htmlBasicList(html.pdf.HTMLTokenizerNew(htmlStr))
 	var ok bool
 	alignStr := "L"
 	for _, el := range list {
 		switch el.Cat {
 		case 'T':
+			if len(lists) > 0 {
+				// Whitespace between list tags is not rendered
+				if strings.TrimSpace(el.Str) == "" {
+					continue
+				}
+				if itemStart {
+					el.Str = strings.TrimLeft(el.Str, " ")
+				}
+			}
+			itemStart = false
 			if len(hrefStr) > 0 {
 				putLink(hrefStr, el.Str)
 				hrefStr = ""
@@ -188,6 +229,11 @@ func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
 			case "center":
 				html.pdf.Ln(lineHt)
 				alignStr = "C"
+			case "ul", "ol":
+				lineBreak()
+				lists = append(lists, listType{ordered: el.Str == "ol"})
+			case "li":
+				putItem()
 			case "a":
 				hrefStr, ok = el.Attr["href"]
 				if !ok {
@@ -205,7 +251,18 @@ func (html *HTMLBasicType) Write(lineHt float64, htmlStr string) {
 			case "center":
 				html.pdf.Ln(lineHt)
 				alignStr = "L"
+			case "li":
+				lineBreak()
+			case "ul", "ol":
+				lineBreak()
+				if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+				}
+				listMargin()
 			}
 		}
 	}
+	if len(lists) > 0 {
+		html.pdf.SetLeftMargin(marginLeft)
+	}
 }
@@ -0,0 +1,796 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// cssDeclType is a property declaration such as "color: red". Shorthand
+// properties are expanded into their constituent properties when parsed.
+type cssDeclType struct {
+	prop, value string
+}
+
+// cssCompoundType is a selector for a single element, such as "p",
+// "div.note" or "#main". combinator is the relationship to the element
+// matched by the preceding compound selector: ' ' for a descendant or '>'
+// for a child.
+type cssCompoundType struct {
+	tag        string
+	id         string
+	classes    []string
+	combinator byte
+}
+
+// cssRuleType is a style rule with a single selector
+type cssRuleType struct {
+	selector []cssCompoundType
+	spec     int // specificity
+	order    int // position in the style sheets
+	decls    []cssDeclType
+}
+
+// cssParse returns the rules of the specified style sheet. Rules are numbered
+// consecutively beginning with *order, which is advanced past them. At-rules
+// such as @media are skipped.
+func cssParse(cssStr string, order *int) (rules []cssRuleType) {
+	cssStr = cssStripComments(cssStr)
+	for len(cssStr) > 0 {
+		cssStr = strings.TrimSpace(cssStr)
+		if strings.HasPrefix(cssStr, "@") {
+			cssStr = cssSkipAtRule(cssStr)
+			continue
+		}
+		open := strings.Index(cssStr, "{")
+		if open < 0 {
+			break
+		}
+		end := strings.Index(cssStr[open:], "}")
+		if end < 0 {
+			end = len(cssStr)
+		} else {
+			end += open
+		}
+		decls := cssParseDecls(cssStr[open+1 : end])
+		for _, selStr := range strings.Split(cssStr[:open], ",") {
+			if sel, spec, ok := cssParseSelector(selStr); ok {
+				rules = append(rules, cssRuleType{selector: sel, spec: spec, order: *order, decls: decls})
+				*order++
+			}
+		}
+		if end < len(cssStr) {
+			end++
+		}
+		cssStr = cssStr[end:]
+	}
+	return
+}
+
+// cssStripComments removes comments from a style sheet
+func cssStripComments(cssStr string) string {
+	for {
+		pos := strings.Index(cssStr, "/*")
+		if pos < 0 {
+			return cssStr
+		}
+		end := strings.Index(cssStr[pos+2:], "*/")
+		if end < 0 {
+			return cssStr[:pos]
+		}
+		cssStr = cssStr[:pos] + " " + cssStr[pos+2+end+2:]
+	}
+}
+
+// cssSkipAtRule returns the portion of cssStr that follows the at-rule with
+// which it begins
+func cssSkipAtRule(cssStr string) string {
+	depth := 0
+	for j := 0; j < len(cssStr); j++ {
+		switch cssStr[j] {
+		case ';':
+			if depth == 0 {
+				return cssStr[j+1:]
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth <= 0 {
+				return cssStr[j+1:]
+			}
+		}
+	}
+	return ""
+}
+
+// cssParseSelector parses a selector made up of compound selectors separated
+// by descendant or child combinators. ok is false if the selector uses
+// features that are not supported, such as attribute selectors and
+// pseudo-classes.
+func cssParseSelector(selStr string) (sel []cssCompoundType, spec int, ok bool) {
+	selStr = strings.Replace(selStr, ">", " > ", -1)
+	combinator := byte(' ')
+	for _, part := range strings.Fields(selStr) {
+		if part == ">" {
+			combinator = '>'
+			continue
+		}
+		if strings.ContainsAny(part, "[:+~") {
+			return nil, 0, false
+		}
+		var c cssCompoundType
+		c.combinator = combinator
+		combinator = ' '
+		for len(part) > 0 {
+			end := strings.IndexAny(part[1:], ".#") + 1
+			if end == 0 {
+				end = len(part)
+			}
+			switch name := part[:end]; name[0] {
+			case '#':
+				c.id = name[1:]
+				spec += 10000
+			case '.':
+				c.classes = append(c.classes, name[1:])
+				spec += 100
+			default:
+				c.tag = strings.ToLower(name)
+				if c.tag != "*" {
+					spec++
+				}
+			}
+			part = part[end:]
+		}
+		sel = append(sel, c)
+	}
+	return sel, spec, len(sel) > 0
+}
+
+// cssParseDecls parses a semicolon-separated list of declarations, such as
+// the content of a style attribute
+func cssParseDecls(declStr string) (decls []cssDeclType) {
+	for _, str := range strings.Split(declStr, ";") {
+		pos := strings.Index(str, ":")
+		if pos < 0 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(str[:pos]))
+		value := strings.TrimSpace(str[pos+1:])
+		value = strings.TrimSpace(strings.TrimSuffix(value, "!important"))
+		if prop != "" && value != "" {
+			decls = append(decls, cssExpand(prop, value)...)
+		}
+	}
+	return
+}
+
+var cssSides = []string{"top", "right", "bottom", "left"}
+
+// cssExpand returns the declarations that correspond to the specified
+// property, expanding shorthand properties
+func cssExpand(prop, value string) (decls []cssDeclType) {
+	add := func(prop, value string) {
+		decls = append(decls, cssDeclType{prop, value})
+	}
+	// sides assigns one to four values to the four sides in the usual order
+	sides := func(format, value string) {
+		vals := cssFields(value)
+		switch len(vals) {
+		case 1:
+			vals = append(vals, vals[0], vals[0], vals[0])
+		case 2:
+			vals = append(vals, vals[0], vals[1])
+		case 3:
+			vals = append(vals, vals[1])
+		}
+		if len(vals) == 4 {
+			for j, side := range cssSides {
+				add(strings.Replace(format, "*", side, 1), vals[j])
+			}
+		}
+	}
+	switch prop {
+	case "margin", "padding":
+		sides(prop+"-*", value)
+	case "border-width", "border-style", "border-color":
+		sides("border-*"+prop[6:], value)
+	case "border", "border-top", "border-right", "border-bottom", "border-left":
+		width, style, color := "medium", "none", "currentcolor"
+		for _, str := range cssFields(value) {
+			switch {
+			case cssBorderStyle(str):
+				style = str
+			case str == "thin" || str == "medium" || str == "thick" || str[0] == '.' || str[0] >= '0' && str[0] <= '9':
+				width = str
+			default:
+				color = str
+			}
+		}
+		sideList := cssSides
+		if prop != "border" {
+			sideList = []string{prop[7:]}
+		}
+		for _, side := range sideList {
+			add("border-"+side+"-width", width)
+			add("border-"+side+"-style", style)
+			add("border-"+side+"-color", color)
+		}
+	case "background":
+		for _, str := range cssFields(value) {
+			if _, _, _, ok := cssColor(str); ok || str == "transparent" {
+				add("background-color", str)
+			}
+		}
+	case "list-style":
+		for _, str := range cssFields(value) {
+			if !strings.HasPrefix(str, "url(") && str != "inside" && str != "outside" {
+				add("list-style-type", str)
+			}
+		}
+	default:
+		add(prop, value)
+	}
+	return
+}
+
+// cssFields splits value at white space that is not enclosed in parentheses
+// or quotes
+func cssFields(value string) (list []string) {
+	depth := 0
+	var quote rune
+	start := -1
+	for j, r := range value {
+		space := false
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			space = depth == 0
+		}
+		if space {
+			if start >= 0 {
+				list = append(list, value[start:j])
+				start = -1
+			}
+		} else if start < 0 {
+			start = j
+		}
+	}
+	if start >= 0 {
+		list = append(list, value[start:])
+	}
+	return
+}
+
+// cssBorderStyle returns true if str is a border style keyword
+func cssBorderStyle(str string) bool {
+	switch str {
+	case "none", "hidden", "solid", "dashed", "dotted", "double", "groove", "ridge", "inset", "outset":
+		return true
+	}
+	return false
+}
+
+var cssNamedColors = map[string]int{
+	"black": 0x000000, "silver": 0xc0c0c0, "gray": 0x808080, "grey": 0x808080,
+	"white": 0xffffff, "maroon": 0x800000, "red": 0xff0000, "purple": 0x800080,
+	"fuchsia": 0xff00ff, "magenta": 0xff00ff, "green": 0x008000, "lime": 0x00ff00,
+	"olive": 0x808000, "yellow": 0xffff00, "navy": 0x000080, "blue": 0x0000ff,
+	"teal": 0x008080, "aqua": 0x00ffff, "cyan": 0x00ffff, "orange": 0xffa500,
+	"brown": 0xa52a2a, "pink": 0xffc0cb, "gold": 0xffd700, "darkgray": 0xa9a9a9,
+	"darkgrey": 0xa9a9a9, "lightgray": 0xd3d3d3, "lightgrey": 0xd3d3d3,
+	"darkblue": 0x00008b, "darkred": 0x8b0000, "darkgreen": 0x006400,
+	"lightblue": 0xadd8e6, "lightyellow": 0xffffe0, "whitesmoke": 0xf5f5f5,
+}
+
+// cssColor parses a color specified by name or in one of the forms #rgb,
+// #rrggbb and rgb(r, g, b)
+func cssColor(str string) (r, g, b int, ok bool) {
+	str = strings.ToLower(strings.TrimSpace(str))
+	if v, found := cssNamedColors[str]; found {
+		return v >> 16, v >> 8 & 0xff, v & 0xff, true
+	}
+	switch {
+	case strings.HasPrefix(str, "#"):
+		hexStr := str[1:]
+		if len(hexStr) == 3 {
+			hexStr = string([]byte{hexStr[0], hexStr[0], hexStr[1], hexStr[1], hexStr[2], hexStr[2]})
+		}
+		if len(hexStr) != 6 {
+			return
+		}
+		v, err := strconv.ParseUint(hexStr, 16, 32)
+		if err != nil {
+			return
+		}
+		return int(v >> 16), int(v >> 8 & 0xff), int(v & 0xff), true
+	case strings.HasPrefix(str, "rgb(") && strings.HasSuffix(str, ")"):
+		parts := strings.Split(str[4:len(str)-1], ",")
+		if len(parts) != 3 {
+			return
+		}
+		var comp [3]int
+		for j, part := range parts {
+			part = strings.TrimSpace(part)
+			var v float64
+			var err error
+			if strings.HasSuffix(part, "%") {
+				v, err = strconv.ParseFloat(part[:len(part)-1], 64)
+				v = v * 255 / 100
+			} else {
+				v, err = strconv.ParseFloat(part, 64)
+			}
+			if err != nil {
+				return
+			}
+			if v < 0 {
+				v = 0
+			} else if v > 255 {
+				v = 255
+			}
+			comp[j] = int(v + 0.5)
+		}
+		return comp[0], comp[1], comp[2], true
+	}
+	return
+}
+
+// cssLength converts a length to points. emPt is the font size in points
+// that em and ex units are relative to, and pctPt is the length in points
+// that percentages are relative to. A number without a unit is taken to be in
+// pixels, as in HTML attributes.
+func cssLength(str string, emPt, pctPt float64) (v float64, ok bool) {
+	str = strings.ToLower(strings.TrimSpace(str))
+	end := len(str)
+	for end > 0 && (str[end-1] < '0' || str[end-1] > '9') && str[end-1] != '.' {
+		end--
+	}
+	v, err := strconv.ParseFloat(str[:end], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch str[end:] {
+	case "", "px":
+		v *= 0.75
+	case "pt":
+	case "pc":
+		v *= 12
+	case "in":
+		v *= 72
+	case "cm":
+		v *= 72 / 2.54
+	case "mm":
+		v *= 72 / 25.4
+	case "em":
+		v *= emPt
+	case "ex":
+		v *= emPt / 2
+	case "%":
+		v *= pctPt / 100
+	default:
+		return 0, false
+	}
+	return v, true
+}
+
+// cssMatch returns true if the selector sel matches element n
+func cssMatch(sel []cssCompoundType, n *htmlNodeType) bool {
+	j := len(sel) - 1
+	if !sel[j].matches(n) {
+		return false
+	}
+	for ; j > 0; j-- {
+		if sel[j].combinator == '>' {
+			n = n.parent
+			if n == nil || !sel[j-1].matches(n) {
+				return false
+			}
+			continue
+		}
+		for n = n.parent; n != nil && !sel[j-1].matches(n); n = n.parent {
+		}
+		if n == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns true if the compound selector c matches element n
+func (c *cssCompoundType) matches(n *htmlNodeType) bool {
+	if n.tag == "" || n.tag[0] == '#' {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && c.tag != n.tag {
+		return false
+	}
+	if c.id != "" && c.id != n.attr["id"] {
+		return false
+	}
+	classes := strings.Fields(n.attr["class"])
+	for _, cls := range c.classes {
+		found := false
+		for _, str := range classes {
+			if str == cls {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// declarations returns the values of the properties that apply to element n
+// after the cascade
+func (html *HTMLType) declarations(n *htmlNodeType) map[string]string {
+	type matchType struct {
+		level, spec, order int
+		decls              []cssDeclType
+	}
+	var list []matchType
+	for _, r := range html.defRules {
+		if cssMatch(r.selector, n) {
+			list = append(list, matchType{0, r.spec, r.order, r.decls})
+		}
+	}
+	if n.tag == "a" && n.attr["href"] != "" {
+		list = append(list, matchType{0, 1 << 20, 0, []cssDeclType{
+			{"color", "#000080"}, {"text-decoration", "underline"}}})
+	}
+	if hints := htmlHints(n); len(hints) > 0 {
+		list = append(list, matchType{1, 0, 0, hints})
+	}
+	for _, r := range html.rules {
+		if cssMatch(r.selector, n) {
+			list = append(list, matchType{2, r.spec, r.order, r.decls})
+		}
+	}
+	if styleStr := n.attr["style"]; styleStr != "" {
+		list = append(list, matchType{3, 0, 0, cssParseDecls(styleStr)})
+	}
+	gensort(len(list), func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.level != b.level {
+			return a.level < b.level
+		}
+		if a.spec != b.spec {
+			return a.spec < b.spec
+		}
+		return a.order < b.order
+	}, func(i, j int) {
+		list[i], list[j] = list[j], list[i]
+	})
+	values := make(map[string]string)
+	for _, m := range list {
+		for _, d := range m.decls {
+			values[d.prop] = strings.ToLower(d.value)
+			if d.prop == "font-family" {
+				values[d.prop] = d.value
+			}
+		}
+	}
+	return values
+}
+
+// htmlHints returns the declarations that correspond to the presentational
+// attributes of element n, such as align and bgcolor
+func htmlHints(n *htmlNodeType) (decls []cssDeclType) {
+	add := func(prop, attrStr string) {
+		if v, ok := n.attr[attrStr]; ok && v != "" {
+			decls = append(decls, cssExpand(prop, v)...)
+		}
+	}
+	switch n.tag {
+	case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "td", "th", "tr", "caption":
+		add("text-align", "align")
+	}
+	switch n.tag {
+	case "table", "tr", "td", "th", "body":
+		add("background-color", "bgcolor")
+	}
+	switch n.tag {
+	case "img", "table", "td", "th":
+		add("width", "width")
+		add("height", "height")
+	case "font":
+		add("color", "color")
+		add("font-family", "face")
+	}
+	switch n.tag {
+	case "td", "th", "tr":
+		add("vertical-align", "valign")
+	case "table":
+		if n.attr["align"] == "center" {
+			decls = append(decls, cssExpand("margin-left", "auto")...)
+			decls = append(decls, cssExpand("margin-right", "auto")...)
+		}
+	}
+	if n.tag == "td" || n.tag == "th" {
+		// Cell borders and padding specified by the enclosing table
+		p := n.parent
+		for p != nil && p.tag != "table" {
+			p = p.parent
+		}
+		if p != nil {
+			if v, err := strconv.Atoi(p.attr["border"]); err == nil && v > 0 {
+				decls = append(decls, cssExpand("border", "1px solid")...)
+			}
+			if v, err := strconv.Atoi(p.attr["cellpadding"]); err == nil && v >= 0 {
+				decls = append(decls, cssExpand("padding", strconv.Itoa(v)+"px")...)
+			}
+		}
+	}
+	return
+}
+
+// htmlDisplay returns the default display of elements with the specified
+// name
+func htmlDisplay(tag string) string {
+	switch tag {
+	case "li":
+		return "list-item"
+	case "head", "script", "style", "title", "meta", "link":
+		return "none"
+	}
+	if htmlBlockTags[tag] {
+		return "block"
+	}
+	return "inline"
+}
+
+// cssProps lists the supported properties in the order in which they are
+// applied
+var cssProps = []string{
+	"color", "font-family", "font-weight", "font-style", "text-decoration",
+	"text-align", "line-height", "white-space", "list-style-type",
+	"vertical-align", "display", "background-color", "width", "height",
+	"margin-top", "margin-right", "margin-bottom", "margin-left",
+	"padding-top", "padding-right", "padding-bottom", "padding-left",
+	"border-top-width", "border-right-width", "border-bottom-width", "border-left-width",
+	"border-top-style", "border-right-style", "border-bottom-style", "border-left-style",
+	"border-top-color", "border-right-color", "border-bottom-color", "border-left-color",
+}
+
+// computeStyle returns the style of element n. parent is the style of its
+// parent and wd is the width of its containing block.
+func (html *HTMLType) computeStyle(n *htmlNodeType, parent *htmlStyleType, wd float64) (st htmlStyleType) {
+	f := html.pdf
+	// Inherited properties are retained
+	st = *parent
+	st.display = htmlDisplay(n.tag)
+	st.background = htmlColorType{}
+	st.verticalAlign = ""
+	st.margin, st.padding = [4]float64{}, [4]float64{}
+	st.autoMargin = [4]bool{}
+	st.width, st.height = 0, 0
+	values := html.declarations(n)
+	if v, ok := values["font-size"]; ok {
+		st.fontSize = html.fontSize(v, parent.fontSize)
+	}
+	length := func(v string, pct float64) (float64, bool) {
+		pt, ok := cssLength(v, st.fontSize, pct*f.k)
+		return pt / f.k, ok
+	}
+	for j := range st.border {
+		st.border[j] = htmlBorderType{width: 2.25 / f.k, style: "none"}
+	}
+	borderClr := [4]string{}
+	for _, prop := range cssProps {
+		v, ok := values[prop]
+		if !ok || v == "inherit" {
+			continue
+		}
+		switch prop {
+		case "color":
+			if r, g, b, ok := cssColor(v); ok {
+				st.color = htmlColorType{r: r, g: g, b: b, set: true}
+			}
+		case "font-family":
+			st.fontFamily = html.fontFamily(v, st.fontFamily)
+		case "font-weight":
+			switch v {
+			case "bold", "bolder", "600", "700", "800", "900":
+				st.bold = true
+			case "normal", "lighter", "100", "200", "300", "400", "500":
+				st.bold = false
+			}
+		case "font-style":
+			st.italic = v == "italic" || v == "oblique"
+		case "text-decoration":
+			st.underline = strings.Contains(v, "underline")
+		case "text-align":
+			switch v {
+			case "left", "start":
+				st.textAlign = "L"
+			case "right", "end":
+				st.textAlign = "R"
+			case "center":
+				st.textAlign = "C"
+			case "justify":
+				st.textAlign = "J"
+			}
+		case "line-height":
+			if v == "normal" {
+				st.lineHeight = 1.2
+			} else if x, err := strconv.ParseFloat(v, 64); err == nil {
+				st.lineHeight = x
+			} else if pt, ok := cssLength(v, st.fontSize, st.fontSize); ok && st.fontSize > 0 {
+				st.lineHeight = pt / st.fontSize
+			}
+		case "white-space":
+			st.whiteSpace = v
+		case "list-style-type":
+			st.listStyle = v
+		case "vertical-align":
+			st.verticalAlign = v
+		case "display":
+			switch v {
+			case "none", "block", "inline", "list-item":
+				st.display = v
+			case "inline-block":
+				st.display = "inline"
+			}
+		case "background-color":
+			if r, g, b, ok := cssColor(v); ok {
+				st.background = htmlColorType{r: r, g: g, b: b, set: true}
+			}
+		case "width":
+			if x, ok := length(v, wd); ok {
+				st.width = x
+			}
+		case "height":
+			if x, ok := length(v, 0); ok {
+				st.height = x
+			}
+		default:
+			var side int
+			for j, str := range cssSides {
+				if strings.Contains(prop, "-"+str) {
+					side = j
+				}
+			}
+			switch {
+			case strings.HasPrefix(prop, "margin"):
+				if v == "auto" {
+					st.autoMargin[side] = true
+				} else if x, ok := length(v, wd); ok {
+					st.margin[side] = x
+				}
+			case strings.HasPrefix(prop, "padding"):
+				if x, ok := length(v, wd); ok && x >= 0 {
+					st.padding[side] = x
+				}
+			case strings.HasSuffix(prop, "-width"):
+				switch v {
+				case "thin":
+					st.border[side].width = 0.75 / f.k
+				case "medium":
+					st.border[side].width = 2.25 / f.k
+				case "thick":
+					st.border[side].width = 3.75 / f.k
+				default:
+					if x, ok := length(v, 0); ok && x >= 0 {
+						st.border[side].width = x
+					}
+				}
+			case strings.HasSuffix(prop, "-style"):
+				st.border[side].style = v
+			case strings.HasSuffix(prop, "-color"):
+				borderClr[side] = v
+			}
+		}
+	}
+	for j := range st.border {
+		b := &st.border[j]
+		b.color = st.color
+		if r, g, bl, ok := cssColor(borderClr[j]); ok {
+			b.color = htmlColorType{r: r, g: g, b: bl, set: true}
+		}
+		if b.style == "none" || b.style == "hidden" {
+			b.width = 0
+		}
+	}
+	return
+}
+
+// fontSize returns the font size in points specified by v for an element
+// whose parent has a font size of parentPt points
+func (html *HTMLType) fontSize(v string, parentPt float64) float64 {
+	scale := map[string]float64{"xx-small": 0.6, "x-small": 0.75, "small": 0.89,
+		"medium": 1, "large": 1.2, "x-large": 1.5, "xx-large": 2}
+	if s, ok := scale[v]; ok {
+		return html.baseSize * s
+	}
+	switch v {
+	case "smaller":
+		return parentPt / 1.2
+	case "larger":
+		return parentPt * 1.2
+	}
+	if pt, ok := cssLength(v, parentPt, parentPt); ok && pt > 0 {
+		return pt
+	}
+	return parentPt
+}
+
+// fontFamily returns the first font family in the comma-separated list v
+// that is available, or current if none is
+func (html *HTMLType) fontFamily(v, current string) string {
+	return html.pdf.cssFontFamily(v, current)
+}
+
+// cssFontFamily returns the first font family in the comma-separated list v
+// that is available, or current if none is. Generic and common family names
+// are mapped to the core fonts.
+func (f *Fpdf) cssFontFamily(v, current string) string {
+	for _, name := range strings.Split(v, ",") {
+		name = strings.ToLower(strings.Trim(strings.TrimSpace(name), `"'`))
+		switch name {
+		case "sans-serif", "arial", "helvetica":
+			return "helvetica"
+		case "serif", "times", "times new roman":
+			return "times"
+		case "monospace", "courier", "courier new":
+			return "courier"
+		case "symbol", "zapfdingbats":
+			return name
+		}
+		for _, s := range []string{"", "B", "I", "BI"} {
+			if _, ok := f.fonts[name+s]; ok {
+				return name
+			}
+		}
+	}
+	return current
+}
+
+// cssFontStyle returns the style of the bold or italic variant of the font
+// family familyStr, omitting styles for which the family has no variant
+func (f *Fpdf) cssFontStyle(familyStr string, bold, italic bool) string {
+	var styleStr string
+	if bold {
+		styleStr += "B"
+	}
+	if italic {
+		styleStr += "I"
+	}
+	if _, ok := f.coreFonts[familyStr]; ok {
+		if familyStr == "symbol" || familyStr == "zapfdingbats" {
+			return ""
+		}
+		return styleStr
+	}
+	for _, s := range []string{styleStr, strings.Replace(styleStr, "I", "", 1), strings.Replace(styleStr, "B", "", 1)} {
+		if _, ok := f.fonts[familyStr+s]; ok {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,452 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HTMLTokenizerType splits HTML into the tags and text of which it is
+// composed. Segments are returned one at a time by Next(), so the HTML is
+// never held in tokenized form as a whole.
+//
+// Attribute values may be enclosed in double or single quotes, in which case
+// they may contain spaces and '>' characters, or they may be unquoted.
+// Attributes without a value, such as "checked", have an empty value. Tag
+// names and attribute keys are converted to lower case; if an attribute
+// occurs more than once, the first value is retained. A tag that is closed
+// with "/>", such as <span/>, is returned as an open tag followed by a close
+// tag, except for void elements such as BR and IMG, which never have a close
+// tag.
+//
+// Comments, document type declarations and processing instructions are
+// skipped. The content of CDATA sections is returned as text. The content of
+// SCRIPT and STYLE elements is returned as text without further
+// interpretation.
+//
+// Character references in text and attribute values, such as "&amp;",
+// "&eacute;", "&#233;" and "&#x20AC;", are decoded and converted to the
+// encoding of the text. Characters that cannot be represented in that
+// encoding are replaced. All other text is returned unchanged, so it is
+// expected to be in the encoding of the font with which it is rendered.
+// Unknown references are left as they are.
+type HTMLTokenizerType struct {
+	src     string
+	pos     int
+	raw     string // element whose content is returned as raw text
+	pending []HTMLBasicSegmentType
+	tr      func(string) string
+	trNew   func() func(string) string
+}
+
+// HTMLTokenizerNew returns a tokenizer of htmlStr. Character references are
+// converted to the encoding of the current font, or cp1252 if no font has
+// been set. See HTMLTokenizerType for details.
+//
+// The HTMLTokenizerNew() example demonstrates this method.
+func (f *Fpdf) HTMLTokenizerNew(htmlStr string) *HTMLTokenizerType {
+	encStr, fontpath := f.currentFont.Enc, f.fontpath
+	return &HTMLTokenizerType{src: htmlStr, trNew: func() func(string) string {
+		return htmlTranslator(encStr, fontpath)
+	}}
+}
+
+// htmlTranslator returns a function that translates UTF-8 strings to the
+// code page encStr. The descriptor of the code page is taken from the
+// embedded descriptors or from the font directory. If encStr is empty or its
+// descriptor cannot be read, cp1252 is used.
+func htmlTranslator(encStr, fontpath string) (tr func(string) string) {
+	var err error
+	if encStr != "" && encStr != "cp1252" {
+		if str, ok := embeddedMapList[encStr]; ok {
+			tr, err = UnicodeTranslator(strings.NewReader(str))
+		} else {
+			tr, err = UnicodeTranslatorFromFile(filepath.Join(fontpath, encStr) + ".map")
+		}
+		if err == nil {
+			return
+		}
+	}
+	tr, _ = UnicodeTranslator(strings.NewReader(embeddedMapList["cp1252"]))
+	return
+}
+
+// Next returns the next segment of the HTML. ok is false when the end of the
+// HTML has been reached.
+//
+// The HTMLTokenizerNew() example demonstrates this method.
+func (t *HTMLTokenizerType) Next() (seg HTMLBasicSegmentType, ok bool) {
+	if len(t.pending) > 0 {
+		seg = t.pending[0]
+		t.pending = t.pending[1:]
+		return seg, true
+	}
+	if t.raw != "" {
+		end := htmlIndexFold(t.src[t.pos:], "</"+t.raw)
+		if end < 0 {
+			end = len(t.src) - t.pos
+		}
+		t.raw = ""
+		if end > 0 {
+			seg = HTMLBasicSegmentType{Cat: 'T', Str: t.src[t.pos : t.pos+end]}
+			t.pos += end
+			return seg, true
+		}
+	}
+	if t.pos >= len(t.src) {
+		return
+	}
+	if str := t.text(); str != "" {
+		return HTMLBasicSegmentType{Cat: 'T', Str: str}, true
+	}
+	if t.pos >= len(t.src) {
+		return
+	}
+	// text() stops only at the start of a tag
+	if t.src[t.pos+1] == '/' {
+		t.pos += 2
+		name := t.name()
+		end := strings.IndexByte(t.src[t.pos:], '>')
+		if end < 0 {
+			t.pos = len(t.src)
+		} else {
+			t.pos += end + 1
+		}
+		return HTMLBasicSegmentType{Cat: 'C', Str: name}, true
+	}
+	t.pos++
+	seg = HTMLBasicSegmentType{Cat: 'O', Str: t.name(), Attr: make(map[string]string)}
+	selfClosing := t.attributes(seg.Attr)
+	if selfClosing && !htmlVoidTags[seg.Str] {
+		t.pending = append(t.pending, HTMLBasicSegmentType{Cat: 'C', Str: seg.Str})
+	}
+	if !selfClosing && (seg.Str == "script" || seg.Str == "style") {
+		t.raw = seg.Str
+	}
+	return seg, true
+}
+
+// text returns the text that begins at the current position, with character
+// references decoded and comments, declarations and processing instructions
+// removed. It stops at the start of a tag or at the end of the HTML.
+func (t *HTMLTokenizerType) text() string {
+	var buf []byte
+	src := t.src
+	for t.pos < len(src) {
+		ch := src[t.pos]
+		if ch == '&' {
+			if r, n := htmlReference(src[t.pos:]); n > 0 {
+				buf = append(buf, t.translate(r)...)
+				t.pos += n
+				continue
+			}
+		} else if ch == '<' && t.pos+1 < len(src) {
+			next := src[t.pos+1]
+			switch {
+			case htmlIsAlpha(next) || next == '/' && t.pos+2 < len(src) && htmlIsAlpha(src[t.pos+2]):
+				return string(buf)
+			case strings.HasPrefix(src[t.pos:], "<![CDATA["):
+				t.pos += 9
+				end := strings.Index(src[t.pos:], "]]>")
+				if end < 0 {
+					end = len(src) - t.pos
+				}
+				buf = append(buf, src[t.pos:t.pos+end]...)
+				t.pos += end + 3
+				continue
+			case next == '!' || next == '?':
+				endStr := ">"
+				if strings.HasPrefix(src[t.pos:], "<!--") {
+					t.pos += 2
+					endStr = "-->"
+				}
+				end := strings.Index(src[t.pos+2:], endStr)
+				if end < 0 {
+					t.pos = len(src)
+				} else {
+					t.pos += 2 + end + len(endStr)
+				}
+				continue
+			}
+		}
+		buf = append(buf, ch)
+		t.pos++
+	}
+	if t.pos > len(src) {
+		t.pos = len(src)
+	}
+	return string(buf)
+}
+
+// name returns the lower case tag name at the current position
+func (t *HTMLTokenizerType) name() string {
+	start := t.pos
+	for t.pos < len(t.src) && !htmlIsSpace(t.src[t.pos]) && t.src[t.pos] != '/' && t.src[t.pos] != '>' {
+		t.pos++
+	}
+	return strings.ToLower(t.src[start:t.pos])
+}
+
+// attributes reads the attributes of an open tag into attr and advances past
+// the end of the tag. It returns true if the tag is closed with "/>".
+func (t *HTMLTokenizerType) attributes(attr map[string]string) (selfClosing bool) {
+	src := t.src
+	for t.pos < len(src) && src[t.pos] != '>' {
+		ch := src[t.pos]
+		if htmlIsSpace(ch) {
+			t.pos++
+			continue
+		}
+		if ch == '/' {
+			t.pos++
+			selfClosing = t.pos < len(src) && src[t.pos] == '>'
+			continue
+		}
+		selfClosing = false
+		start := t.pos
+		t.pos++
+		for t.pos < len(src) && !htmlIsSpace(src[t.pos]) && !strings.ContainsRune("=>/", rune(src[t.pos])) {
+			t.pos++
+		}
+		key := strings.ToLower(src[start:t.pos])
+		for t.pos < len(src) && htmlIsSpace(src[t.pos]) {
+			t.pos++
+		}
+		var value string
+		if t.pos < len(src) && src[t.pos] == '=' {
+			t.pos++
+			for t.pos < len(src) && htmlIsSpace(src[t.pos]) {
+				t.pos++
+			}
+			if t.pos < len(src) && (src[t.pos] == '"' || src[t.pos] == '\'') {
+				quote := src[t.pos]
+				t.pos++
+				end := strings.IndexByte(src[t.pos:], quote)
+				if end < 0 {
+					end = len(src) - t.pos
+				}
+				value = src[t.pos : t.pos+end]
+				t.pos += end + 1
+			} else {
+				start = t.pos
+				for t.pos < len(src) && !htmlIsSpace(src[t.pos]) && src[t.pos] != '>' {
+					t.pos++
+				}
+				value = src[start:t.pos]
+			}
+		}
+		if _, ok := attr[key]; !ok {
+			attr[key] = t.decode(value)
+		}
+	}
+	t.pos++
+	if t.pos > len(src) {
+		t.pos = len(src)
+	}
+	return
+}
+
+// decode returns str with its character references decoded
+func (t *HTMLTokenizerType) decode(str string) string {
+	if strings.IndexByte(str, '&') < 0 {
+		return str
+	}
+	var buf []byte
+	for j := 0; j < len(str); {
+		if str[j] == '&' {
+			if r, n := htmlReference(str[j:]); n > 0 {
+				buf = append(buf, t.translate(r)...)
+				j += n
+				continue
+			}
+		}
+		buf = append(buf, str[j])
+		j++
+	}
+	return string(buf)
+}
+
+// translate returns r in the encoding of the text
+func (t *HTMLTokenizerType) translate(r rune) string {
+	if r < 0x80 {
+		return string(byte(r))
+	}
+	if t.tr == nil {
+		if t.trNew == nil {
+			return string(r)
+		}
+		t.tr = t.trNew()
+	}
+	return t.tr(string(r))
+}
+
+// htmlReference decodes the character reference at the start of str. n is
+// the number of bytes it occupies, or zero if str does not begin with a
+// known reference. Named references must be terminated with a semicolon,
+// except for the most common ones.
+func htmlReference(str string) (r rune, n int) {
+	if len(str) < 3 || str[0] != '&' {
+		return
+	}
+	if str[1] == '#' {
+		j, base := 2, 10
+		if str[j] == 'x' || str[j] == 'X' {
+			j, base = 3, 16
+		}
+		start := j
+		for j < len(str) && (str[j] >= '0' && str[j] <= '9' ||
+			base == 16 && strings.IndexByte("abcdefABCDEF", str[j]) >= 0) {
+			j++
+		}
+		if j == start {
+			return
+		}
+		v, err := strconv.ParseUint(str[start:j], base, 32)
+		switch {
+		case err != nil || v == 0 || v > 0x10ffff || v >= 0xd800 && v <= 0xdfff:
+			r = 0xfffd
+		case v >= 0x80 && v <= 0x9f:
+			// Numeric references in this range denote cp1252 characters
+			r = htmlWinRunes[v-0x80]
+			if r == 0 {
+				r = rune(v)
+			}
+		default:
+			r = rune(v)
+		}
+		if j < len(str) && str[j] == ';' {
+			j++
+		}
+		return r, j
+	}
+	j := 1
+	for j < len(str) && j < 12 && (htmlIsAlpha(str[j]) || str[j] >= '0' && str[j] <= '9') {
+		j++
+	}
+	nameStr := str[1:j]
+	r, ok := htmlEntities[nameStr]
+	if !ok {
+		return 0, 0
+	}
+	if j < len(str) && str[j] == ';' {
+		return r, j + 1
+	}
+	switch nameStr {
+	case "amp", "lt", "gt", "quot", "nbsp":
+		return r, j
+	}
+	return 0, 0
+}
+
+// htmlIndexFold returns the position of the first occurrence of the lower
+// case string substr in str without regard to case, or -1
+func htmlIndexFold(str, substr string) int {
+	for j := 0; j+len(substr) <= len(str); j++ {
+		if strings.EqualFold(str[j:j+len(substr)], substr) {
+			return j
+		}
+	}
+	return -1
+}
+
+// htmlIsSpace returns true if ch is an HTML whitespace character
+func htmlIsSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f'
+}
+
+// htmlIsAlpha returns true if ch is an ASCII letter
+func htmlIsAlpha(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+// htmlWinRunes maps the code points 0x80 through 0x9f, which are control
+// characters in Unicode, to the characters they denote in cp1252
+var htmlWinRunes = [32]rune{
+	0x20ac, 0, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021,
+	0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017d, 0,
+	0, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014,
+	0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0, 0x017e, 0x0178,
+}
+
+// htmlEntities maps the names of the character entities of HTML 4 to the
+// characters they denote
+var htmlEntities = map[string]rune{
+	"quot": 34, "amp": 38, "apos": 39, "lt": 60, "gt": 62,
+	// Latin-1
+	"nbsp": 160, "iexcl": 161, "cent": 162, "pound": 163, "curren": 164,
+	"yen": 165, "brvbar": 166, "sect": 167, "uml": 168, "copy": 169,
+	"ordf": 170, "laquo": 171, "not": 172, "shy": 173, "reg": 174,
+	"macr": 175, "deg": 176, "plusmn": 177, "sup2": 178, "sup3": 179,
+	"acute": 180, "micro": 181, "para": 182, "middot": 183, "cedil": 184,
+	"sup1": 185, "ordm": 186, "raquo": 187, "frac14": 188, "frac12": 189,
+	"frac34": 190, "iquest": 191, "Agrave": 192, "Aacute": 193, "Acirc": 194,
+	"Atilde": 195, "Auml": 196, "Aring": 197, "AElig": 198, "Ccedil": 199,
+	"Egrave": 200, "Eacute": 201, "Ecirc": 202, "Euml": 203, "Igrave": 204,
+	"Iacute": 205, "Icirc": 206, "Iuml": 207, "ETH": 208, "Ntilde": 209,
+	"Ograve": 210, "Oacute": 211, "Ocirc": 212, "Otilde": 213, "Ouml": 214,
+	"times": 215, "Oslash": 216, "Ugrave": 217, "Uacute": 218, "Ucirc": 219,
+	"Uuml": 220, "Yacute": 221, "THORN": 222, "szlig": 223, "agrave": 224,
+	"aacute": 225, "acirc": 226, "atilde": 227, "auml": 228, "aring": 229,
+	"aelig": 230, "ccedil": 231, "egrave": 232, "eacute": 233, "ecirc": 234,
+	"euml": 235, "igrave": 236, "iacute": 237, "icirc": 238, "iuml": 239,
+	"eth": 240, "ntilde": 241, "ograve": 242, "oacute": 243, "ocirc": 244,
+	"otilde": 245, "ouml": 246, "divide": 247, "oslash": 248, "ugrave": 249,
+	"uacute": 250, "ucirc": 251, "uuml": 252, "yacute": 253, "thorn": 254,
+	"yuml": 255,
+	// Latin Extended and spacing modifiers
+	"OElig": 338, "oelig": 339, "Scaron": 352, "scaron": 353, "Yuml": 376,
+	"fnof": 402, "circ": 710, "tilde": 732,
+	// Greek
+	"Alpha": 913, "Beta": 914, "Gamma": 915, "Delta": 916, "Epsilon": 917,
+	"Zeta": 918, "Eta": 919, "Theta": 920, "Iota": 921, "Kappa": 922,
+	"Lambda": 923, "Mu": 924, "Nu": 925, "Xi": 926, "Omicron": 927,
+	"Pi": 928, "Rho": 929, "Sigma": 931, "Tau": 932, "Upsilon": 933,
+	"Phi": 934, "Chi": 935, "Psi": 936, "Omega": 937, "alpha": 945,
+	"beta": 946, "gamma": 947, "delta": 948, "epsilon": 949, "zeta": 950,
+	"eta": 951, "theta": 952, "iota": 953, "kappa": 954, "lambda": 955,
+	"mu": 956, "nu": 957, "xi": 958, "omicron": 959, "pi": 960,
+	"rho": 961, "sigmaf": 962, "sigma": 963, "tau": 964, "upsilon": 965,
+	"phi": 966, "chi": 967, "psi": 968, "omega": 969, "thetasym": 977,
+	"upsih": 978, "piv": 982,
+	// General punctuation
+	"ensp": 8194, "emsp": 8195, "thinsp": 8201, "zwnj": 8204, "zwj": 8205,
+	"lrm": 8206, "rlm": 8207, "ndash": 8211, "mdash": 8212, "lsquo": 8216,
+	"rsquo": 8217, "sbquo": 8218, "ldquo": 8220, "rdquo": 8221, "bdquo": 8222,
+	"dagger": 8224, "Dagger": 8225, "bull": 8226, "hellip": 8230, "permil": 8240,
+	"prime": 8242, "Prime": 8243, "lsaquo": 8249, "rsaquo": 8250, "oline": 8254,
+	"frasl": 8260, "euro": 8364,
+	// Letter-like symbols and arrows
+	"image": 8465, "weierp": 8472, "real": 8476, "trade": 8482, "alefsym": 8501,
+	"larr": 8592, "uarr": 8593, "rarr": 8594, "darr": 8595, "harr": 8596,
+	"crarr": 8629, "lArr": 8656, "uArr": 8657, "rArr": 8658, "dArr": 8659,
+	"hArr": 8660,
+	// Mathematical operators
+	"forall": 8704, "part": 8706, "exist": 8707, "empty": 8709, "nabla": 8711,
+	"isin": 8712, "notin": 8713, "ni": 8715, "prod": 8719, "sum": 8721,
+	"minus": 8722, "lowast": 8727, "radic": 8730, "prop": 8733, "infin": 8734,
+	"ang": 8736, "and": 8743, "or": 8744, "cap": 8745, "cup": 8746,
+	"int": 8747, "there4": 8756, "sim": 8764, "cong": 8773, "asymp": 8776,
+	"ne": 8800, "equiv": 8801, "le": 8804, "ge": 8805, "sub": 8834,
+	"sup": 8835, "nsub": 8836, "sube": 8838, "supe": 8839, "oplus": 8853,
+	"otimes": 8855, "perp": 8869, "sdot": 8901,
+	// Miscellaneous technical and geometric shapes
+	"lceil": 8968, "rceil": 8969, "lfloor": 8970, "rfloor": 8971, "lang": 9001,
+	"rang": 9002, "loz": 9674, "spades": 9824, "clubs": 9827, "hearts": 9829,
+	"diams": 9830,
+}
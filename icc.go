@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// outputIntentType describes the intended output device of the document
+type outputIntentType struct {
+	subtypeStr   string
+	conditionStr string
+	infoStr      string
+	profile      []byte
+	objNum       int
+}
+
+// iccComponents returns the number of color components of the ICC profile,
+// or zero if the profile is not a valid gray, RGB or CMYK profile.
+func iccComponents(profile []byte) int {
+	if len(profile) < 128 || string(profile[36:40]) != "acsp" {
+		return 0
+	}
+	switch string(profile[16:20]) {
+	case "GRAY":
+		return 1
+	case "RGB ":
+		return 3
+	case "CMYK":
+		return 4
+	}
+	return 0
+}
+
+// imageComponents returns the number of color components of the color
+// space of an image, not counting the palette of an indexed image.
+func imageComponents(csStr string) int {
+	switch csStr {
+	case "DeviceGray":
+		return 1
+	case "DeviceCMYK":
+		return 4
+	}
+	return 3
+}
+
+// jpegICCProfile returns the ICC profile embedded in the APP2 segments of the
+// specified JPEG data, or nil if there is none. Large profiles are split
+// across several segments, each of which carries its sequence number.
+func jpegICCProfile(data []byte) []byte {
+	const sig = "ICC_PROFILE\x00"
+	var chunks [][]byte
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xD8 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			pos += 2
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		n := int(data[pos+2])<<8 | int(data[pos+3])
+		end := pos + 2 + n
+		if n < 2 || end > len(data) {
+			break
+		}
+		seg := data[pos+4 : end]
+		if marker == 0xE2 && len(seg) > len(sig)+2 && string(seg[:len(sig)]) == sig {
+			seq, count := int(seg[len(sig)]), int(seg[len(sig)+1])
+			if chunks == nil {
+				chunks = make([][]byte, count)
+			}
+			if seq < 1 || seq > len(chunks) {
+				return nil
+			}
+			chunks[seq-1] = seg[len(sig)+2:]
+		}
+		pos = end
+	}
+	var buf bytes.Buffer
+	for _, chunk := range chunks {
+		if chunk == nil {
+			return nil
+		}
+		buf.Write(chunk)
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// pngICCProfile returns the ICC profile of the specified iCCP chunk, which
+// holds the name of the profile, a compression method and the compressed
+// profile, or nil if the chunk cannot be read.
+func pngICCProfile(chunk []byte) []byte {
+	pos := bytes.IndexByte(chunk, 0)
+	if pos < 0 || pos+2 > len(chunk) || chunk[pos+1] != 0 {
+		return nil
+	}
+	profile, err := sliceUncompress(chunk[pos+2:])
+	if err != nil {
+		return nil
+	}
+	return profile
+}
+
+// iccPutProfile writes the specified ICC profile as a stream object, unless
+// an identical profile has already been written, and returns the number of
+// the object.
+func (f *Fpdf) iccPutProfile(profile []byte) int {
+	key := string(profile)
+	if n, ok := f.iccObjMap[key]; ok {
+		return n
+	}
+	n := iccComponents(profile)
+	alt := "DeviceRGB"
+	if n == 1 {
+		alt = "DeviceGray"
+	} else if n == 4 {
+		alt = "DeviceCMYK"
+	}
+	f.newobj()
+	if f.compress {
+		profile = sliceCompress(profile)
+		f.outf("<</N %d /Alternate /%s /Filter /FlateDecode /Length %d>>", n, alt, len(profile))
+	} else {
+		f.outf("<</N %d /Alternate /%s /Length %d>>", n, alt, len(profile))
+	}
+	f.putstream(profile)
+	f.out("endobj")
+	f.iccObjMap[key] = f.n
+	return f.n
+}
+
+// SetOutputIntent specifies the output device for which the colors of the
+// document are intended, as required for PDF/A and PDF/X conformance.
+// subtypeStr is "GTS_PDFA1" for PDF/A or "GTS_PDFX" for PDF/X. conditionStr
+// identifies the output condition, for example "sRGB IEC61966-2.1" or
+// "FOGRA39", and infoStr optionally describes it.
+//
+// The ICC profile of the output device is read from r. It may be nil only for
+// PDF/X output conditions that are registered with the ICC, in which case the
+// profile is not embedded. An error occurs if the profile is not a valid
+// gray, RGB or CMYK profile.
+//
+// JPEG and PNG images that carry an ICC profile are always written with an
+// ICCBased color space regardless of this setting.
+func (f *Fpdf) SetOutputIntent(subtypeStr, conditionStr, infoStr string, r io.Reader) {
+	if f.err != nil {
+		return
+	}
+	intent := outputIntentType{subtypeStr: subtypeStr, conditionStr: conditionStr, infoStr: infoStr}
+	if r != nil {
+		intent.profile, f.err = ioutil.ReadAll(r)
+		if f.err != nil {
+			return
+		}
+		if iccComponents(intent.profile) == 0 {
+			f.err = fmt.Errorf("output intent \"%s\" does not have a valid ICC profile", conditionStr)
+			return
+		}
+	}
+	f.outputIntent = &intent
+	if f.pdfVersion < "1.4" {
+		f.pdfVersion = "1.4"
+	}
+}
+
+func (f *Fpdf) iccPutOutputIntent() {
+	intent := f.outputIntent
+	if intent == nil {
+		return
+	}
+	profileNum := 0
+	if intent.profile != nil {
+		profileNum = f.iccPutProfile(intent.profile)
+	}
+	f.newobj()
+	f.outf("<</Type /OutputIntent /S /%s", intent.subtypeStr)
+	f.outf("/OutputConditionIdentifier %s", f.textstring(intent.conditionStr))
+	if intent.infoStr != "" {
+		f.outf("/Info %s", f.textstring(intent.infoStr))
+	}
+	if profileNum > 0 {
+		f.outf("/DestOutputProfile %d 0 R", profileNum)
+	}
+	f.out(">>")
+	f.out("endobj")
+	intent.objNum = f.n
+}
+
+func (f *Fpdf) iccPutCatalog() {
+	if f.outputIntent != nil {
+		f.outf("/OutputIntents [%d 0 R]", f.outputIntent.objNum)
+	}
+}
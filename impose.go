@@ -0,0 +1,280 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// pageTplType is a template that captures the content of a page of the
+// document in which it is used, or an SVG image registered with
+// RegisterSVG(). Unlike other templates, it shares the resource dictionary of
+// the document.
+type pageTplType struct {
+	id   int64
+	size SizeType
+	page *bytes.Buffer
+}
+
+// ID returns the global template identifier
+func (t *pageTplType) ID() int64 {
+	return t.id
+}
+
+// Size gives the bounding dimensions of the page
+func (t *pageTplType) Size() (corner PointType, size SizeType) {
+	return PointType{}, t.size
+}
+
+// Bytes returns the content of the page
+func (t *pageTplType) Bytes() []byte {
+	return t.page.Bytes()
+}
+
+// Images returns nil because the images of the page belong to the document
+func (t *pageTplType) Images() map[string]*ImageInfoType {
+	return nil
+}
+
+// Templates returns nil because the templates of the page belong to the
+// document
+func (t *pageTplType) Templates() []Template {
+	return nil
+}
+
+// PageTemplate returns a template that captures page pageNum of the current
+// document as a whole, for example to place a reduced copy of it on another
+// page with UseTemplateScaled(). The template refers to the content of the
+// page rather than copying it, so content that is subsequently added to the
+// page is included. The template can only be used in the document that
+// created it.
+//
+// The Impose() example demonstrates this method.
+func (f *Fpdf) PageTemplate(pageNum int) Template {
+	if f.err != nil {
+		return nil
+	}
+	if pageNum < 1 || pageNum > f.PageCount() {
+		f.err = fmt.Errorf("page %d does not exist", pageNum)
+		return nil
+	}
+	w, h := f.pagedims(pageNum)
+	return &pageTplType{id: GenerateTemplateID(), size: SizeType{Wd: w, Ht: h},
+		page: f.pages[pageNum]}
+}
+
+// ImposeOptions specifies the layout of the sheets produced by Impose().
+//
+// Sheet is the size of each sheet in the unit of measure specified in New().
+// If it is zero, the default page size is used.
+//
+// Cols and Rows specify the grid of pages placed on each side of a sheet. If
+// both are zero, two pages are placed side by side. Margin is the space
+// between the edges of the sheet and the grid, and Gap is the space between
+// adjacent cells of the grid. Each page is scaled uniformly to fit its cell.
+// Pages are centered in their cells, except in booklets, where they are
+// placed against the fold.
+//
+// If Booklet is true, pages are arranged for saddle stitching: each sheet has
+// a front and a back side with two pages each, and folding the stack of
+// printed sheets in half yields the pages in order. Blank pages are added as
+// needed to make the number of pages a multiple of four. Cols and Rows are
+// ignored. Creep is the distance by which the pages of each sheet are shifted
+// toward the fold relative to the sheet that encloses it, in order to
+// compensate for the thickness of the paper.
+//
+// If CropMarks is true, crop marks of length CropMarkLen are drawn outside
+// the corners of each page. If CropMarkLen is zero, twelve points are used.
+type ImposeOptions struct {
+	Sheet       SizeType
+	Cols, Rows  int
+	Margin, Gap float64
+	Booklet     bool
+	Creep       float64
+	CropMarks   bool
+	CropMarkLen float64
+}
+
+// Impose arranges the pages of the current document on larger sheets, for
+// example to print two or four pages on each side of a sheet or to produce a
+// saddle-stitched booklet. The pages of the document are finished as Close()
+// would finish them: the footer function is called for the last page and the
+// page stamp function, if any, is called for every page. Each page is then
+// captured as a template and the pages are replaced with the sheets described
+// by opt.
+//
+// Internal links, link targets and bookmarks are transferred to the sheets
+// on which their pages are placed. The header, footer and page stamp
+// functions are cleared. The last sheet becomes the current page, and further
+// content and pages may be added to the document in the usual way.
+//
+// The Impose() example demonstrates this method.
+func (f *Fpdf) Impose(opt ImposeOptions) {
+	if f.err != nil {
+		return
+	}
+	if f.state != 2 {
+		f.err = fmt.Errorf("document has no open pages to impose")
+		return
+	}
+	if f.clipNest > 0 || f.transformNest > 0 {
+		f.err = fmt.Errorf("clip and transformation procedures must be ended before imposing pages")
+		return
+	}
+	if opt.Sheet.Wd <= 0 || opt.Sheet.Ht <= 0 {
+		opt.Sheet.Wd, opt.Sheet.Ht = f.pagedims(0)
+	}
+	cols, rows := opt.Cols, opt.Rows
+	if opt.Booklet || (cols < 1 && rows < 1) {
+		cols, rows = 2, 1
+	} else if cols < 1 {
+		cols = 1
+	} else if rows < 1 {
+		rows = 1
+	}
+	if opt.CropMarkLen == 0 {
+		opt.CropMarkLen = 12 / f.k
+	}
+	// Finish the pages of the document
+	f.finishpage()
+	if f.pageStampFnc != nil {
+		f.stamppages()
+		if f.err != nil {
+			return
+		}
+	}
+	nb := f.PageCount()
+	tplList := make([]*pageTplType, nb+1)
+	for n := 1; n <= nb; n++ {
+		f.resolvepage(f.pages[n], nb)
+		tplList[n] = f.PageTemplate(n).(*pageTplType)
+	}
+	if f.err != nil {
+		return
+	}
+	pageLinks := f.pageLinks
+	// Arrange the pages on the sides of the sheets
+	perSide := cols * rows
+	var sides [][]int
+	if opt.Booklet {
+		total := (nb + 3) / 4 * 4
+		for j := 0; j < total/4; j++ {
+			sides = append(sides, []int{total - 2*j, 1 + 2*j}, []int{2 + 2*j, total - 1 - 2*j})
+		}
+	} else {
+		for n := 1; n <= nb; n += perSide {
+			side := make([]int, perSide)
+			for k := range side {
+				side[k] = n + k
+			}
+			sides = append(sides, side)
+		}
+	}
+	// Replace the pages with sheets
+	f.pages, f.pageLinks = f.pages[:1:1], f.pageLinks[:1:1]
+	f.pageSizes = make(map[int]SizeType)
+	f.pageStates = nil
+	f.page = 0
+	f.headerFnc, f.footerFnc, f.pageStampFnc = nil, nil, nil
+	type placeType struct {
+		sheet   int
+		x, y, s float64 // position on sheet and scale factor
+	}
+	places := make([]placeType, nb+1)
+	cellWd := (opt.Sheet.Wd - 2*opt.Margin - float64(cols-1)*opt.Gap) / float64(cols)
+	cellHt := (opt.Sheet.Ht - 2*opt.Margin - float64(rows-1)*opt.Gap) / float64(rows)
+	for j, side := range sides {
+		f.AddPageFormat("P", opt.Sheet)
+		if f.err != nil {
+			return
+		}
+		for k, n := range side {
+			if n < 1 || n > nb {
+				continue
+			}
+			t := tplList[n]
+			col, row := k%cols, k/cols
+			cellX := opt.Margin + float64(col)*(cellWd+opt.Gap)
+			cellY := opt.Margin + float64(row)*(cellHt+opt.Gap)
+			s := math.Min(cellWd/t.size.Wd, cellHt/t.size.Ht)
+			wd, ht := t.size.Wd*s, t.size.Ht*s
+			x := cellX + (cellWd-wd)/2
+			y := cellY + (cellHt-ht)/2
+			if opt.Booklet {
+				shift := opt.Creep * float64(j/2)
+				if col == 0 {
+					x = cellX + cellWd - wd + shift
+				} else {
+					x = cellX - shift
+				}
+			}
+			f.templates[t.id] = t
+			f.outf("q %.5f 0 0 %.5f %.5f %.5f cm /TPL%d Do Q", s, s, x*f.k, (f.h-y-ht)*f.k, t.id)
+			if opt.CropMarks {
+				f.imposeCropMarks(x, y, wd, ht, opt.CropMarkLen)
+			}
+			places[n] = placeType{sheet: f.page, x: x, y: y, s: s}
+			for _, pl := range pageLinks[n] {
+				f.pageLinks[f.page] = append(f.pageLinks[f.page], linkType{
+					x:  x*f.k + pl.x*s,
+					y:  (f.h-y-ht)*f.k + pl.y*s,
+					wd: pl.wd * s, ht: pl.ht * s,
+					link: pl.link, linkStr: pl.linkStr})
+			}
+		}
+	}
+	// Transfer link targets and bookmarks to the sheets
+	for j := range f.links {
+		if p := f.links[j].page; p > 0 && p <= nb {
+			pl := places[p]
+			f.links[j].page = pl.sheet
+			f.links[j].y = pl.y + f.links[j].y*pl.s
+		}
+	}
+	for j := range f.outlines {
+		if p := f.outlines[j].p; p > 0 && p <= nb {
+			pl := places[p]
+			f.outlines[j].p = pl.sheet
+			f.outlines[j].y = pl.y + f.outlines[j].y*pl.s
+		}
+	}
+}
+
+// imposeCropMarks draws crop marks outside the corners of the specified
+// rectangle
+func (f *Fpdf) imposeCropMarks(x, y, w, h, markLen float64) {
+	lw := f.lineWidth
+	f.SetLineWidth(0.25 / f.k)
+	gap := 3 / f.k
+	for _, cx := range []float64{x, x + w} {
+		dx := -1.0
+		if cx > x {
+			dx = 1
+		}
+		for _, cy := range []float64{y, y + h} {
+			dy := -1.0
+			if cy > y {
+				dy = 1
+			}
+			f.Line(cx+dx*gap, cy, cx+dx*(gap+markLen), cy)
+			f.Line(cx, cy+dy*gap, cx, cy+dy*(gap+markLen))
+		}
+	}
+	f.SetLineWidth(lw)
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"strconv"
+	"strings"
+)
+
+type indexEntryType struct {
+	terms []string
+	page  int
+	y     float64
+}
+
+// indexNodeType is a term in the hierarchy of index terms
+type indexNodeType struct {
+	term     string
+	entries  []indexEntryType
+	children []*indexNodeType
+}
+
+// IndexOptions specifies the layout of the index written by Index().
+//
+// LineHt is the line height in the unit of measure specified in New(). If it
+// is zero, the height of the current font is used.
+//
+// ColCount is the number of columns in which the index is laid out. A value
+// less than one is treated as one. ColGap is the horizontal space between
+// columns; if it is zero, twelve points are used.
+//
+// Indent is the indentation of each subordinate level of terms. Lines of an
+// entry that wrap are indented by the same amount. If it is zero, twelve
+// points are used.
+//
+// RangeSep separates the first and last pages of a range of consecutive
+// pages. If it is empty, an en dash ("\x96" in the cp1252 encoding used by
+// the core fonts) is used.
+type IndexOptions struct {
+	LineHt   float64
+	ColCount int
+	ColGap   float64
+	Indent   float64
+	RangeSep string
+}
+
+// IndexEntry records an occurrence of an index term on the current page at the
+// current vertical position. Terms are hierarchical: the first argument is the
+// main term and any following arguments are successively subordinate terms.
+// For example,
+//
+//	pdf.IndexEntry("Widgets", "Blue")
+//
+// lists the current page under "Blue" beneath the main term "Widgets". The
+// recorded entries are written with Index().
+//
+// The Index() example demonstrates this method.
+func (f *Fpdf) IndexEntry(terms ...string) {
+	if f.err != nil || f.measure != nil {
+		return
+	}
+	if len(terms) == 0 {
+		f.SetErrorf("index entry requires at least one term")
+		return
+	}
+	if f.page < 1 {
+		f.SetErrorf("index entry cannot be recorded before the first page is added")
+		return
+	}
+	f.index = append(f.index, indexEntryType{
+		terms: append([]string(nil), terms...),
+		page:  f.page,
+		y:     f.y,
+	})
+}
+
+// Index writes the entries recorded with IndexEntry() as a back-of-book index,
+// beginning at the current position. Terms are sorted alphabetically without
+// regard to case, and subordinate terms are listed beneath their main term.
+// Each term is followed by the pages on which it occurs; consecutive pages are
+// merged into ranges such as "12\x9614". Each page number or range is an
+// internal link to the first occurrence of the term on that page.
+//
+// The index is laid out in columns as specified by opt. An entry is not split
+// between columns unless it is taller than a column, in which case it
+// continues at the top of the next one. When the last column of a page is
+// filled, a new page is added and the index continues at the top of its first
+// column. The current
+// font is used throughout. Upon method exit, the current position is at the
+// left margin below the last entry.
+func (f *Fpdf) Index(opt IndexOptions) {
+	if f.err != nil {
+		return
+	}
+	if opt.LineHt == 0 {
+		opt.LineHt = f.fontSize
+	}
+	if opt.ColCount < 1 {
+		opt.ColCount = 1
+	}
+	if opt.ColGap == 0 {
+		opt.ColGap = 12 / f.k
+	}
+	if opt.Indent == 0 {
+		opt.Indent = 12 / f.k
+	}
+	if opt.RangeSep == "" {
+		opt.RangeSep = "\x96"
+	}
+	root := indexTree(f.index)
+	lMargin, rMargin := f.lMargin, f.rMargin
+	acceptPageBreak := f.acceptPageBreak
+	colWd := (f.w - lMargin - rMargin - float64(opt.ColCount-1)*opt.ColGap) / float64(opt.ColCount)
+	col := 0
+	colTop := f.y
+	atTop := true
+	setCol := func() {
+		x := lMargin + float64(col)*(colWd+opt.ColGap)
+		f.lMargin = x
+		f.rMargin = f.w - x - colWd
+		f.x = x
+	}
+	// nextCol moves to the top of the next column, keeping the position
+	// relative to the current column and any indentation of the margins
+	nextCol := func() {
+		dx := -float64(col) * (colWd + opt.ColGap)
+		x := f.x
+		col++
+		if col == opt.ColCount {
+			col = 0
+			f.AddPage()
+			colTop = f.y
+		}
+		dx += float64(col) * (colWd + opt.ColGap)
+		f.lMargin += dx
+		f.rMargin -= dx
+		f.x, f.y = x+dx, colTop
+	}
+	// Entries that are taller than a column break where they reach the
+	// bottom of it; no break occurs while an entry is measured
+	f.acceptPageBreak = func() bool {
+		if f.measure == nil {
+			nextCol()
+		}
+		return false
+	}
+	var putNode func(node *indexNodeType, level int)
+	putNode = func(node *indexNodeType, level int) {
+		indent := opt.Indent * float64(level)
+		put := func() {
+			f.lMargin += indent + opt.Indent
+			f.x += indent
+			f.Write(opt.LineHt, node.term)
+			f.putIndexPages(opt, node.entries)
+			f.lMargin -= indent + opt.Indent
+		}
+		m := f.Measure(put)
+		fits := m.Y+opt.LineHt-f.y <= f.pageBreakTrigger-colTop
+		if !atTop && fits && m.Y+opt.LineHt > f.pageBreakTrigger {
+			nextCol()
+		}
+		put()
+		f.Ln(opt.LineHt)
+		atTop = false
+		for _, child := range node.children {
+			putNode(child, level+1)
+		}
+	}
+	setCol()
+	for _, node := range root.children {
+		putNode(node, 0)
+	}
+	f.acceptPageBreak = acceptPageBreak
+	f.lMargin, f.rMargin = lMargin, rMargin
+	f.x = lMargin
+}
+
+// putIndexPages writes the page numbers of the specified entries, merging
+// consecutive pages into ranges
+func (f *Fpdf) putIndexPages(opt IndexOptions, entries []indexEntryType) {
+	for j := 0; j < len(entries); {
+		first := entries[j]
+		last := first.page
+		for j++; j < len(entries) && entries[j].page <= last+1; j++ {
+			last = entries[j].page
+		}
+		pageStr := strconv.Itoa(first.page)
+		if last > first.page {
+			pageStr += opt.RangeSep + strconv.Itoa(last)
+		}
+		link := 0
+		if f.measure == nil {
+			link = f.AddLink()
+			f.SetLink(link, first.y, first.page)
+		}
+		f.Write(opt.LineHt, ", ")
+		f.WriteLinkID(opt.LineHt, pageStr, link)
+	}
+}
+
+// indexTree arranges the specified entries into a tree of sorted terms. The
+// entries of each term are sorted by position and retain only the first
+// occurrence on each page.
+func indexTree(entries []indexEntryType) (root *indexNodeType) {
+	root = new(indexNodeType)
+	for _, entry := range entries {
+		node := root
+		for _, term := range entry.terms {
+			var child *indexNodeType
+			for _, c := range node.children {
+				if c.term == term {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &indexNodeType{term: term}
+				node.children = append(node.children, child)
+			}
+			node = child
+		}
+		node.entries = append(node.entries, entry)
+	}
+	var sortNode func(node *indexNodeType)
+	sortNode = func(node *indexNodeType) {
+		entries := node.entries
+		gensort(len(entries), func(i, j int) bool {
+			a, b := entries[i], entries[j]
+			return a.page < b.page || a.page == b.page && a.y < b.y
+		}, func(i, j int) {
+			entries[i], entries[j] = entries[j], entries[i]
+		})
+		list := node.entries[:0]
+		for _, entry := range node.entries {
+			if len(list) == 0 || list[len(list)-1].page != entry.page {
+				list = append(list, entry)
+			}
+		}
+		node.entries = list
+		children := node.children
+		gensort(len(children), func(i, j int) bool {
+			a, b := children[i].term, children[j].term
+			la, lb := strings.ToLower(a), strings.ToLower(b)
+			if la == lb {
+				return a < b
+			}
+			return la < lb
+		}, func(i, j int) {
+			children[i], children[j] = children[j], children[i]
+		})
+		for _, child := range node.children {
+			sortNode(child)
+		}
+	}
+	sortNode(root)
+	return
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LabelSheetType describes a sheet of labels or cards arranged in a regular
+// grid.
+//
+// UnitStr specifies the unit of measure of the remaining dimensions: "pt",
+// "mm", "cm" or "in". If it is empty, the unit of measure specified in New()
+// is used. Page is the size of the sheet; if it is zero, the default page
+// size is used.
+//
+// Cols and Rows specify the number of labels across and down the sheet, and
+// Label is the size of each label. Pitch is the distance between the upper
+// left corners of horizontally and vertically adjacent labels; a zero
+// component is replaced with the corresponding component of Label, which is
+// the case for labels that abut each other. Left and Top are the distances
+// from the left and top edges of the sheet to the upper left corner of the
+// first label.
+type LabelSheetType struct {
+	UnitStr    string
+	Page       SizeType
+	Cols, Rows int
+	Label      SizeType
+	Pitch      SizeType
+	Left, Top  float64
+}
+
+var labelSheetPresets = map[string]LabelSheetType{
+	"avery5160":  {"in", SizeType{8.5, 11}, 3, 10, SizeType{2.625, 1}, SizeType{2.75, 1}, 0.1875, 0.5},
+	"avery5161":  {"in", SizeType{8.5, 11}, 2, 10, SizeType{4, 1}, SizeType{4.1875, 1}, 0.15625, 0.5},
+	"avery5163":  {"in", SizeType{8.5, 11}, 2, 5, SizeType{4, 2}, SizeType{4.1875, 2}, 0.15625, 0.5},
+	"avery5167":  {"in", SizeType{8.5, 11}, 4, 20, SizeType{1.75, 0.5}, SizeType{2.0625, 0.5}, 0.28125, 0.5},
+	"avery5371":  {"in", SizeType{8.5, 11}, 2, 5, SizeType{3.5, 2}, SizeType{3.5, 2}, 0.75, 0.5},
+	"averyl7160": {"mm", SizeType{210, 297}, 3, 7, SizeType{63.5, 38.1}, SizeType{66.04, 38.1}, 7.21, 15.15},
+	"averyl7163": {"mm", SizeType{210, 297}, 2, 7, SizeType{99.1, 38.1}, SizeType{101.6, 38.1}, 4.65, 15.15},
+	"averyl7173": {"mm", SizeType{210, 297}, 2, 5, SizeType{99.1, 57}, SizeType{101.6, 57}, 4.65, 6},
+	"averyl7651": {"mm", SizeType{210, 297}, 5, 13, SizeType{38.1, 21.2}, SizeType{40.6, 21.2}, 4.75, 10.7},
+	"herma4360":  {"mm", SizeType{210, 297}, 3, 8, SizeType{70, 36}, SizeType{70, 36}, 0, 4.5},
+}
+
+// LabelSheetPreset returns the layout of a commonly used label or card sheet.
+// nameStr is the manufacturer followed by the product number, for example
+// "Avery 5160" or "Herma 4360"; case, spaces and hyphens are ignored. The
+// following sheets are known:
+//
+//	Avery 5160   US Letter, 3 x 10 address labels, 2-5/8 x 1 in
+//	Avery 5161   US Letter, 2 x 10 address labels, 4 x 1 in
+//	Avery 5163   US Letter, 2 x 5 shipping labels, 4 x 2 in
+//	Avery 5167   US Letter, 4 x 20 return address labels, 1-3/4 x 1/2 in
+//	Avery 5371   US Letter, 2 x 5 business cards, 3-1/2 x 2 in
+//	Avery L7160  A4, 3 x 7 address labels, 63.5 x 38.1 mm
+//	Avery L7163  A4, 2 x 7 address labels, 99.1 x 38.1 mm
+//	Avery L7173  A4, 2 x 5 shipping labels, 99.1 x 57 mm
+//	Avery L7651  A4, 5 x 13 mini labels, 38.1 x 21.2 mm
+//	Herma 4360   A4, 3 x 8 address labels, 70 x 36 mm
+//
+// ok is false if the sheet is not known.
+//
+// The Labels() example demonstrates this function.
+func LabelSheetPreset(nameStr string) (sheet LabelSheetType, ok bool) {
+	nameStr = strings.ToLower(nameStr)
+	nameStr = strings.NewReplacer(" ", "", "-", "").Replace(nameStr)
+	sheet, ok = labelSheetPresets[nameStr]
+	return
+}
+
+// Labels lays out count labels on sheets described by sheet. The first skip
+// positions of the first sheet are left empty so that partially used sheets
+// can be printed on. Labels are filled in row by row, and a new page of the
+// size of the sheet is added whenever a sheet is full. The first label is
+// always placed on a new page.
+//
+// For each label, fnc is called with the zero-based index of the label and
+// its size in the unit of measure specified in New(). During the call, the
+// origin of the coordinate system is the upper left corner of the label and
+// everything drawn is clipped to the label. The current position is at the
+// origin, the margins are zero and automatic page breaks are disabled, so
+// that text written with methods such as Cell() and MultiCell() is laid out
+// within the label. Link areas, such as those of Link() and CellFormat(), are
+// positioned on the label as well. Changes that fnc makes to the font, colors
+// and line attributes do not carry over to the next label. fnc must not add
+// pages.
+//
+// Upon method exit, the last sheet is the current page and the margins and
+// page break setting in effect before the call are restored.
+//
+// The Labels() example demonstrates this method.
+func (f *Fpdf) Labels(sheet LabelSheetType, skip, count int, fnc func(index int, size SizeType)) {
+	if f.err != nil {
+		return
+	}
+	var scale float64
+	switch sheet.UnitStr {
+	case "":
+		scale = 1
+	case "pt", "point":
+		scale = 1 / f.k
+	case "mm":
+		scale = 72 / 25.4 / f.k
+	case "cm":
+		scale = 72 / 2.54 / f.k
+	case "in", "inch":
+		scale = 72 / f.k
+	default:
+		f.err = fmt.Errorf("incorrect unit %s", sheet.UnitStr)
+		return
+	}
+	if sheet.Cols < 1 || sheet.Rows < 1 || sheet.Label.Wd <= 0 || sheet.Label.Ht <= 0 {
+		f.err = fmt.Errorf("label sheet requires at least one row and column of labels with positive size")
+		return
+	}
+	if skip < 0 || count < 0 {
+		f.err = fmt.Errorf("invalid label count")
+		return
+	}
+	page := f.defPageSize
+	if sheet.Page.Wd > 0 && sheet.Page.Ht > 0 {
+		page = SizeType{Wd: sheet.Page.Wd * scale, Ht: sheet.Page.Ht * scale}
+	}
+	size := SizeType{Wd: sheet.Label.Wd * scale, Ht: sheet.Label.Ht * scale}
+	pitch := SizeType{Wd: sheet.Pitch.Wd * scale, Ht: sheet.Pitch.Ht * scale}
+	if pitch.Wd == 0 {
+		pitch.Wd = size.Wd
+	}
+	if pitch.Ht == 0 {
+		pitch.Ht = size.Ht
+	}
+	left, top := sheet.Left*scale, sheet.Top*scale
+	lMargin, tMargin, rMargin := f.lMargin, f.tMargin, f.rMargin
+	acceptPageBreak := f.acceptPageBreak
+	// The graphics state is restored at the end of each label, so the
+	// tracked state is restored to match
+	familyStr, styleStr, underline := f.fontFamily, f.fontStyle, f.underline
+	fontSizePt, fontSize, currentFont := f.fontSizePt, f.fontSize, f.currentFont
+	lw, capStyle, joinStyle := f.lineWidth, f.capStyle, f.joinStyle
+	dashArray, dashPhase := f.dashArray, f.dashPhase
+	color, colorFlag := f.color, f.colorFlag
+	alpha, blendModeStr := f.alpha, f.blendMode
+	perSheet := sheet.Cols * sheet.Rows
+	sheetNum := -1
+	for j := 0; j < count && f.err == nil; j++ {
+		pos := skip + j
+		for sheetNum < pos/perSheet {
+			f.AddPageFormat("P", page)
+			sheetNum++
+		}
+		pos %= perSheet
+		x := left + float64(pos%sheet.Cols)*pitch.Wd
+		y := top + float64(pos/sheet.Cols)*pitch.Ht
+		f.TransformBegin()
+		f.TransformTranslate(x, y)
+		f.ClipRect(0, 0, size.Wd, size.Ht, false)
+		f.lMargin, f.tMargin, f.rMargin = 0, 0, f.w-size.Wd
+		f.acceptPageBreak = func() bool { return false }
+		f.x, f.y = 0, 0
+		f.linkOffset = PointType{X: x, Y: y}
+		fnc(j, size)
+		f.linkOffset = PointType{}
+		f.acceptPageBreak = acceptPageBreak
+		f.lMargin, f.tMargin, f.rMargin = lMargin, tMargin, rMargin
+		f.ClipEnd()
+		f.TransformEnd()
+		f.fontFamily, f.fontStyle, f.underline = familyStr, styleStr, underline
+		f.fontSizePt, f.fontSize, f.currentFont = fontSizePt, fontSize, currentFont
+		f.lineWidth, f.capStyle, f.joinStyle = lw, capStyle, joinStyle
+		f.dashArray, f.dashPhase = dashArray, dashPhase
+		f.color, f.colorFlag = color, colorFlag
+		f.alpha, f.blendMode = alpha, blendModeStr
+	}
+	f.acceptPageBreak = acceptPageBreak
+	f.lMargin, f.tMargin, f.rMargin = lMargin, tMargin, rMargin
+	f.x, f.y = lMargin, tMargin
+}
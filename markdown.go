@@ -0,0 +1,1270 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MarkdownFontType specifies a font used by MarkdownType. FontSize is in
+// points. Empty or zero values select the corresponding attribute of the
+// font that is current when Write() is called.
+type MarkdownFontType struct {
+	FontFamily string
+	FontStyle  string
+	FontSize   float64
+}
+
+// MarkdownType is used for rendering Markdown. The subset of CommonMark that
+// is supported comprises ATX and setext headings, paragraphs with hard and
+// soft line breaks, emphasis, code spans, links (inline, reference and
+// automatic), images, bulleted and numbered lists, block quotes, fenced and
+// indented code blocks and thematic breaks, as well as tables as defined by
+// GitHub Flavored Markdown. Raw HTML is not rendered, except that a BR tag
+// breaks the line.
+//
+// Headings[0] through Headings[5] specify the fonts of headings of levels 1
+// through 6. If Bookmarks is true, a bookmark is created for each heading;
+// heading levels that are skipped in the document are closed up in the
+// outline. Code specifies the font of code spans and code blocks, and
+// CodeFill (0 through 255) the background color of code blocks. In the Link
+// structure, the ClrR, ClrG and ClrB fields define the color of hyperlinks
+// and the Bold, Italic and Underscore values define their style. Quote.Indent
+// is the indentation of block quotes, at the left of which a bar of the color
+// given by Quote.ClrR, Quote.ClrG and Quote.ClrB is drawn. The List field
+// controls the indentation and labels of lists as it does for HTMLBasicType;
+// a numbered list uses the delimiter of its first item unless the Numbering
+// field of its level is set. BlockSpacing is the vertical space between
+// blocks in the unit of measure specified in New().
+//
+// Text is written without translation, so it needs to be in the encoding of
+// the fonts that are used, as it is for Write(). Character references such
+// as "&eacute;" are converted to the encoding of the current font.
+type MarkdownType struct {
+	pdf      *Fpdf
+	Headings [6]MarkdownFontType
+	Code     MarkdownFontType
+	CodeFill struct {
+		ClrR, ClrG, ClrB int
+	}
+	Link struct {
+		ClrR, ClrG, ClrB         int
+		Bold, Italic, Underscore bool
+	}
+	Quote struct {
+		Indent           float64
+		ClrR, ClrG, ClrB int
+	}
+	List         ListType
+	BlockSpacing float64
+	Bookmarks    bool
+	links        map[string]int // link identifiers of heading anchors
+	slugs        map[string]int // number of headings with each anchor name
+	outline      int            // level of the most recent bookmark
+	parser       *mdParserType
+	body         MarkdownFontType
+	lineHt       float64
+	pending      float64 // vertical space before the next block
+}
+
+// MarkdownNew returns an instance that facilitates writing Markdown in the
+// specified PDF document. By default, headings are bold and sized from twice
+// the current font size down to the current font size, code is set in
+// Courier on a light gray background, and headings are bookmarked.
+//
+// The MarkdownNew() example demonstrates this method.
+func (f *Fpdf) MarkdownNew() (md MarkdownType) {
+	md.pdf = f
+	for j, scale := range []float64{2, 1.5, 1.25, 1.1, 1, 1} {
+		md.Headings[j] = MarkdownFontType{FontStyle: "B", FontSize: scale * f.fontSizePt}
+	}
+	md.Code = MarkdownFontType{FontFamily: "Courier"}
+	md.CodeFill.ClrR, md.CodeFill.ClrG, md.CodeFill.ClrB = 240, 240, 240
+	md.Link.ClrR, md.Link.ClrG, md.Link.ClrB = 0, 0, 128
+	md.Link.Underscore = true
+	md.Quote.Indent = 18 / f.k
+	md.Quote.ClrR, md.Quote.ClrG, md.Quote.ClrB = 192, 192, 192
+	md.List = f.ListNew()
+	md.BlockSpacing = 6 / f.k
+	md.Bookmarks = true
+	md.links = make(map[string]int)
+	md.slugs = make(map[string]int)
+	md.outline = -1
+	return
+}
+
+// Write renders the specified Markdown beginning at the left margin of the
+// current vertical position, using the current font for body text. lineHt
+// indicates the line height of body text in the unit of measure specified in
+// New(); the line heights of headings and code are scaled in proportion to
+// their font sizes. Lines fill the space between the left and right margins,
+// and page breaks occur as needed. Upon method exit, the current position is
+// at the left margin below the rendered content, and the font and colors are
+// restored.
+//
+// Links to fragment identifiers, such as "#installation", are internal links
+// to the heading whose text, converted to lower case with spaces replaced by
+// hyphens and punctuation removed, matches the identifier. Images are placed
+// on lines of their own at their natural size, reduced if necessary to fit
+// the page. Link reference definitions apply only to the Markdown in which
+// they appear.
+//
+// The MarkdownNew() example demonstrates this method.
+func (md *MarkdownType) Write(lineHt float64, mdStr string) {
+	f := md.pdf
+	if f.err != nil {
+		return
+	}
+	if md.links == nil {
+		md.links = make(map[string]int)
+		md.slugs = make(map[string]int)
+	}
+	md.parser = &mdParserType{defs: make(map[string]string),
+		tr: htmlTranslator(f.currentFont.Enc, f.fontpath)}
+	blocks := md.parser.parse(mdLines(mdStr))
+	md.body = MarkdownFontType{FontFamily: f.fontFamily, FontStyle: f.fontStyle, FontSize: f.fontSizePt}
+	if f.underline {
+		md.body.FontStyle += "U"
+	}
+	fill, text := f.color.fill, f.color.text
+	md.lineHt = lineHt
+	md.pending = 0
+	f.x = f.lMargin
+	md.blocks(blocks, false, 0)
+	if md.body.FontFamily != "" {
+		f.SetFont(md.body.FontFamily, md.body.FontStyle, md.body.FontSize)
+	}
+	f.setFillClr(fill)
+	f.setTextClr(text)
+	f.x = f.lMargin
+	md.parser = nil
+}
+
+// blocks renders a sequence of blocks. Paragraphs in tight lists are not
+// separated by vertical space. depth is the nesting level of lists.
+func (md *MarkdownType) blocks(list []*mdBlockType, tight bool, depth int) {
+	f := md.pdf
+	for _, blk := range list {
+		if f.err != nil {
+			return
+		}
+		f.y += md.pending
+		md.pending = 0
+		switch blk.kind {
+		case mdParagraph:
+			md.setFont(md.body, false, false, false)
+			md.spans(md.parser.inlines(blk.text), md.body, md.lineHt)
+			if f.x > f.lMargin {
+				f.Ln(md.lineHt)
+			}
+		case mdHeading:
+			md.heading(blk)
+		case mdCode:
+			md.code(blk)
+		case mdQuote:
+			md.quote(blk, depth)
+		case mdList:
+			md.list(blk, depth)
+		case mdRule:
+			md.pageBreak(md.lineHt)
+			y := f.y + md.lineHt/2
+			f.Line(f.lMargin, y, f.w-f.rMargin, y)
+			f.y += md.lineHt
+		case mdTable:
+			md.table(blk)
+		}
+		if !tight || blk.kind != mdParagraph {
+			md.pending = md.BlockSpacing
+		}
+	}
+}
+
+// heading renders a heading, bookmarks it and makes it the target of links
+// to its anchor name
+func (md *MarkdownType) heading(blk *mdBlockType) {
+	f := md.pdf
+	font := md.font(md.Headings[blk.level-1])
+	lineHt := md.lineHt * font.FontSize / md.body.FontSize
+	// Keep the heading together with the first line that follows it
+	md.pageBreak(lineHt + md.BlockSpacing + md.lineHt)
+	spans := md.parser.inlines(blk.text)
+	txtStr := mdPlain(spans)
+	if md.Bookmarks {
+		level := blk.level - 1
+		if level > md.outline+1 {
+			level = md.outline + 1
+		}
+		md.outline = level
+		f.Bookmark(txtStr, level, -1)
+	}
+	nameStr := mdSlug(txtStr)
+	if n := md.slugs[nameStr]; n > 0 {
+		md.slugs[nameStr]++
+		nameStr += "-" + strconv.Itoa(n)
+	} else {
+		md.slugs[nameStr] = 1
+	}
+	if id, ok := md.links[nameStr]; ok {
+		f.SetLink(id, f.y, -1)
+	} else {
+		md.links[nameStr] = f.AddLink()
+		f.SetLink(md.links[nameStr], f.y, -1)
+	}
+	md.setFont(font, false, false, false)
+	md.spans(spans, font, lineHt)
+	if f.x > f.lMargin {
+		f.Ln(lineHt)
+	}
+}
+
+// code renders a code block on a filled background that spans the width
+// between the margins
+func (md *MarkdownType) code(blk *mdBlockType) {
+	f := md.pdf
+	font := md.font(md.Code)
+	lineHt := md.lineHt * font.FontSize / md.body.FontSize
+	wd := f.w - f.rMargin - f.lMargin
+	pad := lineHt / 4
+	md.setFont(font, false, false, false)
+	fill := f.color.fill
+	f.SetFillColor(md.CodeFill.ClrR, md.CodeFill.ClrG, md.CodeFill.ClrB)
+	f.x = f.lMargin
+	f.CellFormat(wd, pad, "", "", 2, "", true, 0, "")
+	f.MultiCell(wd, lineHt, blk.text, "", "L", true)
+	f.CellFormat(wd, pad, "", "", 1, "", true, 0, "")
+	f.setFillClr(fill)
+}
+
+// quote renders a block quote with a bar at its left. When the quote is
+// split by a page break, the bar is drawn on each page.
+func (md *MarkdownType) quote(blk *mdBlockType, depth int) {
+	f := md.pdf
+	lMargin := f.lMargin
+	y := f.y
+	bar := func() {
+		fill := f.color.fill
+		f.SetFillColor(md.Quote.ClrR, md.Quote.ClrG, md.Quote.ClrB)
+		f.Rect(lMargin, y, 3/f.k, f.y-y, "F")
+		f.setFillClr(fill)
+	}
+	acceptPageBreak := f.acceptPageBreak
+	f.acceptPageBreak = func() bool {
+		if !acceptPageBreak() {
+			return false
+		}
+		bar()
+		y = f.tMargin
+		return true
+	}
+	f.SetLeftMargin(lMargin + md.Quote.Indent)
+	md.blocks(blk.children, false, depth)
+	f.acceptPageBreak = acceptPageBreak
+	bar()
+	f.SetLeftMargin(lMargin)
+}
+
+// list renders the items of a list. Each item is labeled as it is by
+// HTMLBasicType and its content is rendered with a hanging indent.
+func (md *MarkdownType) list(blk *mdBlockType, depth int) {
+	f := md.pdf
+	lMargin := f.lMargin
+	labelX := lMargin
+	if depth > 0 {
+		labelX += md.List.Indent - md.List.LabelWd
+	}
+	for k, item := range blk.children {
+		if k > 0 {
+			f.y += md.pending
+		}
+		lvl := md.List.level(depth)
+		var labelStr string
+		if blk.ordered {
+			if lvl.Numbering == "" {
+				lvl.Numbering = "1" + string(blk.delim)
+			}
+			labelStr = listNumber(lvl.Numbering, blk.start+k)
+		} else {
+			labelStr = lvl.Bullet
+			if labelStr == "" {
+				labelStr = "\x95"
+			}
+		}
+		md.pageBreak(md.lineHt)
+		md.setFont(md.body, false, false, false)
+		f.x = labelX
+		md.List.label(md.lineHt, lvl, labelStr)
+		f.SetLeftMargin(f.x)
+		md.pending = 0
+		md.blocks(item.children, blk.tight, depth+1)
+		if f.x > f.lMargin || len(item.children) == 0 {
+			f.Ln(md.lineHt)
+		}
+		f.SetLeftMargin(lMargin)
+		f.x = lMargin
+		md.pending = 0
+		if !blk.tight {
+			md.pending = md.BlockSpacing
+		}
+	}
+}
+
+// table renders a table with a bold header row that is repeated at the top
+// of each page. Columns are as wide as their content if the table fits
+// between the margins; otherwise they are narrowed and their text wrapped.
+func (md *MarkdownType) table(blk *mdBlockType) {
+	f := md.pdf
+	cols := len(blk.align)
+	rows := make([][]string, len(blk.rows))
+	for j, row := range blk.rows {
+		rows[j] = make([]string, cols)
+		for k := range rows[j] {
+			if k < len(row) {
+				rows[j][k] = mdPlain(md.parser.inlines(row[k]))
+			}
+		}
+	}
+	// Natural and minimum widths of the columns
+	nat := make([]float64, cols)
+	min := make([]float64, cols)
+	var natSum, minSum float64
+	for k := 0; k < cols; k++ {
+		for j, row := range rows {
+			md.setFont(md.body, j == 0, false, false)
+			nat[k] = htmlMax(nat[k], f.GetStringWidth(row[k]))
+			for _, wordStr := range strings.Fields(row[k]) {
+				min[k] = htmlMax(min[k], f.GetStringWidth(wordStr))
+			}
+		}
+		nat[k] += 2 * f.cMargin
+		min[k] += 2 * f.cMargin
+		natSum += nat[k]
+		minSum += min[k]
+	}
+	wds := nat
+	if avail := f.w - f.lMargin - f.rMargin; natSum > avail {
+		wds = make([]float64, cols)
+		for k := range wds {
+			if minSum >= avail {
+				wds[k] = avail * min[k] / minSum
+			} else {
+				wds[k] = min[k] + (avail-minSum)*(nat[k]-min[k])/(natSum-minSum)
+			}
+		}
+	}
+	var putRow func(row []string, head bool)
+	putRow = func(row []string, head bool) {
+		md.setFont(md.body, head, false, false)
+		lines := make([][][]byte, cols)
+		n := 1
+		for k := range row {
+			lines[k] = f.SplitLines([]byte(row[k]), wds[k])
+			if len(lines[k]) > n {
+				n = len(lines[k])
+			}
+		}
+		ht := float64(n) * md.lineHt
+		page := f.page
+		md.pageBreak(ht)
+		if f.page != page && !head {
+			putRow(rows[0], true)
+			md.setFont(md.body, false, false, false)
+		}
+		x, y := f.lMargin, f.y
+		for k := range row {
+			f.Rect(x, y, wds[k], ht, "D")
+			for j, line := range lines[k] {
+				f.SetXY(x, y+float64(j)*md.lineHt)
+				f.CellFormat(wds[k], md.lineHt, string(line), "", 0, string(blk.align[k]), false, 0, "")
+			}
+			x += wds[k]
+		}
+		f.SetXY(f.lMargin, y+ht)
+	}
+	for j, row := range rows {
+		putRow(row, j == 0)
+	}
+}
+
+// image places an image on a line of its own
+func (md *MarkdownType) image(sp mdSpanType) {
+	f := md.pdf
+	if f.x > f.lMargin {
+		f.Ln(md.lineHt)
+	}
+	info := f.GetImageInfo(sp.image)
+	if info == nil {
+		info = f.RegisterImageOptions(sp.image, ImageOptions{})
+	}
+	if f.err != nil {
+		return
+	}
+	wd, ht := info.Extent()
+	if avail := f.w - f.lMargin - f.rMargin; wd > avail {
+		wd, ht = avail, ht*avail/wd
+	}
+	if avail := f.pageBreakTrigger - f.tMargin; ht > avail {
+		wd, ht = wd*avail/ht, avail
+	}
+	var linkID int
+	var linkStr string
+	if strings.HasPrefix(sp.link, "#") {
+		linkID = md.linkID(sp.link[1:])
+	} else {
+		linkStr = sp.link
+	}
+	f.ImageOptions(sp.image, f.lMargin, 0, wd, ht, true, ImageOptions{}, linkID, linkStr)
+	f.x = f.lMargin
+}
+
+// spans writes inline content in the specified font
+func (md *MarkdownType) spans(list []mdSpanType, font MarkdownFontType, lineHt float64) {
+	f := md.pdf
+	for _, sp := range list {
+		switch {
+		case sp.brk:
+			f.Ln(lineHt)
+		case sp.image != "":
+			md.image(sp)
+		default:
+			ft := font
+			if sp.code {
+				code := md.font(md.Code)
+				ft.FontFamily, ft.FontStyle = code.FontFamily, code.FontStyle
+				if md.Code.FontSize > 0 {
+					ft.FontSize = md.Code.FontSize
+				}
+			}
+			if sp.link == "" {
+				md.setFont(ft, sp.bold, sp.italic, false)
+				f.Write(lineHt, sp.text)
+				continue
+			}
+			text := f.color.text
+			f.SetTextColor(md.Link.ClrR, md.Link.ClrG, md.Link.ClrB)
+			md.setFont(ft, sp.bold || md.Link.Bold, sp.italic || md.Link.Italic, md.Link.Underscore)
+			if strings.HasPrefix(sp.link, "#") {
+				f.WriteLinkID(lineHt, sp.text, md.linkID(sp.link[1:]))
+			} else {
+				f.WriteLinkString(lineHt, sp.text, sp.link)
+			}
+			f.setTextClr(text)
+		}
+	}
+}
+
+// linkID returns the link identifier of the heading with the specified
+// anchor name. Until the heading is written, the link refers to the current
+// position.
+func (md *MarkdownType) linkID(nameStr string) int {
+	f := md.pdf
+	id, ok := md.links[nameStr]
+	if !ok {
+		id = f.AddLink()
+		md.links[nameStr] = id
+	}
+	if f.links[id].page == 0 {
+		f.SetLink(id, f.y, -1)
+	}
+	return id
+}
+
+// font returns ft with empty attributes replaced by those of the body font
+func (md *MarkdownType) font(ft MarkdownFontType) MarkdownFontType {
+	if ft.FontFamily == "" {
+		ft.FontFamily = md.body.FontFamily
+	}
+	if ft.FontStyle == "" {
+		ft.FontStyle = md.body.FontStyle
+	}
+	if ft.FontSize == 0 {
+		ft.FontSize = md.body.FontSize
+	}
+	return ft
+}
+
+// setFont selects the specified font with bold, italic and underscore added
+// to its style as requested
+func (md *MarkdownType) setFont(ft MarkdownFontType, bold, italic, underscore bool) {
+	ft = md.font(ft)
+	styleStr := strings.ToUpper(ft.FontStyle)
+	if bold && !strings.Contains(styleStr, "B") {
+		styleStr += "B"
+	}
+	if italic && !strings.Contains(styleStr, "I") {
+		styleStr += "I"
+	}
+	if underscore && !strings.Contains(styleStr, "U") {
+		styleStr += "U"
+	}
+	md.pdf.SetFont(ft.FontFamily, styleStr, ft.FontSize)
+}
+
+// pageBreak adds a page if content of height ht does not fit on the current
+// page, in the same way as the automatic page break of CellFormat()
+func (md *MarkdownType) pageBreak(ht float64) {
+	f := md.pdf
+	if f.y+ht > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.pageBreakAllowed() && f.acceptPageBreak() {
+		x := f.x
+		f.AddPageFormat(f.curOrientation, f.curPageSize)
+		f.x = x
+	}
+}
+
+// mdPlain returns the text of the specified spans without formatting
+func mdPlain(list []mdSpanType) string {
+	var buf []byte
+	for _, sp := range list {
+		if sp.brk {
+			buf = append(buf, ' ')
+		} else {
+			buf = append(buf, sp.text...)
+		}
+	}
+	return string(buf)
+}
+
+// mdSlug returns the anchor name of a heading with the specified text
+func mdSlug(txtStr string) string {
+	var buf []byte
+	for _, ch := range []byte(strings.ToLower(strings.TrimSpace(txtStr))) {
+		switch {
+		case ch == ' ':
+			buf = append(buf, '-')
+		case ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '_' || ch >= 0x80:
+			buf = append(buf, ch)
+		}
+	}
+	return string(buf)
+}
+
+// Block parsing
+
+const (
+	mdParagraph = iota
+	mdHeading
+	mdCode
+	mdQuote
+	mdList
+	mdItem
+	mdRule
+	mdTable
+)
+
+// mdBlockType is a block of a Markdown document. Paragraphs and headings
+// hold their unparsed inline content in text; code blocks hold their literal
+// content.
+type mdBlockType struct {
+	kind     int
+	level    int    // heading level
+	text     string // inline content or code
+	children []*mdBlockType
+	ordered  bool   // list is numbered
+	start    int    // number of first item
+	delim    byte   // list marker character or numbering delimiter
+	tight    bool   // list items are not separated by blank lines
+	align    []byte // alignment of table columns
+	rows     [][]string
+}
+
+// mdParserType parses Markdown. defs maps the normalized labels of link
+// reference definitions to their destinations. tr converts characters
+// denoted by character references to the encoding of the text.
+type mdParserType struct {
+	defs map[string]string
+	tr   func(string) string
+}
+
+var (
+	mdFenceRe = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	mdATXRe   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdRuleRe  = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdQuoteRe = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	mdItemRe  = regexp.MustCompile(`^( {0,3})([-+*]|[0-9]{1,9}[.)])([ \t]+|$)`)
+	mdSetexRe = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdDelimRe = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	mdDefRe   = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^ \t>]+)>?(?:[ \t]+(?:"[^"]*"|'[^']*'|\([^)]*\)))?[ \t]*$`)
+)
+
+// mdLines splits str into lines with tabs expanded to multiples of four
+// columns
+func mdLines(str string) (lines []string) {
+	str = strings.Replace(str, "\r\n", "\n", -1)
+	str = strings.Replace(str, "\r", "\n", -1)
+	for _, line := range strings.Split(str, "\n") {
+		if strings.IndexByte(line, '\t') >= 0 {
+			var buf []byte
+			for j := 0; j < len(line); j++ {
+				if line[j] == '\t' {
+					buf = append(buf, ' ')
+					for len(buf)%4 != 0 {
+						buf = append(buf, ' ')
+					}
+				} else {
+					buf = append(buf, line[j])
+				}
+			}
+			line = string(buf)
+		}
+		lines = append(lines, line)
+	}
+	return
+}
+
+// mdBlank returns true if line contains only whitespace
+func mdBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// mdIndent returns the number of spaces at the start of line
+func mdIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// mdTrimIndent removes up to n spaces from the start of line
+func mdTrimIndent(line string, n int) string {
+	if ind := mdIndent(line); ind < n {
+		n = ind
+	}
+	return line[n:]
+}
+
+// mdLabel returns the normalized form of a link label
+func mdLabel(str string) string {
+	return strings.ToLower(strings.Join(strings.Fields(str), " "))
+}
+
+// interrupts returns true if line begins a block that ends a paragraph
+func (p *mdParserType) interrupts(line string) bool {
+	if mdATXRe.MatchString(line) || mdFenceRe.MatchString(line) ||
+		mdRuleRe.MatchString(line) || mdQuoteRe.MatchString(line) {
+		return true
+	}
+	if m := mdItemRe.FindStringSubmatch(line); m != nil {
+		return !mdBlank(line[len(m[0]):])
+	}
+	return false
+}
+
+// parse returns the blocks of the specified lines
+func (p *mdParserType) parse(lines []string) (list []*mdBlockType) {
+	for j := 0; j < len(lines); {
+		line := lines[j]
+		if mdBlank(line) {
+			j++
+			continue
+		}
+		if m := mdFenceRe.FindStringSubmatch(line); m != nil {
+			indent, fence := len(m[1]), m[2]
+			var code []string
+			for j++; j < len(lines); j++ {
+				str := strings.TrimSpace(lines[j])
+				if mdIndent(lines[j]) < 4 && len(str) >= len(fence) &&
+					strings.Trim(str, fence[:1]) == "" {
+					j++
+					break
+				}
+				code = append(code, mdTrimIndent(lines[j], indent))
+			}
+			list = append(list, &mdBlockType{kind: mdCode, text: strings.Join(code, "\n")})
+			continue
+		}
+		if mdIndent(line) >= 4 {
+			var code []string
+			for ; j < len(lines) && (mdBlank(lines[j]) || mdIndent(lines[j]) >= 4); j++ {
+				code = append(code, mdTrimIndent(lines[j], 4))
+			}
+			for mdBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			list = append(list, &mdBlockType{kind: mdCode, text: strings.Join(code, "\n")})
+			continue
+		}
+		if m := mdATXRe.FindStringSubmatch(line); m != nil {
+			list = append(list, &mdBlockType{kind: mdHeading, level: len(m[1]), text: m[2]})
+			j++
+			continue
+		}
+		if mdRuleRe.MatchString(line) {
+			list = append(list, &mdBlockType{kind: mdRule})
+			j++
+			continue
+		}
+		if mdQuoteRe.MatchString(line) {
+			var sub []string
+			for ; j < len(lines); j++ {
+				if m := mdQuoteRe.FindStringSubmatch(lines[j]); m != nil {
+					sub = append(sub, m[1])
+				} else if !mdBlank(lines[j]) && !mdBlank(sub[len(sub)-1]) && !p.interrupts(lines[j]) {
+					// Lazy continuation of a paragraph
+					sub = append(sub, lines[j])
+				} else {
+					break
+				}
+			}
+			list = append(list, &mdBlockType{kind: mdQuote, children: p.parse(sub)})
+			continue
+		}
+		if mdItemRe.MatchString(line) {
+			blk, n := p.list(lines[j:])
+			list = append(list, blk)
+			j += n
+			continue
+		}
+		if blk, n := p.table(lines[j:]); blk != nil {
+			list = append(list, blk)
+			j += n
+			continue
+		}
+		if m := mdDefRe.FindStringSubmatch(line); m != nil {
+			if label := mdLabel(m[1]); p.defs[label] == "" {
+				p.defs[label] = mdUnescape(m[2], p.tr)
+			}
+			j++
+			continue
+		}
+		var para []string
+		blk := &mdBlockType{kind: mdParagraph}
+		for ; j < len(lines) && !mdBlank(lines[j]); j++ {
+			if len(para) > 0 {
+				if m := mdSetexRe.FindStringSubmatch(lines[j]); m != nil {
+					blk.kind, blk.level = mdHeading, 1
+					if m[1][0] == '-' {
+						blk.level = 2
+					}
+					j++
+					break
+				}
+				if p.interrupts(lines[j]) {
+					break
+				}
+			}
+			para = append(para, strings.TrimLeft(lines[j], " "))
+		}
+		blk.text = strings.TrimRight(strings.Join(para, "\n"), " ")
+		list = append(list, blk)
+	}
+	return
+}
+
+// list returns a list that begins with the first of the specified lines and
+// the number of lines it occupies
+func (p *mdParserType) list(lines []string) (blk *mdBlockType, n int) {
+	blk = &mdBlockType{kind: mdList, tight: true}
+	for n < len(lines) {
+		if mdRuleRe.MatchString(lines[n]) {
+			break
+		}
+		m := mdItemRe.FindStringSubmatch(lines[n])
+		if m == nil {
+			break
+		}
+		marker := m[2]
+		delim := marker[len(marker)-1]
+		ordered := delim == '.' || delim == ')'
+		if len(blk.children) == 0 {
+			blk.ordered, blk.delim = ordered, delim
+			if ordered {
+				blk.start, _ = strconv.Atoi(marker[:len(marker)-1])
+			}
+		} else if ordered != blk.ordered || delim != blk.delim {
+			break
+		}
+		// Content is indented to the column following the marker
+		space := len(m[3])
+		if space > 4 || mdBlank(lines[n][len(m[0]):]) {
+			space = 1
+		}
+		indent := len(m[1]) + len(marker) + space
+		first := ""
+		if len(lines[n]) > indent {
+			first = lines[n][indent:]
+		}
+		sub := []string{first}
+		for n++; n < len(lines); n++ {
+			line := lines[n]
+			switch {
+			case mdBlank(line):
+				sub = append(sub, "")
+			case mdIndent(line) >= indent:
+				sub = append(sub, line[indent:])
+			case sub[len(sub)-1] != "" && !p.interrupts(line):
+				// Lazy continuation of a paragraph
+				sub = append(sub, line)
+			default:
+				goto done
+			}
+		}
+	done:
+		trailing := 0
+		for len(sub) > 1 && sub[len(sub)-1] == "" {
+			sub = sub[:len(sub)-1]
+			trailing++
+		}
+		item := &mdBlockType{kind: mdItem, children: p.parse(sub)}
+		if len(item.children) > 1 {
+			for _, line := range sub {
+				if line == "" {
+					blk.tight = false
+				}
+			}
+		}
+		blk.children = append(blk.children, item)
+		if trailing > 0 && n < len(lines) && mdItemRe.MatchString(lines[n]) {
+			blk.tight = false
+		}
+	}
+	return
+}
+
+// table returns a table that begins with the first of the specified lines
+// and the number of lines it occupies, or nil if the lines do not begin with
+// a table header and delimiter row
+func (p *mdParserType) table(lines []string) (blk *mdBlockType, n int) {
+	if len(lines) < 2 || strings.IndexByte(lines[0], '|') < 0 || !mdDelimRe.MatchString(lines[1]) {
+		return nil, 0
+	}
+	head := mdRow(lines[0])
+	delims := mdRow(lines[1])
+	if len(head) != len(delims) {
+		return nil, 0
+	}
+	blk = &mdBlockType{kind: mdTable, rows: [][]string{head}}
+	for _, str := range delims {
+		align := byte('L')
+		if strings.HasSuffix(str, ":") {
+			align = 'R'
+			if strings.HasPrefix(str, ":") {
+				align = 'C'
+			}
+		}
+		blk.align = append(blk.align, align)
+	}
+	for n = 2; n < len(lines) && !mdBlank(lines[n]) && !p.interrupts(lines[n]); n++ {
+		blk.rows = append(blk.rows, mdRow(lines[n]))
+	}
+	return
+}
+
+// mdRow returns the cells of a table row
+func mdRow(line string) (cells []string) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+	start := 0
+	for j := 0; j <= len(line); j++ {
+		if j == len(line) || line[j] == '|' && (j == 0 || line[j-1] != '\\') {
+			cellStr := strings.Replace(line[start:j], "\\|", "|", -1)
+			cells = append(cells, strings.TrimSpace(cellStr))
+			start = j + 1
+		}
+	}
+	return
+}
+
+// Inline parsing
+
+// mdSpanType is a run of inline content with uniform formatting
+type mdSpanType struct {
+	text         string
+	bold, italic bool
+	code         bool
+	link         string // destination of link
+	image        string // source of image; text is its description
+	brk          bool   // hard line break
+}
+
+// mdNodeType is a span or a run of emphasis delimiters that may be matched
+// with another run
+type mdNodeType struct {
+	span        mdSpanType
+	delim       byte // '*' or '_', or zero for spans
+	n, orig     int  // remaining and original length of run
+	open, close bool // run can open or close emphasis
+}
+
+var (
+	mdAutoLinkRe = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<> ]*)>`)
+	mdEmailRe    = regexp.MustCompile(`^<([^ <>@]+@[^ <>@]+)>`)
+	mdTagRe      = regexp.MustCompile(`^</?([A-Za-z][A-Za-z0-9-]*)(?:\s[^<>]*)?/?>`)
+)
+
+// mdIsPunct returns true if ch is an ASCII punctuation character
+func mdIsPunct(ch byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", ch) >= 0
+}
+
+// mdIsSpace returns true if ch is whitespace
+func mdIsSpace(ch byte) bool {
+	return ch == ' ' || ch == '\n' || ch == '\t'
+}
+
+// mdUnescape returns str with backslash escapes and character references
+// decoded
+func mdUnescape(str string, tr func(string) string) string {
+	var buf []byte
+	for j := 0; j < len(str); {
+		switch {
+		case str[j] == '\\' && j+1 < len(str) && mdIsPunct(str[j+1]):
+			buf = append(buf, str[j+1])
+			j += 2
+			continue
+		case str[j] == '&':
+			if r, n := htmlReference(str[j:]); n > 0 {
+				if r < 0x80 {
+					buf = append(buf, byte(r))
+				} else {
+					buf = append(buf, tr(string(r))...)
+				}
+				j += n
+				continue
+			}
+		}
+		buf = append(buf, str[j])
+		j++
+	}
+	return string(buf)
+}
+
+// mdBackticks returns the length of the run of backticks at str[j]
+func mdBackticks(str string, j int) (n int) {
+	for j+n < len(str) && str[j+n] == '`' {
+		n++
+	}
+	return
+}
+
+// mdCodeEnd returns the position of the run of exactly n backticks that
+// closes a code span whose content begins at str[j], or -1
+func mdCodeEnd(str string, j, n int) int {
+	for j < len(str) {
+		if str[j] == '`' {
+			k := mdBackticks(str, j)
+			if k == n {
+				return j
+			}
+			j += k
+		} else {
+			j++
+		}
+	}
+	return -1
+}
+
+// mdCloseBracket returns the position of the bracket that closes the one at
+// str[j], or -1
+func mdCloseBracket(str string, j int) int {
+	depth := 0
+	for k := j; k < len(str); k++ {
+		switch str[k] {
+		case '\\':
+			k++
+		case '`':
+			n := mdBackticks(str, k)
+			if end := mdCodeEnd(str, k+n, n); end >= 0 {
+				k = end + n - 1
+			} else {
+				k += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return k
+			}
+		}
+	}
+	return -1
+}
+
+// mdDestination parses the destination and optional title of an inline link
+// that begin with the parenthesis at str[j]. It returns the destination and
+// the position that follows the closing parenthesis.
+func mdDestination(str string, j int) (dest string, end int, ok bool) {
+	skip := func() {
+		for j < len(str) && mdIsSpace(str[j]) {
+			j++
+		}
+	}
+	j++
+	skip()
+	if j < len(str) && str[j] == '<' {
+		k := strings.IndexAny(str[j+1:], ">\n")
+		if k < 0 || str[j+1+k] != '>' {
+			return
+		}
+		dest = str[j+1 : j+1+k]
+		j += k + 2
+	} else {
+		start, depth := j, 0
+	loop:
+		for ; j < len(str); j++ {
+			switch ch := str[j]; {
+			case ch == '\\' && j+1 < len(str):
+				j++
+			case ch <= ' ':
+				break loop
+			case ch == '(':
+				depth++
+			case ch == ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			}
+		}
+		dest = str[start:j]
+	}
+	skip()
+	if j < len(str) && strings.IndexByte("\"'(", str[j]) >= 0 {
+		closeCh := str[j]
+		if closeCh == '(' {
+			closeCh = ')'
+		}
+		k := strings.IndexByte(str[j+1:], closeCh)
+		if k < 0 {
+			return
+		}
+		j += k + 2
+		skip()
+	}
+	if j >= len(str) || str[j] != ')' {
+		return
+	}
+	return dest, j + 1, true
+}
+
+// link parses a link or image whose text begins with the bracket at str[j].
+// It returns the destination of the link, its text and the position that
+// follows it.
+func (p *mdParserType) link(str string, j int) (dest, txtStr string, end int, ok bool) {
+	k := mdCloseBracket(str, j)
+	if k < 0 {
+		return
+	}
+	txtStr = str[j+1 : k]
+	end = k + 1
+	if end < len(str) && str[end] == '(' {
+		if d, e, found := mdDestination(str, end); found {
+			return mdUnescape(d, p.tr), txtStr, e, true
+		}
+	}
+	label := txtStr
+	if end < len(str) && str[end] == '[' {
+		if e := strings.IndexByte(str[end:], ']'); e > 0 {
+			if ref := str[end+1 : end+e]; ref != "" {
+				label = ref
+			}
+			end += e + 1
+		}
+	}
+	dest, ok = p.defs[mdLabel(label)]
+	return
+}
+
+// inlines returns the spans of the specified inline content
+func (p *mdParserType) inlines(str string) (list []mdSpanType) {
+	var nodes []mdNodeType
+	var buf []byte
+	flush := func() {
+		if len(buf) > 0 {
+			nodes = append(nodes, mdNodeType{span: mdSpanType{text: string(buf)}})
+			buf = buf[:0]
+		}
+	}
+	span := func(sp mdSpanType) {
+		flush()
+		nodes = append(nodes, mdNodeType{span: sp})
+	}
+	for j := 0; j < len(str); {
+		ch := str[j]
+		switch {
+		case ch == '\\' && j+1 < len(str) && str[j+1] == '\n':
+			span(mdSpanType{brk: true})
+			j += 2
+			continue
+		case ch == '\\' && j+1 < len(str) && mdIsPunct(str[j+1]):
+			buf = append(buf, str[j+1])
+			j += 2
+			continue
+		case ch == '\n':
+			trimmed := strings.TrimRight(string(buf), " ")
+			hard := len(buf)-len(trimmed) >= 2
+			buf = append(buf[:0], trimmed...)
+			if hard {
+				span(mdSpanType{brk: true})
+			} else {
+				buf = append(buf, ' ')
+			}
+			for j++; j < len(str) && str[j] == ' '; j++ {
+			}
+			continue
+		case ch == '`':
+			n := mdBackticks(str, j)
+			if end := mdCodeEnd(str, j+n, n); end >= 0 {
+				code := strings.Replace(str[j+n:end], "\n", " ", -1)
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+					code = code[1 : len(code)-1]
+				}
+				span(mdSpanType{text: code, code: true})
+				j = end + n
+			} else {
+				buf = append(buf, str[j:j+n]...)
+				j += n
+			}
+			continue
+		case ch == '*' || ch == '_':
+			n := 1
+			for j+n < len(str) && str[j+n] == ch {
+				n++
+			}
+			before, after := byte(' '), byte(' ')
+			if j > 0 {
+				before = str[j-1]
+			}
+			if j+n < len(str) {
+				after = str[j+n]
+			}
+			left := !mdIsSpace(after) && (!mdIsPunct(after) || mdIsSpace(before) || mdIsPunct(before))
+			right := !mdIsSpace(before) && (!mdIsPunct(before) || mdIsSpace(after) || mdIsPunct(after))
+			open, close := left, right
+			if ch == '_' {
+				open = left && (!right || mdIsPunct(before))
+				close = right && (!left || mdIsPunct(after))
+			}
+			flush()
+			nodes = append(nodes, mdNodeType{delim: ch, n: n, orig: n, open: open, close: close})
+			j += n
+			continue
+		case ch == '!' && j+1 < len(str) && str[j+1] == '[':
+			if dest, txtStr, end, ok := p.link(str, j+1); ok {
+				span(mdSpanType{text: mdPlain(p.inlines(txtStr)), image: dest})
+				j = end
+				continue
+			}
+		case ch == '[':
+			if dest, txtStr, end, ok := p.link(str, j); ok {
+				flush()
+				for _, sp := range p.inlines(txtStr) {
+					if sp.link == "" {
+						sp.link = dest
+					}
+					nodes = append(nodes, mdNodeType{span: sp})
+				}
+				j = end
+				continue
+			}
+		case ch == '<':
+			if m := mdAutoLinkRe.FindStringSubmatch(str[j:]); m != nil {
+				span(mdSpanType{text: m[1], link: m[1]})
+				j += len(m[0])
+				continue
+			}
+			if m := mdEmailRe.FindStringSubmatch(str[j:]); m != nil {
+				span(mdSpanType{text: m[1], link: "mailto:" + m[1]})
+				j += len(m[0])
+				continue
+			}
+			if m := mdTagRe.FindStringSubmatch(str[j:]); m != nil {
+				if strings.ToLower(m[1]) == "br" {
+					span(mdSpanType{brk: true})
+				}
+				j += len(m[0])
+				continue
+			}
+		case ch == '&':
+			if r, n := htmlReference(str[j:]); n > 0 {
+				if r < 0x80 {
+					buf = append(buf, byte(r))
+				} else {
+					buf = append(buf, p.tr(string(r))...)
+				}
+				j += n
+				continue
+			}
+		}
+		buf = append(buf, ch)
+		j++
+	}
+	flush()
+	mdEmphasis(nodes)
+	for _, nd := range nodes {
+		sp := nd.span
+		if nd.delim != 0 {
+			if nd.n == 0 {
+				continue
+			}
+			sp.text = strings.Repeat(string(nd.delim), nd.n)
+		}
+		if n := len(list); n > 0 && mdSameFormat(list[n-1], sp) {
+			list[n-1].text += sp.text
+			continue
+		}
+		list = append(list, sp)
+	}
+	return
+}
+
+// mdSameFormat returns true if a and b are runs of text that are formatted
+// alike
+func mdSameFormat(a, b mdSpanType) bool {
+	return !a.brk && !b.brk && a.image == "" && b.image == "" && a.bold == b.bold &&
+		a.italic == b.italic && a.code == b.code && a.link == b.link
+}
+
+// mdEmphasis matches runs of emphasis delimiters in nodes as CommonMark
+// specifies and applies the resulting emphasis to the nodes between them
+func mdEmphasis(nodes []mdNodeType) {
+	for c := 0; c < len(nodes); c++ {
+		cl := &nodes[c]
+		if cl.delim == 0 || !cl.close || cl.n == 0 {
+			continue
+		}
+		for o := c - 1; o >= 0; o-- {
+			op := &nodes[o]
+			if op.delim != cl.delim || !op.open || op.n == 0 {
+				continue
+			}
+			if (op.close || cl.open) && (op.orig+cl.orig)%3 == 0 && (op.orig%3 != 0 || cl.orig%3 != 0) {
+				continue
+			}
+			use := 1
+			if op.n >= 2 && cl.n >= 2 {
+				use = 2
+			}
+			for k := o + 1; k < c; k++ {
+				if use == 2 {
+					nodes[k].span.bold = true
+				} else {
+					nodes[k].span.italic = true
+				}
+				nodes[k].open, nodes[k].close = false, false
+			}
+			op.n -= use
+			cl.n -= use
+			if cl.n > 0 {
+				// The rest of the closing run may close another opener
+				c--
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"math"
+	"strings"
+)
+
+// MeasureType describes the result of a dry run performed with Measure().
+// Pages is the number of pages the output would span; it is 1 if no page
+// break would occur. X and Y specify the current position after the output.
+// Min and Max are the upper left and lower right corners of the bounding box
+// of the output. If the output spans more than one page, the bounding box is
+// the union of the areas occupied on each page. Empty is true if nothing that
+// occupies space was output.
+type MeasureType struct {
+	Pages    int
+	X, Y     float64
+	Min, Max PointType
+	Empty    bool
+}
+
+// Extent returns the width and height of the bounding box of a measurement.
+func (m MeasureType) Extent() SizeType {
+	return SizeType{Wd: m.Max.X - m.Min.X, Ht: m.Max.Y - m.Min.Y}
+}
+
+type measureRecType struct {
+	pages    int
+	empty    bool
+	min, max PointType
+}
+
+// Measure calls fnc, which would typically call methods like MultiCell(),
+// Write() or a header function, without producing any page content. No
+// output is emitted, page breaks are simulated rather than performed, and
+// links, link destinations and bookmarks are not registered. When fnc
+// returns, the current page and position, the page geometry, the margins,
+// the font, the colors and the other drawing settings are restored to the
+// values they had before Measure() was called. Everything else that fnc
+// does to the document, such as loading fonts and images or registering
+// spot colors, patterns and soft masks, is retained, since the application
+// may keep references to these resources.
+//
+// When a page break would occur, the header function, if any, is called (in
+// measuring mode) so that the resulting position accounts for it. The footer
+// function is not called. Output produced by the header function does not
+// contribute to the bounding box.
+//
+// The bounding box accounts for cells (including those output by MultiCell()
+// and Write()), text, images, lines, rectangles, polygons, Bézier figures and
+// ellipses.
+//
+// An error that occurs during the dry run is retained by the document.
+func (f *Fpdf) Measure(fnc func()) (m MeasureType) {
+	if f.err != nil {
+		return
+	}
+	save := f.saveLayout()
+	f.measure = &measureRecType{pages: 1, empty: true}
+	if f.page == 0 {
+		// Output begins on the first page that fnc adds
+		f.measure.pages = 0
+	}
+	fnc()
+	rec := f.measure
+	m.Pages = rec.pages
+	m.X, m.Y = f.x, f.y
+	m.Empty = rec.empty
+	if !rec.empty {
+		m.Min, m.Max = rec.min, rec.max
+	}
+	f.restoreLayout(save)
+	return
+}
+
+// measureLayoutType records the layout state of a document that Measure()
+// restores after a dry run
+type measureLayoutType struct {
+	page, openPage                       int
+	x, y, lasth                          float64
+	w, h, wPt, hPt, pageBreakTrigger     float64
+	curOrientation                       string
+	curPageSize                          SizeType
+	pageStates                           map[int]pageStateType
+	lMargin, tMargin, rMargin, bMargin   float64
+	cMargin, cRadius                     float64
+	cCornersStr                          string
+	autoPageBreak                        bool
+	acceptPageBreak                      func() bool
+	headerFnc, footerFnc                 func()
+	inHeader, inFooter                   bool
+	fontFamily, fontStyle                string
+	underline                            bool
+	currentFont                          fontDefType
+	fontSizePt, fontSize, ws             float64
+	lineWidth, dashPhase, alpha          float64
+	capStyle, joinStyle                  int
+	dashArray                            []float64
+	blendMode                            string
+	draw, fill, text                     clrType
+	colorFlag                            bool
+	clipNest, transformNest, layerActive int
+	measure                              *measureRecType
+}
+
+// saveLayout returns the layout state of the document
+func (f *Fpdf) saveLayout() (s measureLayoutType) {
+	s.page, s.openPage = f.page, f.openPage
+	s.x, s.y, s.lasth = f.x, f.y, f.lasth
+	s.w, s.h, s.wPt, s.hPt, s.pageBreakTrigger = f.w, f.h, f.wPt, f.hPt, f.pageBreakTrigger
+	s.curOrientation, s.curPageSize = f.curOrientation, f.curPageSize
+	if f.pageStates != nil {
+		s.pageStates = make(map[int]pageStateType, len(f.pageStates))
+		for n, ps := range f.pageStates {
+			s.pageStates[n] = ps
+		}
+	}
+	s.lMargin, s.tMargin, s.rMargin, s.bMargin = f.lMargin, f.tMargin, f.rMargin, f.bMargin
+	s.cMargin, s.cRadius, s.cCornersStr = f.cMargin, f.cRadius, f.cCornersStr
+	s.autoPageBreak, s.acceptPageBreak = f.autoPageBreak, f.acceptPageBreak
+	s.headerFnc, s.footerFnc = f.headerFnc, f.footerFnc
+	s.inHeader, s.inFooter = f.inHeader, f.inFooter
+	s.fontFamily, s.fontStyle, s.underline = f.fontFamily, f.fontStyle, f.underline
+	s.currentFont = f.currentFont
+	s.fontSizePt, s.fontSize, s.ws = f.fontSizePt, f.fontSize, f.ws
+	s.lineWidth, s.capStyle, s.joinStyle = f.lineWidth, f.capStyle, f.joinStyle
+	s.dashArray, s.dashPhase = f.dashArray, f.dashPhase
+	s.alpha, s.blendMode = f.alpha, f.blendMode
+	s.draw, s.fill, s.text, s.colorFlag = f.color.draw, f.color.fill, f.color.text, f.colorFlag
+	s.clipNest, s.transformNest, s.layerActive = f.clipNest, f.transformNest, f.layer.currentLayer
+	s.measure = f.measure
+	return
+}
+
+// restoreLayout restores the layout state s recorded by saveLayout()
+func (f *Fpdf) restoreLayout(s measureLayoutType) {
+	f.page, f.openPage = s.page, s.openPage
+	f.x, f.y, f.lasth = s.x, s.y, s.lasth
+	f.w, f.h, f.wPt, f.hPt, f.pageBreakTrigger = s.w, s.h, s.wPt, s.hPt, s.pageBreakTrigger
+	f.curOrientation, f.curPageSize = s.curOrientation, s.curPageSize
+	f.pageStates = s.pageStates
+	f.lMargin, f.tMargin, f.rMargin, f.bMargin = s.lMargin, s.tMargin, s.rMargin, s.bMargin
+	f.cMargin, f.cRadius, f.cCornersStr = s.cMargin, s.cRadius, s.cCornersStr
+	f.autoPageBreak, f.acceptPageBreak = s.autoPageBreak, s.acceptPageBreak
+	f.headerFnc, f.footerFnc = s.headerFnc, s.footerFnc
+	f.inHeader, f.inFooter = s.inHeader, s.inFooter
+	f.fontFamily, f.fontStyle, f.underline = s.fontFamily, s.fontStyle, s.underline
+	f.currentFont = s.currentFont
+	f.fontSizePt, f.fontSize, f.ws = s.fontSizePt, s.fontSize, s.ws
+	f.lineWidth, f.capStyle, f.joinStyle = s.lineWidth, s.capStyle, s.joinStyle
+	f.dashArray, f.dashPhase = s.dashArray, s.dashPhase
+	f.alpha, f.blendMode = s.alpha, s.blendMode
+	f.color.draw, f.color.fill, f.color.text, f.colorFlag = s.draw, s.fill, s.text, s.colorFlag
+	f.clipNest, f.transformNest, f.layer.currentLayer = s.clipNest, s.transformNest, s.layerActive
+	f.measure = s.measure
+}
+
+// measureRect extends the bounding box of the current measurement with the
+// specified rectangle
+func (f *Fpdf) measureRect(x, y, w, h float64) {
+	if f.measure == nil || f.inHeader || f.inFooter {
+		return
+	}
+	x0, x1 := math.Min(x, x+w), math.Max(x, x+w)
+	y0, y1 := math.Min(y, y+h), math.Max(y, y+h)
+	rec := f.measure
+	if rec.empty {
+		rec.min = PointType{x0, y0}
+		rec.max = PointType{x1, y1}
+		rec.empty = false
+		return
+	}
+	rec.min.X = math.Min(rec.min.X, x0)
+	rec.min.Y = math.Min(rec.min.Y, y0)
+	rec.max.X = math.Max(rec.max.X, x1)
+	rec.max.Y = math.Max(rec.max.Y, y1)
+}
+
+// measurePoints extends the bounding box of the current measurement with the
+// smallest rectangle that contains all of the specified points
+func (f *Fpdf) measurePoints(points []PointType) {
+	if f.measure == nil || len(points) == 0 {
+		return
+	}
+	min, max := points[0], points[0]
+	for _, pt := range points[1:] {
+		min.X = math.Min(min.X, pt.X)
+		min.Y = math.Min(min.Y, pt.Y)
+		max.X = math.Max(max.X, pt.X)
+		max.Y = math.Max(max.Y, pt.Y)
+	}
+	f.measureRect(min.X, min.Y, max.X-min.X, max.Y-min.Y)
+}
+
+// measurePage simulates a page break while measuring
+func (f *Fpdf) measurePage(orientationStr string, size SizeType) {
+	f.measure.pages++
+	if orientationStr == "" {
+		orientationStr = f.defOrientation
+	} else {
+		orientationStr = strings.ToUpper(orientationStr[0:1])
+	}
+	if orientationStr == "P" {
+		f.w, f.h = size.Wd, size.Ht
+	} else {
+		f.w, f.h = size.Ht, size.Wd
+	}
+	f.wPt = f.w * f.k
+	f.hPt = f.h * f.k
+	f.pageBreakTrigger = f.h - f.bMargin
+	f.curOrientation = orientationStr
+	f.curPageSize = size
+	f.x = f.lMargin
+	f.y = f.tMargin
+	if f.headerFnc != nil {
+		f.inHeader = true
+		f.headerFnc()
+		f.inHeader = false
+	}
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// pageStateType records the state of a page at the time it was last left
+type pageStateType struct {
+	x, y      float64 // current position, valid if pos is true
+	pos       bool
+	alpha     float64 // transparency in effect at end of page content
+	blendMode string
+}
+
+// PageCount returns the number of pages in the document. Unlike PageNo(), the
+// value is not affected by SetPage().
+func (f *Fpdf) PageCount() int {
+	return len(f.pages) - 1
+}
+
+// SetPage makes the specified page, which must already have been added to the
+// document, the target of subsequent output. This allows content to be added
+// to an earlier page, for example a summary that is known only after later
+// pages have been generated. pageNum ranges from 1 to PageCount(). Calling
+// SetPage() with the number of the last page returns to it.
+//
+// The current font, colors, line width and other drawing attributes carry over
+// to the selected page. When returning to a page that was left with a call to
+// SetPage(), the current position is restored; otherwise it is set to the
+// top-left corner of the page according to the left and top margins. Any open
+// layer is ended. The header and footer functions are not called. Automatic
+// page breaks occur only while the page most recently added with AddPage()
+// is selected. AddPage() and Close() return to that page, wherever it has
+// been moved, to output its footer before proceeding.
+//
+// Clipping and transformation operations must be ended before this method is
+// called.
+//
+// The SetPage() example demonstrates this method.
+func (f *Fpdf) SetPage(pageNum int) {
+	if f.err != nil {
+		return
+	}
+	if f.state != 2 {
+		f.err = fmt.Errorf("page cannot be selected unless document is open")
+		return
+	}
+	if pageNum < 1 || pageNum > f.PageCount() {
+		f.err = fmt.Errorf("page %d does not exist", pageNum)
+		return
+	}
+	if f.clipNest > 0 || f.transformNest > 0 {
+		f.err = fmt.Errorf("clip and transformation procedures must be ended before selecting a page")
+		return
+	}
+	if pageNum == f.page {
+		return
+	}
+	f.EndLayer()
+	if f.pageStates == nil {
+		f.pageStates = make(map[int]pageStateType)
+	}
+	f.pageStates[f.page] = pageStateType{x: f.x, y: f.y, pos: true,
+		alpha: f.alpha, blendMode: f.blendMode}
+	ps, ok := f.pageStates[pageNum]
+	if !ok {
+		ps = pageStateType{alpha: 1, blendMode: "Normal"}
+	}
+	f.page = pageNum
+	f.setpagedims(pageNum)
+	f.outpagestate(ps.alpha, ps.blendMode)
+	if ps.pos {
+		f.x, f.y = ps.x, ps.y
+	} else {
+		f.x, f.y = f.lMargin, f.tMargin
+	}
+}
+
+// pageBreakAllowed returns false if automatic page breaks are suppressed
+// because a page other than the one most recently added has been selected
+// with SetPage()
+func (f *Fpdf) pageBreakAllowed() bool {
+	return f.page == f.openPage
+}
+
+// pagedims returns the width and height of the specified page in user units
+// taking its orientation into account
+func (f *Fpdf) pagedims(pageNum int) (w, h float64) {
+	sz, ok := f.pageSizes[pageNum]
+	if ok {
+		w, h = sz.Wd/f.k, sz.Ht/f.k
+	} else if f.defOrientation == "P" {
+		w, h = f.defPageSize.Wd, f.defPageSize.Ht
+	} else {
+		w, h = f.defPageSize.Ht, f.defPageSize.Wd
+	}
+	return
+}
+
+// setpagedims sets the dimensions of the current page to those of the
+// specified page
+func (f *Fpdf) setpagedims(pageNum int) {
+	f.w, f.h = f.pagedims(pageNum)
+	f.wPt, f.hPt = f.w*f.k, f.h*f.k
+	f.pageBreakTrigger = f.h - f.bMargin
+}
+
+// outpagestate outputs the current drawing attributes to the current page so
+// that its content stream is consistent with them. alpha and blendModeStr
+// specify the transparency in effect at the end of the existing content.
+func (f *Fpdf) outpagestate(alpha float64, blendModeStr string) {
+	familyStr := f.fontFamily
+	style := f.fontStyle
+	if f.underline {
+		style += "U"
+	}
+	f.outf("%d J", f.capStyle)
+	f.outf("%d j", f.joinStyle)
+	f.outf("%.2f w", f.lineWidth*f.k)
+	f.outputDashPattern()
+	if familyStr != "" {
+		f.fontFamily = ""
+		f.SetFont(familyStr, style, f.fontSizePt)
+	}
+	f.out(f.color.draw.str)
+	f.out(f.color.fill.str)
+	trackedAlpha, trackedBlendModeStr := f.alpha, f.blendMode
+	f.alpha, f.blendMode = alpha, blendModeStr
+	f.SetAlpha(trackedAlpha, trackedBlendModeStr)
+}
+
+// MovePage moves page from so that it becomes page to. Pages between the two
+// positions are renumbered accordingly. Internal links, bookmarks, anchors,
+// index entries and page sizes follow the pages they refer to. If the current
+// page is moved, it remains the current page under its new number. The
+// footer of the page most recently added is output when the next page is
+// added or the document is closed, regardless of where the page has moved.
+//
+// The MovePage() example demonstrates this method.
+func (f *Fpdf) MovePage(from, to int) {
+	if !f.pageop(from) || !f.pageop(to) {
+		return
+	}
+	order := make([]int, 0, len(f.pages))
+	for n := 0; n < len(f.pages); n++ {
+		if n != from {
+			order = append(order, n)
+		}
+	}
+	order = append(order[:to], append([]int{from}, order[to:]...)...)
+	f.remappages(order)
+}
+
+// InsertPageBefore inserts a new page with the default orientation and size
+// before page pageNum and makes it the current page, as with SetPage(). The
+// current position is set to the top-left corner of the page according to the
+// left and top margins. The header and footer functions are not called for
+// the inserted page. Pages from pageNum onward are renumbered accordingly;
+// see MovePage() for details. A page can be appended to the document with
+// AddPage().
+//
+// The MovePage() example demonstrates this method.
+func (f *Fpdf) InsertPageBefore(pageNum int) {
+	if !f.pageop(pageNum) {
+		return
+	}
+	if f.clipNest > 0 || f.transformNest > 0 {
+		f.err = fmt.Errorf("clip and transformation procedures must be ended before inserting a page")
+		return
+	}
+	count := f.PageCount()
+	f.pages = append(f.pages, bytes.NewBufferString(""))
+	f.pageLinks = append(f.pageLinks, make([]linkType, 0, 0))
+	f.MovePage(count+1, pageNum)
+	f.SetPage(pageNum)
+}
+
+// DeletePage removes page pageNum from the document. Pages that follow it are
+// renumbered accordingly; see MovePage() for details. Internal links and
+// bookmarks that refer to the deleted page are redirected to the top of the
+// page that followed it, or to the new last page if the last page was
+// deleted. Anchors are redirected in the same way and index entries that
+// refer to the deleted page are removed. The current page cannot be deleted,
+// nor can the only page of a document. If the page most recently added is
+// deleted, its footer is not output.
+//
+// The MovePage() example demonstrates this method.
+func (f *Fpdf) DeletePage(pageNum int) {
+	if !f.pageop(pageNum) {
+		return
+	}
+	if pageNum == f.page {
+		f.err = fmt.Errorf("current page %d cannot be deleted", pageNum)
+		return
+	}
+	order := make([]int, 0, len(f.pages))
+	for n := 0; n < len(f.pages); n++ {
+		if n != pageNum {
+			order = append(order, n)
+		}
+	}
+	f.remappages(order)
+}
+
+// pageop returns true if the document is in a state that permits pages to be
+// rearranged and pageNum is a valid page number
+func (f *Fpdf) pageop(pageNum int) bool {
+	if f.err != nil {
+		return false
+	}
+	if f.state != 2 {
+		f.err = fmt.Errorf("pages cannot be rearranged unless document is open")
+		return false
+	}
+	if pageNum < 1 || pageNum > f.PageCount() {
+		f.err = fmt.Errorf("page %d does not exist", pageNum)
+		return false
+	}
+	return true
+}
+
+// remappages rearranges the pages of the document. order lists the old page
+// numbers in their new order, beginning with the unused element zero. Pages
+// that are not listed are deleted.
+func (f *Fpdf) remappages(order []int) {
+	// newNum maps old page numbers to new page numbers; references to deleted
+	// pages are redirected to the next remaining page
+	newNum := make([]int, len(f.pages))
+	for n, old := range order {
+		newNum[old] = n
+	}
+	deleted := make([]bool, len(f.pages))
+	next := 0
+	for n := len(newNum) - 1; n > 0; n-- {
+		if newNum[n] == 0 {
+			deleted[n] = true
+			newNum[n] = next
+		} else {
+			next = newNum[n]
+		}
+	}
+	last := len(order) - 1
+	for n := range newNum {
+		if n > 0 && newNum[n] == 0 {
+			newNum[n] = last
+		}
+	}
+	pages := make([]*bytes.Buffer, len(order))
+	pageLinks := make([][]linkType, len(order))
+	pageSizes := make(map[int]SizeType)
+	for n, old := range order {
+		pages[n] = f.pages[old]
+		pageLinks[n] = f.pageLinks[old]
+		if sz, ok := f.pageSizes[old]; ok {
+			pageSizes[n] = sz
+		}
+	}
+	f.pages, f.pageLinks, f.pageSizes = pages, pageLinks, pageSizes
+	for j := range f.links {
+		if p := f.links[j].page; p > 0 && p < len(newNum) {
+			if deleted[p] {
+				f.links[j].y = 0
+			}
+			f.links[j].page = newNum[p]
+		}
+	}
+	for j := range f.outlines {
+		if p := f.outlines[j].p; p > 0 && p < len(newNum) {
+			if deleted[p] {
+				f.outlines[j].y = 0
+			}
+			f.outlines[j].p = newNum[p]
+		}
+	}
+	index := f.index[:0]
+	for _, entry := range f.index {
+		if !deleted[entry.page] {
+			entry.page = newNum[entry.page]
+			index = append(index, entry)
+		}
+	}
+	f.index = index
+	for nameStr, p := range f.anchors {
+		f.anchors[nameStr] = newNum[p]
+	}
+	if f.pageStates != nil {
+		pageStates := make(map[int]pageStateType)
+		for p, ps := range f.pageStates {
+			if !deleted[p] {
+				pageStates[newNum[p]] = ps
+			}
+		}
+		f.pageStates = pageStates
+	}
+	f.page = newNum[f.page]
+	if deleted[f.openPage] {
+		f.openPage = 0
+	} else {
+		f.openPage = newNum[f.openPage]
+	}
+}
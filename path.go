@@ -0,0 +1,401 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"math"
+)
+
+// pathSegType is a segment of a path. op is 'M' (move to pts[0]), 'L' (line
+// to pts[0]), 'C' (cubic Bézier curve with control points pts[0] and pts[1]
+// to pts[2]) or 'Z' (close subpath).
+type pathSegType struct {
+	op  byte
+	pts [3]PointType
+}
+
+// PathType records the segments of a path so that its geometry can be
+// examined and transformed before it is drawn. The zero value is an empty
+// path. Segments are added with methods that correspond to the path methods
+// of Fpdf, such as MoveTo() and LineTo(), using coordinates in the unit of
+// measure specified in New(). Quadratic curves and arcs are recorded as cubic
+// Bézier curves, so transformations are applied exactly.
+//
+// The transformation methods and Reverse() return a new path and leave the
+// receiver unchanged. A path is drawn with Fpdf.Path() or added to the
+// current path of the page with Fpdf.AppendPath(), for example within
+// ClipPath(). A path can be drawn any number of times.
+type PathType struct {
+	segs     []pathSegType
+	cur      PointType // current point
+	start    PointType // start point of current subpath
+	hasStart bool      // true if a subpath has been started
+}
+
+func (p *PathType) add(op byte, pts ...PointType) {
+	seg := pathSegType{op: op}
+	copy(seg.pts[:], pts)
+	p.segs = append(p.segs, seg)
+}
+
+// MoveTo begins a new subpath at (x, y).
+func (p *PathType) MoveTo(x, y float64) {
+	p.add('M', PointType{x, y})
+	p.cur, p.start, p.hasStart = PointType{x, y}, PointType{x, y}, true
+}
+
+// LineTo adds a straight line from the current point to (x, y). If the path
+// has no current point, a subpath is begun at (x, y) instead.
+func (p *PathType) LineTo(x, y float64) {
+	if !p.hasStart {
+		p.MoveTo(x, y)
+		return
+	}
+	p.add('L', PointType{x, y})
+	p.cur = PointType{x, y}
+}
+
+// CurveTo adds a quadratic Bézier curve from the current point to (x, y)
+// with the control point (cx, cy).
+func (p *PathType) CurveTo(cx, cy, x, y float64) {
+	if !p.hasStart {
+		p.MoveTo(p.cur.X, p.cur.Y)
+	}
+	c := p.cur
+	p.CurveBezierCubicTo(c.X+2*(cx-c.X)/3, c.Y+2*(cy-c.Y)/3, x+2*(cx-x)/3, y+2*(cy-y)/3, x, y)
+}
+
+// CurveBezierCubicTo adds a cubic Bézier curve from the current point to (x,
+// y) with the control points (cx0, cy0) and (cx1, cy1).
+func (p *PathType) CurveBezierCubicTo(cx0, cy0, cx1, cy1, x, y float64) {
+	if !p.hasStart {
+		p.MoveTo(p.cur.X, p.cur.Y)
+	}
+	p.add('C', PointType{cx0, cy0}, PointType{cx1, cy1}, PointType{x, y})
+	p.cur = PointType{x, y}
+}
+
+// ArcTo adds an elliptical arc centered at (x, y) with the horizontal and
+// vertical radii rx and ry. The arguments are interpreted as they are by
+// Fpdf.ArcTo(): angles are in degrees, measured counter-clockwise from the 3
+// o'clock position, and the ellipse is rotated by degRotate. If the start of
+// the arc is not at the current point, a connecting line is added.
+func (p *PathType) ArcTo(x, y, rx, ry, degRotate, degStart, degEnd float64) {
+	sin, cos := math.Sincos(degRotate * math.Pi / 180)
+	// pt returns the point of the ellipse at angle t and the derivative there
+	pt := func(t float64) (PointType, PointType) {
+		st, ct := math.Sincos(t)
+		ox, oy := rx*ct, -ry*st
+		dx, dy := -rx*st, -ry*ct
+		return PointType{x + ox*cos + oy*sin, y - ox*sin + oy*cos},
+			PointType{dx*cos + dy*sin, -dx*sin + dy*cos}
+	}
+	segments := int(math.Ceil(math.Abs(degEnd-degStart) / 60))
+	if segments < 2 {
+		segments = 2
+	}
+	t0 := degStart * math.Pi / 180
+	dt := (degEnd - degStart) * math.Pi / 180 / float64(segments)
+	kappa := 4 * math.Tan(dt/4) / 3
+	p0, d0 := pt(t0)
+	if !p.hasStart {
+		p.MoveTo(p0.X, p0.Y)
+	} else if p.cur != p0 {
+		p.LineTo(p0.X, p0.Y)
+	}
+	for j := 1; j <= segments; j++ {
+		p1, d1 := pt(t0 + float64(j)*dt)
+		p.CurveBezierCubicTo(p0.X+kappa*d0.X, p0.Y+kappa*d0.Y, p1.X-kappa*d1.X, p1.Y-kappa*d1.Y,
+			p1.X, p1.Y)
+		p0, d0 = p1, d1
+	}
+}
+
+// ClosePath closes the current subpath with a straight line to its start
+// point, if necessary. The current point becomes the start point.
+func (p *PathType) ClosePath() {
+	if p.hasStart {
+		p.add('Z')
+		p.cur = p.start
+	}
+}
+
+// Empty returns true if the path has no segments.
+func (p PathType) Empty() bool {
+	return len(p.segs) == 0
+}
+
+// Transform returns a copy of the path with each point (x, y) replaced by (A
+// * x + C * y + E, B * x + D * y + F). Unlike the matrix passed to
+// Fpdf.Transform(), which operates in points from the lower left corner of
+// the page, m operates on the coordinates of the path, in the unit of
+// measure specified in New() with y increasing downward.
+func (p PathType) Transform(m TransformMatrix) PathType {
+	apply := func(pt PointType) PointType {
+		return PointType{m.A*pt.X + m.C*pt.Y + m.E, m.B*pt.X + m.D*pt.Y + m.F}
+	}
+	q := p
+	q.segs = make([]pathSegType, len(p.segs))
+	for j, seg := range p.segs {
+		for k := range seg.pts {
+			seg.pts[k] = apply(seg.pts[k])
+		}
+		q.segs[j] = seg
+	}
+	q.cur, q.start = apply(p.cur), apply(p.start)
+	return q
+}
+
+// Translate returns a copy of the path moved by tx horizontally and ty
+// vertically.
+func (p PathType) Translate(tx, ty float64) PathType {
+	return p.Transform(TransformMatrix{A: 1, D: 1, E: tx, F: ty})
+}
+
+// Scale returns a copy of the path scaled by the factors sx and sy about the
+// point (x, y).
+func (p PathType) Scale(sx, sy, x, y float64) PathType {
+	return p.Transform(TransformMatrix{A: sx, D: sy, E: x - sx*x, F: y - sy*y})
+}
+
+// Rotate returns a copy of the path rotated counter-clockwise by angle
+// degrees about the point (x, y).
+func (p PathType) Rotate(angle, x, y float64) PathType {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	return p.Transform(TransformMatrix{A: cos, B: -sin, C: sin, D: cos,
+		E: x - cos*x - sin*y, F: y + sin*x - cos*y})
+}
+
+// Reverse returns a copy of the path that traverses the same segments in the
+// opposite direction. Subpaths are reversed individually and their order is
+// reversed as well. Reversing a subpath changes its winding direction, which
+// matters when the path is filled with the nonzero winding number rule: a
+// subpath that runs opposite to the subpath enclosing it leaves a hole.
+func (p PathType) Reverse() PathType {
+	// Split the path into subpaths. A segment that follows ClosePath() without
+	// an intervening MoveTo() begins a subpath at the start of the closed one.
+	type subpathType struct {
+		start  PointType
+		segs   []pathSegType
+		closed bool
+	}
+	var subs []*subpathType
+	var sub *subpathType
+	var start PointType
+	for _, seg := range p.segs {
+		switch seg.op {
+		case 'M':
+			start = seg.pts[0]
+			sub = &subpathType{start: start}
+			subs = append(subs, sub)
+		case 'Z':
+			if sub != nil {
+				sub.closed = true
+			}
+			sub = nil
+		default:
+			if sub == nil {
+				sub = &subpathType{start: start}
+				subs = append(subs, sub)
+			}
+			sub.segs = append(sub.segs, seg)
+		}
+	}
+	// The end point of each segment is the start point of the next one
+	endPt := func(seg pathSegType) PointType {
+		if seg.op == 'C' {
+			return seg.pts[2]
+		}
+		return seg.pts[0]
+	}
+	var q PathType
+	for j := len(subs) - 1; j >= 0; j-- {
+		sub := subs[j]
+		pt := sub.start
+		if n := len(sub.segs); n > 0 {
+			pt = endPt(sub.segs[n-1])
+		}
+		q.MoveTo(pt.X, pt.Y)
+		for k := len(sub.segs) - 1; k >= 0; k-- {
+			to := sub.start
+			if k > 0 {
+				to = endPt(sub.segs[k-1])
+			}
+			if sub.segs[k].op == 'C' {
+				c := sub.segs[k].pts
+				q.CurveBezierCubicTo(c[1].X, c[1].Y, c[0].X, c[0].Y, to.X, to.Y)
+			} else {
+				q.LineTo(to.X, to.Y)
+			}
+		}
+		if sub.closed {
+			q.ClosePath()
+		}
+	}
+	return q
+}
+
+// Bounds returns the smallest rectangle that contains the path, given by its
+// upper left corner and its size. Curves are measured exactly rather than
+// by their control points. The rectangle of an empty path is zero.
+func (p PathType) Bounds() (corner PointType, size SizeType) {
+	x0, y0 := math.Inf(1), math.Inf(1)
+	x1, y1 := math.Inf(-1), math.Inf(-1)
+	add := func(pt PointType) {
+		x0, y0 = math.Min(x0, pt.X), math.Min(y0, pt.Y)
+		x1, y1 = math.Max(x1, pt.X), math.Max(y1, pt.Y)
+	}
+	var cur, start PointType
+	for _, seg := range p.segs {
+		switch seg.op {
+		case 'M':
+			cur, start = seg.pts[0], seg.pts[0]
+			add(cur)
+		case 'L':
+			cur = seg.pts[0]
+			add(cur)
+		case 'Z':
+			cur = start
+		case 'C':
+			c := seg.pts
+			for _, t := range append(cubicExtremes(cur.X, c[0].X, c[1].X, c[2].X),
+				cubicExtremes(cur.Y, c[0].Y, c[1].Y, c[2].Y)...) {
+				add(PointType{cubicPoint(t, cur.X, c[0].X, c[1].X, c[2].X),
+					cubicPoint(t, cur.Y, c[0].Y, c[1].Y, c[2].Y)})
+			}
+			cur = c[2]
+			add(cur)
+		}
+	}
+	if x0 > x1 {
+		return
+	}
+	return PointType{x0, y0}, SizeType{x1 - x0, y1 - y0}
+}
+
+// Length returns the total length of the segments of the path, including the
+// lines that close subpaths.
+func (p PathType) Length() (length float64) {
+	var cur, start PointType
+	for _, seg := range p.segs {
+		switch seg.op {
+		case 'M':
+			cur, start = seg.pts[0], seg.pts[0]
+		case 'L':
+			length += math.Hypot(seg.pts[0].X-cur.X, seg.pts[0].Y-cur.Y)
+			cur = seg.pts[0]
+		case 'C':
+			length += cubicLength(cur, seg.pts[0], seg.pts[1], seg.pts[2], 0)
+			cur = seg.pts[2]
+		case 'Z':
+			length += math.Hypot(start.X-cur.X, start.Y-cur.Y)
+			cur = start
+		}
+	}
+	return
+}
+
+// cubicLength returns the length of the cubic Bézier curve from p0 to p3
+// with control points p1 and p2. The curve is subdivided until the length of
+// its control polygon is close to the length of its chord.
+func cubicLength(p0, p1, p2, p3 PointType, depth int) float64 {
+	dist := func(a, b PointType) float64 {
+		return math.Hypot(b.X-a.X, b.Y-a.Y)
+	}
+	chord := dist(p0, p3)
+	poly := dist(p0, p1) + dist(p1, p2) + dist(p2, p3)
+	if depth >= 16 || poly-chord <= 1e-6*math.Max(poly, 1) {
+		return (chord + poly) / 2
+	}
+	mid := func(a, b PointType) PointType {
+		return PointType{(a.X + b.X) / 2, (a.Y + b.Y) / 2}
+	}
+	p01, p12, p23 := mid(p0, p1), mid(p1, p2), mid(p2, p3)
+	p012, p123 := mid(p01, p12), mid(p12, p23)
+	m := mid(p012, p123)
+	return cubicLength(p0, p01, p012, m, depth+1) + cubicLength(m, p123, p23, p3, depth+1)
+}
+
+// cubicPoint returns a coordinate of the point at parameter t of the cubic
+// Bézier curve with coordinates p0 to p3
+func cubicPoint(t, p0, p1, p2, p3 float64) float64 {
+	u := 1 - t
+	return u*u*u*p0 + 3*u*u*t*p1 + 3*u*t*t*p2 + t*t*t*p3
+}
+
+// cubicExtremes returns the parameters between 0 and 1 at which a
+// coordinate of the cubic Bézier curve with coordinates p0 to p3 has a local
+// extreme
+func cubicExtremes(p0, p1, p2, p3 float64) (list []float64) {
+	// The derivative is a*t*t + b*t + c
+	a := 3 * (-p0 + 3*p1 - 3*p2 + p3)
+	b := 6 * (p0 - 2*p1 + p2)
+	c := 3 * (p1 - p0)
+	add := func(t float64) {
+		if t > 0 && t < 1 {
+			list = append(list, t)
+		}
+	}
+	if math.Abs(a) < 1e-12 {
+		if b != 0 {
+			add(-c / b)
+		}
+		return
+	}
+	if d := b*b - 4*a*c; d >= 0 {
+		sq := math.Sqrt(d)
+		add((-b + sq) / (2 * a))
+		add((-b - sq) / (2 * a))
+	}
+	return
+}
+
+// AppendPath adds the segments of path p to the current path of the page,
+// as if they had been added with MoveTo(), LineTo(), CurveBezierCubicTo()
+// and ClosePath(). The path is then painted with DrawPath(), or it can be
+// used to clip within the function passed to ClipPath().
+//
+// The Path() example demonstrates this method.
+func (f *Fpdf) AppendPath(p PathType) {
+	for _, seg := range p.segs {
+		switch seg.op {
+		case 'M':
+			f.MoveTo(seg.pts[0].X, seg.pts[0].Y)
+		case 'L':
+			f.LineTo(seg.pts[0].X, seg.pts[0].Y)
+		case 'C':
+			c := seg.pts
+			f.CurveBezierCubicTo(c[0].X, c[0].Y, c[1].X, c[1].Y, c[2].X, c[2].Y)
+		case 'Z':
+			f.ClosePath()
+		}
+	}
+}
+
+// Path draws path p. styleStr is interpreted as it is by DrawPath(), so "F*"
+// and "FD*" fill the path with the even-odd rule.
+//
+// The Path() example demonstrates this method.
+func (f *Fpdf) Path(p PathType, styleStr string) {
+	if p.Empty() {
+		return
+	}
+	corner, size := p.Bounds()
+	f.measureRect(corner.X, corner.Y, size.Wd, size.Ht)
+	f.AppendPath(p)
+	f.DrawPath(styleStr)
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// patternType is a tiling pattern whose cell is recorded as a content
+// stream, or a shading pattern that paints an entry of the gradient list
+type patternType struct {
+	content *bytes.Buffer
+	size    SizeType
+	shading int // position in gradientList, or zero for a tiling pattern
+	objNum  int
+}
+
+// AddPattern defines a tiling pattern, such as a hatch or a texture, that
+// can be used in place of a color with SetDrawPattern(), SetFillPattern() and
+// SetTextPattern(). The pattern cell is wd by ht in the unit of measure
+// specified in New(). It is drawn by fn, which calls the drawing methods of
+// the document with the upper left corner of the cell at the upper left
+// corner of the page. The cell is repeated horizontally and vertically
+// without gaps, and content outside of it is clipped. The graphics settings
+// of the document, such as colors and line width, are in effect when fn is
+// called, and are restored afterward. Transparency, gradients and other
+// patterns can be used within the cell. No page breaks occur while fn is
+// called.
+//
+// The return value is an integer ID that is passed to the methods that
+// select the pattern. Cells are aligned with the lower left corner of the
+// page, regardless of where the pattern is used.
+func (f *Fpdf) AddPattern(wd, ht float64, fn func()) (patternID int) {
+	if f.err != nil {
+		return
+	}
+	if wd <= 0 || ht <= 0 {
+		f.err = fmt.Errorf("pattern cell dimensions must be greater than zero")
+		return
+	}
+	// The cell is drawn at the top of the page and moved to the origin of
+	// pattern space. Its graphics state is not inherited from where the
+	// pattern is used, so the current settings are written first.
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "1 0 0 1 0 %.5f cm\n%.2f w\n", (ht-f.h)*f.k, f.lineWidth*f.k)
+	for _, str := range []string{f.color.draw.str, f.color.fill.str} {
+		if str != "" {
+			fmt.Fprintf(&content, "%s\n", str)
+		}
+	}
+	content.Write(f.captureContent(fn).Bytes())
+	if f.err != nil {
+		return
+	}
+	patternID = len(f.patternList)
+	f.patternList = append(f.patternList, patternType{content: &content,
+		size: SizeType{Wd: wd, Ht: ht}})
+	return
+}
+
+// patternValue returns clr with its color replaced by the pattern
+// patternID. The pattern color space is selected with csStr and the pattern
+// with scnStr. The RGB and CMYK components of clr are retained as an
+// approximation.
+func (f *Fpdf) patternValue(clr clrType, patternID int, csStr, scnStr string) (clrType, bool) {
+	if patternID < 1 || patternID >= len(f.patternList) {
+		f.err = fmt.Errorf("pattern %d has not been defined", patternID)
+		return clr, false
+	}
+	clr.str = sprintf("/Pattern %s /P%d %s", csStr, patternID, scnStr)
+	return clr, true
+}
+
+// SetDrawPattern sets the current draw color to the pattern patternID, which
+// must have been defined with AddPattern() or with one of the gradient and
+// mesh methods, such as AddLinearGradient(). Lines and the outlines of
+// shapes are painted with the pattern until another draw color is set.
+//
+// The AddPattern() example demonstrates this method.
+func (f *Fpdf) SetDrawPattern(patternID int) {
+	if f.err != nil {
+		return
+	}
+	clr, ok := f.patternValue(f.color.draw, patternID, "CS", "SCN")
+	if !ok {
+		return
+	}
+	f.color.draw = clr
+	if f.page > 0 {
+		f.out(f.color.draw.str)
+	}
+}
+
+// SetFillPattern sets the current fill color to the pattern patternID, which
+// must have been defined with AddPattern() or with one of the gradient and
+// mesh methods, such as AddLinearGradient(). Filled shapes, such as those
+// drawn with Rect(), Polygon(), Beziergon() and Ellipse(), and cell
+// backgrounds are painted with the pattern until another fill color is set.
+//
+// The AddPattern() example demonstrates this method.
+func (f *Fpdf) SetFillPattern(patternID int) {
+	if f.err != nil {
+		return
+	}
+	clr, ok := f.patternValue(f.color.fill, patternID, "cs", "scn")
+	if !ok {
+		return
+	}
+	f.color.fill = clr
+	f.colorFlag = f.color.fill.str != f.color.text.str
+	if f.page > 0 {
+		f.out(f.color.fill.str)
+	}
+}
+
+// SetTextPattern sets the current text color to the pattern patternID, which
+// must have been defined with AddPattern() or with one of the gradient and
+// mesh methods, such as AddLinearGradient().
+//
+// The AddPattern() example demonstrates this method.
+func (f *Fpdf) SetTextPattern(patternID int) {
+	if f.err != nil {
+		return
+	}
+	clr, ok := f.patternValue(f.color.text, patternID, "cs", "scn")
+	if !ok {
+		return
+	}
+	f.color.text = clr
+	f.colorFlag = f.color.fill.str != f.color.text.str
+}
+
+func (f *Fpdf) patternPutPatterns() {
+	for j := 1; j < len(f.patternList); j++ {
+		if sh := f.patternList[j].shading; sh > 0 {
+			f.newobj()
+			f.outf("<</Type /Pattern /PatternType 2 /Shading %d 0 R>>", f.gradientList[sh].objNum)
+			f.out("endobj")
+			f.patternList[j].objNum = f.n
+			continue
+		}
+		size := f.patternList[j].size
+		f.newobj()
+		f.out("<</Type /Pattern /PatternType 1 /PaintType 1 /TilingType 1")
+		f.outf("/BBox [0 0 %.2f %.2f] /XStep %.5f /YStep %.5f", size.Wd*f.k, size.Ht*f.k,
+			size.Wd*f.k, size.Ht*f.k)
+		f.out("/Resources 2 0 R")
+		buffer := f.patternList[j].content.Bytes()
+		if f.compress {
+			buffer = sliceCompress(buffer)
+			f.outf("/Filter /FlateDecode /Length %d>>", len(buffer))
+		} else {
+			f.outf("/Length %d>>", len(buffer))
+		}
+		f.putstream(buffer)
+		f.out("endobj")
+		f.patternList[j].objNum = f.n
+	}
+}
+
+func (f *Fpdf) patternPutResourceDict() {
+	if len(f.patternList) > 1 {
+		f.out("/Pattern <<")
+		for j := 1; j < len(f.patternList); j++ {
+			f.outf("/P%d %d 0 R", j, f.patternList[j].objNum)
+		}
+		f.out(">>")
+	}
+}
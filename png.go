@@ -115,6 +115,13 @@ func (f *Fpdf) parsepngstream(buf *bytes.Buffer, readdpi bool) (info *ImageInfoT
 		case "IEND":
 			// dbg("IEND")
 			loop = false
+		case "iCCP":
+			// Read embedded color profile
+			profile := pngICCProfile(buf.Next(n))
+			if iccComponents(profile) == imageComponents(colspace) {
+				info.icc = profile
+			}
+			_ = buf.Next(4)
 		case "pHYs":
 			// dbg("pHYs")
 			// png files theoretically support different x/y dpi
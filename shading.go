@@ -0,0 +1,265 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+// Gradients and mesh shadings in this file are used as shading patterns, so
+// that they can fill and stroke arbitrary paths and text with
+// SetFillPattern(), SetDrawPattern() and SetTextPattern().
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// shadingStops returns the stops of a gradient in the form used by
+// gradientFunction(). Positions are limited to the range 0 to 1 and made
+// non-decreasing, and the first and last colors are repeated at the ends of
+// the range if necessary.
+func (f *Fpdf) shadingStops(stops []ColorStopType) (list []gradientStopType) {
+	if len(stops) == 0 {
+		f.err = fmt.Errorf("gradient requires at least one color stop")
+		return
+	}
+	pos := 0.0
+	for _, st := range stops {
+		pos = math.Max(pos, math.Min(1, st.Pos))
+		list = append(list, gradientStopType{pos, colorValue(st.Clr.R, st.Clr.G, st.Clr.B, "", "").str})
+	}
+	if list[0].pos > 0 {
+		list = append([]gradientStopType{{0, list[0].clrStr}}, list...)
+	}
+	if last := list[len(list)-1]; last.pos < 1 {
+		list = append(list, gradientStopType{1, last.clrStr})
+	}
+	return
+}
+
+// addShadingPattern adds gr to the shadings of the document and returns the
+// ID of a pattern that paints it
+func (f *Fpdf) addShadingPattern(gr gradientType) (patternID int) {
+	if f.err != nil {
+		return
+	}
+	patternID = len(f.patternList)
+	f.patternList = append(f.patternList, patternType{shading: f.addShading(gr)})
+	return
+}
+
+// AddLinearGradient defines a linear gradient that can be used in place of a
+// color with SetFillPattern(), SetDrawPattern() and SetTextPattern(), for
+// example to fill a path drawn with DrawPath() or the glyphs of a line of
+// text. The gradient runs from (x1, y1) to (x2, y2) in the unit of measure
+// specified in New(), and colors are blended perpendicularly to this vector.
+//
+// stops specifies the colors of the gradient and their positions on the
+// vector, which range from 0 at (x1, y1) to 1 at (x2, y2). Any number of
+// stops can be given in order of increasing position; two stops at the same
+// position produce an abrupt change of color. If extendStart is true, the
+// color of the first stop is used before the start of the vector, and if
+// extendEnd is true, the color of the last stop is used beyond its end.
+// Otherwise, nothing is painted there.
+//
+// Like a tiling pattern, the gradient is positioned relative to the lower
+// left corner of the page, so the coordinates refer to pages with the height
+// of the current page. The return value is a pattern ID.
+func (f *Fpdf) AddLinearGradient(x1, y1, x2, y2 float64, stops []ColorStopType, extendStart, extendEnd bool) int {
+	gr := gradientType{tp: 2, stops: f.shadingStops(stops), extend: [2]bool{extendStart, extendEnd},
+		x1: x1 * f.k, y1: (f.h - y1) * f.k, x2: x2 * f.k, y2: (f.h - y2) * f.k}
+	return f.addShadingPattern(gr)
+}
+
+// AddRadialGradient defines a radial gradient that can be used in place of a
+// color with SetFillPattern(), SetDrawPattern() and SetTextPattern(). Colors
+// are blended from the starting circle centered at (x1, y1) with radius r1
+// to the ending circle centered at (x2, y2) with radius r2, all in the unit
+// of measure specified in New(). The starting circle is often a point with a
+// radius of zero within the ending circle.
+//
+// The stops and extend flags are interpreted as they are by
+// AddLinearGradient(), with position 0 at the starting circle and position 1
+// at the ending circle. The return value is a pattern ID.
+//
+// The AddLinearGradient() example demonstrates this method.
+func (f *Fpdf) AddRadialGradient(x1, y1, r1, x2, y2, r2 float64, stops []ColorStopType, extendStart, extendEnd bool) int {
+	if r1 < 0 || r2 < 0 {
+		f.SetErrorf("radii of radial gradient must not be negative")
+		return 0
+	}
+	gr := gradientType{tp: 3, stops: f.shadingStops(stops), extend: [2]bool{extendStart, extendEnd},
+		x1: x1 * f.k, y1: (f.h - y1) * f.k, r1: r1 * f.k, x2: x2 * f.k, y2: (f.h - y2) * f.k, r: r2 * f.k}
+	return f.addShadingPattern(gr)
+}
+
+// meshWriterType encodes the vertices of a mesh shading. Coordinates are
+// written as 32-bit fractions of the bounding box of the mesh.
+type meshWriterType struct {
+	buf                    bytes.Buffer
+	minX, maxX, minY, maxY float64
+}
+
+func newMeshWriter(f *Fpdf, pts []PointType) *meshWriterType {
+	w := &meshWriterType{minX: math.Inf(1), maxX: math.Inf(-1), minY: math.Inf(1), maxY: math.Inf(-1)}
+	for _, pt := range pts {
+		x, y := pt.X*f.k, (f.h-pt.Y)*f.k
+		w.minX, w.maxX = math.Min(w.minX, x), math.Max(w.maxX, x)
+		w.minY, w.maxY = math.Min(w.minY, y), math.Max(w.maxY, y)
+	}
+	// Avoid empty ranges
+	if w.maxX <= w.minX {
+		w.maxX = w.minX + 1
+	}
+	if w.maxY <= w.minY {
+		w.maxY = w.minY + 1
+	}
+	return w
+}
+
+func (w *meshWriterType) coord(v, min, max float64) {
+	n := uint32(math.Floor((v-min)/(max-min)*math.MaxUint32 + 0.5))
+	w.buf.Write([]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+}
+
+func (w *meshWriterType) point(f *Fpdf, pt PointType) {
+	w.coord(pt.X*f.k, w.minX, w.maxX)
+	w.coord((f.h-pt.Y)*f.k, w.minY, w.maxY)
+}
+
+func (w *meshWriterType) color(clr RGBType) {
+	c := colorValue(clr.R, clr.G, clr.B, "", "")
+	w.buf.Write([]byte{byte(c.ir), byte(c.ig), byte(c.ib)})
+}
+
+// shading returns a mesh shading of type tp with the encoded vertices
+func (w *meshWriterType) shading(tp int) gradientType {
+	return gradientType{tp: tp, data: w.buf.Bytes(),
+		decodeStr: sprintf("%.5f %.5f %.5f %.5f 0 1 0 1 0 1", w.minX, w.maxX, w.minY, w.maxY)}
+}
+
+// AddTriangleMesh defines a free-form mesh of triangles, each of which is
+// shaded by blending the colors of its vertices. The mesh can be used in
+// place of a color with SetFillPattern(), SetDrawPattern() and
+// SetTextPattern(); nothing is painted outside its triangles. Vertex
+// positions are specified in the unit of measure specified in New() and are
+// relative to the lower left corner of the page, as they are for
+// AddLinearGradient(). The return value is a pattern ID.
+//
+// The AddLinearGradient() example demonstrates this method.
+func (f *Fpdf) AddTriangleMesh(triangles [][3]MeshVertexType) int {
+	if len(triangles) == 0 {
+		f.SetErrorf("triangle mesh requires at least one triangle")
+		return 0
+	}
+	var pts []PointType
+	for _, tri := range triangles {
+		for _, v := range tri {
+			pts = append(pts, v.Pt)
+		}
+	}
+	w := newMeshWriter(f, pts)
+	for _, tri := range triangles {
+		for _, v := range tri {
+			w.buf.WriteByte(0) // each triangle is independent of the others
+			w.point(f, v.Pt)
+			w.color(v.Clr)
+		}
+	}
+	return f.addShadingPattern(w.shading(4))
+}
+
+// AddLatticeMesh defines a mesh of vertices arranged in rows of perRow
+// vertices each. Adjacent rows form a lattice of quadrilaterals, each of
+// which is divided into two triangles that are shaded by blending the colors
+// of their vertices. This is a convenient way to shade a smooth background,
+// for example of a chart. vertices holds the rows in order, so its length
+// must be a multiple of perRow, and there must be at least two rows of at
+// least two vertices each. In every other respect the mesh behaves like one
+// defined with AddTriangleMesh().
+//
+// The AddLinearGradient() example demonstrates this method.
+func (f *Fpdf) AddLatticeMesh(vertices []MeshVertexType, perRow int) int {
+	if perRow < 2 || len(vertices) < 2*perRow || len(vertices)%perRow != 0 {
+		f.SetErrorf("lattice mesh requires at least two complete rows of at least two vertices")
+		return 0
+	}
+	pts := make([]PointType, len(vertices))
+	for j, v := range vertices {
+		pts[j] = v.Pt
+	}
+	w := newMeshWriter(f, pts)
+	for _, v := range vertices {
+		w.point(f, v.Pt)
+		w.color(v.Clr)
+	}
+	gr := w.shading(5)
+	gr.perRow = perRow
+	return f.addShadingPattern(gr)
+}
+
+// addPatchMesh defines a Coons patch mesh (tp 6) or a tensor-product patch
+// mesh (tp 7)
+func (f *Fpdf) addPatchMesh(tp int, patches []MeshPatchType) int {
+	if len(patches) == 0 {
+		f.SetErrorf("patch mesh requires at least one patch")
+		return 0
+	}
+	var pts []PointType
+	for _, p := range patches {
+		pts = append(pts, p.Points[:]...)
+		if tp == 7 {
+			pts = append(pts, p.Inner[:]...)
+		}
+	}
+	w := newMeshWriter(f, pts)
+	for _, p := range patches {
+		w.buf.WriteByte(0) // each patch is independent of the others
+		for _, pt := range p.Points {
+			w.point(f, pt)
+		}
+		if tp == 7 {
+			// The interior points are ordered p11, p12, p22, p21
+			for _, pt := range p.Inner {
+				w.point(f, pt)
+			}
+		}
+		for _, clr := range p.Clrs {
+			w.color(clr)
+		}
+	}
+	return f.addShadingPattern(w.shading(tp))
+}
+
+// AddCoonsPatchMesh defines a mesh of patches, each of which is bounded by
+// four cubic Bézier curves and shaded by blending the colors of its corners.
+// The Inner fields of the patches are ignored. Curved patches are well
+// suited to smooth, organic color transitions in artwork. In every other
+// respect the mesh behaves like one defined with AddTriangleMesh().
+//
+// The AddLinearGradient() example demonstrates this method.
+func (f *Fpdf) AddCoonsPatchMesh(patches []MeshPatchType) int {
+	return f.addPatchMesh(6, patches)
+}
+
+// AddTensorPatchMesh defines a mesh of tensor-product patches. These are
+// like the patches of AddCoonsPatchMesh(), except that the four control
+// points in the Inner fields of the patches further control how colors are
+// distributed within each patch.
+//
+// The AddLinearGradient() example demonstrates this method.
+func (f *Fpdf) AddTensorPatchMesh(patches []MeshPatchType) int {
+	return f.addPatchMesh(7, patches)
+}
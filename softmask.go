@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// softMaskType is a soft mask whose content is recorded as a transparency
+// group form XObject
+type softMaskType struct {
+	subtypeStr string // Luminosity or Alpha
+	size       SizeType
+	content    *bytes.Buffer
+	objNum     int // object number of graphics state parameter dictionary
+}
+
+// AddSoftMask defines a soft mask, which varies the opacity of content
+// across the page, for example to fade the edges of a photo or to let a
+// cover illustration blend into the page with a gradient. The mask is
+// applied to subsequent content with ClipSoftMask().
+//
+// fn draws the mask with the methods of the document, using the coordinates
+// of the current page. Its output is captured rather than added to the
+// page, and no page breaks occur while it is called. The current position
+// and drawing settings are restored afterward.
+//
+// If subtypeStr is "Luminosity", masked content is opaque where the mask is
+// white, transparent where it is black or where nothing is drawn, and
+// partially transparent where it is gray. Gradients, such as those drawn
+// with LinearGradient(), are well suited for this purpose. If subtypeStr is
+// "Alpha", the opacity of the mask is used instead of its color, so content
+// is visible only where the mask is drawn, to the extent given by
+// SetAlpha(). Other values result in an error.
+//
+// The return value is an integer ID that is passed to ClipSoftMask().
+func (f *Fpdf) AddSoftMask(subtypeStr string, fn func()) (maskID int) {
+	if f.err != nil {
+		return
+	}
+	if subtypeStr != "Luminosity" && subtypeStr != "Alpha" {
+		f.err = fmt.Errorf("unrecognized soft mask type \"%s\"", subtypeStr)
+		return
+	}
+	content := f.captureContent(fn)
+	if f.err != nil {
+		return
+	}
+	if f.pdfVersion < "1.4" {
+		f.pdfVersion = "1.4"
+	}
+	maskID = len(f.softMaskList)
+	f.softMaskList = append(f.softMaskList, softMaskType{subtypeStr: subtypeStr,
+		size: SizeType{Wd: f.w, Ht: f.h}, content: content})
+	return
+}
+
+// ClipSoftMask begins an operation in which subsequent content is masked by
+// the soft mask maskID, which must have been defined with AddSoftMask(). The
+// operation is ended with ClipEnd() and can be nested with clipping
+// operations. The mask is positioned with the transformation that is in
+// effect when this method is called, so masks defined for the current page
+// line up with it outside of transformations.
+//
+// The AddSoftMask() example demonstrates this method.
+func (f *Fpdf) ClipSoftMask(maskID int) {
+	if f.err != nil {
+		return
+	}
+	if maskID < 1 || maskID >= len(f.softMaskList) {
+		f.err = fmt.Errorf("soft mask %d has not been defined", maskID)
+		return
+	}
+	f.clipNest++
+	f.outf("q /SM%d gs", maskID)
+}
+
+func (f *Fpdf) softMaskPutSoftMasks() {
+	for j := 1; j < len(f.softMaskList); j++ {
+		mask := f.softMaskList[j]
+		// Transparency group that holds the content of the mask
+		f.newobj()
+		f.out("<</Type /XObject /Subtype /Form /FormType 1")
+		f.outf("/BBox [0 0 %.2f %.2f]", mask.size.Wd*f.k, mask.size.Ht*f.k)
+		if mask.subtypeStr == "Luminosity" {
+			f.out("/Group <</S /Transparency /CS /DeviceGray>>")
+		} else {
+			f.out("/Group <</S /Transparency>>")
+		}
+		f.out("/Resources 2 0 R")
+		buffer := mask.content.Bytes()
+		if f.compress {
+			buffer = sliceCompress(buffer)
+			f.outf("/Filter /FlateDecode /Length %d>>", len(buffer))
+		} else {
+			f.outf("/Length %d>>", len(buffer))
+		}
+		f.putstream(buffer)
+		f.out("endobj")
+		// Graphics state parameter dictionary that selects the mask
+		f.newobj()
+		f.outf("<</Type /ExtGState /SMask <</Type /Mask /S /%s /G %d 0 R>>>>", mask.subtypeStr, f.n-1)
+		f.out("endobj")
+		f.softMaskList[j].objNum = f.n
+	}
+}
+
+func (f *Fpdf) softMaskPutExtGStates() {
+	for j := 1; j < len(f.softMaskList); j++ {
+		f.outf("/SM%d %d 0 R", j, f.softMaskList[j].objNum)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// spotColorType is a named ink that is written as a Separation color space.
+// Its alternate color is used by devices that cannot produce the ink.
+type spotColorType struct {
+	name   string
+	alt    clrType // CMYK alternate color at full tint
+	objNum int
+}
+
+// AddSpotColor registers a spot color, such as a Pantone ink, for use with
+// SetDrawSpotColor(), SetFillSpotColor() and SetTextSpotColor(). nameStr is
+// the name of the ink as it is known to the print shop. The cyan, magenta,
+// yellow and black components, which range from 0 to 100 percent, specify
+// the alternate color that is displayed on screen and printed by devices
+// that do not have the ink. An error occurs if nameStr is already
+// registered.
+//
+// Each spot color is written as a Separation color space in the resource
+// dictionary of the document.
+func (f *Fpdf) AddSpotColor(nameStr string, c, m, y, k int) {
+	if f.err != nil {
+		return
+	}
+	if _, ok := f.spotColorMap[nameStr]; ok {
+		f.err = fmt.Errorf("name \"%s\" is already associated with a spot color", nameStr)
+		return
+	}
+	f.spotColorMap[nameStr] = len(f.spotColorList)
+	f.spotColorList = append(f.spotColorList, spotColorType{name: nameStr,
+		alt: colorValueCMYK(c, m, y, k, "")})
+}
+
+// spotColorValue returns the color of the registered spot color nameStr at
+// the specified tint, which ranges from 0 to 100 percent. The color space is
+// selected with csStr and the tint with scnStr. The RGB and CMYK components
+// of the returned color approximate the tinted alternate color.
+func (f *Fpdf) spotColorValue(nameStr string, tint int, csStr, scnStr string) (clr clrType, ok bool) {
+	pos, ok := f.spotColorMap[nameStr]
+	if !ok {
+		f.err = fmt.Errorf("spot color \"%s\" has not been registered", nameStr)
+		return
+	}
+	tint = colorCompCMYK(tint)
+	alt := f.spotColorList[pos].alt
+	scale := func(v int) int {
+		return (v*tint + 50) / 100
+	}
+	clr = colorValueCMYK(scale(alt.ic), scale(alt.im), scale(alt.iy), scale(alt.ik), "")
+	clr.str = sprintf("/CS%d %s %.3f %s", pos, csStr, float64(tint)/100, scnStr)
+	return
+}
+
+// SetDrawSpotColor sets the current draw color to the spot color nameStr,
+// which must have been registered with AddSpotColor(), at the specified tint
+// (0 - 100 percent). In every other respect it behaves like SetDrawColor().
+//
+// The AddSpotColor() example demonstrates this method.
+func (f *Fpdf) SetDrawSpotColor(nameStr string, tint int) {
+	if f.err != nil {
+		return
+	}
+	clr, ok := f.spotColorValue(nameStr, tint, "CS", "SCN")
+	if !ok {
+		return
+	}
+	f.color.draw = clr
+	if f.page > 0 {
+		f.out(f.color.draw.str)
+	}
+}
+
+// SetFillSpotColor sets the current fill color to the spot color nameStr,
+// which must have been registered with AddSpotColor(), at the specified tint
+// (0 - 100 percent). In every other respect it behaves like SetFillColor().
+//
+// The AddSpotColor() example demonstrates this method.
+func (f *Fpdf) SetFillSpotColor(nameStr string, tint int) {
+	if f.err != nil {
+		return
+	}
+	clr, ok := f.spotColorValue(nameStr, tint, "cs", "scn")
+	if !ok {
+		return
+	}
+	f.color.fill = clr
+	f.colorFlag = f.color.fill.str != f.color.text.str
+	if f.page > 0 {
+		f.out(f.color.fill.str)
+	}
+}
+
+// SetTextSpotColor sets the current text color to the spot color nameStr,
+// which must have been registered with AddSpotColor(), at the specified tint
+// (0 - 100 percent). In every other respect it behaves like SetTextColor().
+//
+// The AddSpotColor() example demonstrates this method.
+func (f *Fpdf) SetTextSpotColor(nameStr string, tint int) {
+	if f.err != nil {
+		return
+	}
+	clr, ok := f.spotColorValue(nameStr, tint, "cs", "scn")
+	if !ok {
+		return
+	}
+	f.color.text = clr
+	f.colorFlag = f.color.fill.str != f.color.text.str
+}
+
+// spotColorName returns nameStr as a PDF name object. Characters that are
+// not regular characters are written as two-digit hexadecimal codes.
+func spotColorName(nameStr string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('/')
+	for j := 0; j < len(nameStr); j++ {
+		ch := nameStr[j]
+		if ch < '!' || ch > '~' || bytes.IndexByte([]byte("#%()/<>[]{}"), ch) >= 0 {
+			fmt.Fprintf(&buf, "#%02X", ch)
+		} else {
+			buf.WriteByte(ch)
+		}
+	}
+	return buf.String()
+}
+
+func (f *Fpdf) spotColorPutColorSpaces() {
+	for j := 1; j < len(f.spotColorList); j++ {
+		clr := f.spotColorList[j]
+		f.newobj()
+		f.outf("[/Separation %s /DeviceCMYK <<", spotColorName(clr.name))
+		f.out("/FunctionType 2 /Domain [0 1] /Range [0 1 0 1 0 1 0 1] /N 1")
+		f.outf("/C0 [0 0 0 0] /C1 [%s]>>]", clr.alt.str)
+		f.out("endobj")
+		f.spotColorList[j].objNum = f.n
+	}
+}
+
+func (f *Fpdf) spotColorPutResourceDict() {
+	if len(f.spotColorList) > 1 {
+		f.out("/ColorSpace <<")
+		for j := 1; j < len(f.spotColorList); j++ {
+			f.outf("/CS%d %d 0 R", j, f.spotColorList[j].objNum)
+		}
+		f.out(">>")
+	}
+}
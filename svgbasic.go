@@ -17,184 +17,370 @@
 package gofpdf
 
 import (
-	"encoding/xml"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"strconv"
 	"strings"
 )
 
-var pathCmdSub *strings.Replacer
-
-func init() {
-	// Handle permitted constructions like "100L200,230"
-	pathCmdSub = strings.NewReplacer(",", " ",
-		"L", " L ", "l", " l ",
-		"C", " C ", "c", " c ",
-		"M", " M ", "m", " m ")
-}
-
 // SVGBasicSegmentType describes a single curve or position segment
 type SVGBasicSegmentType struct {
 	Cmd byte // See http://www.w3.org/TR/SVG/paths.html for path command structure
 	Arg [6]float64
 }
 
-func absolutizePath(segs []SVGBasicSegmentType) {
-	var x, y float64
-	var segPtr *SVGBasicSegmentType
-	adjust := func(pos int, adjX, adjY float64) {
-		segPtr.Arg[pos] += adjX
-		segPtr.Arg[pos+1] += adjY
-	}
-	for j, seg := range segs {
-		segPtr = &segs[j]
-		if j == 0 && seg.Cmd == 'm' {
-			segPtr.Cmd = 'M'
+// svgPathScanner reads the commands and numbers of SVG path data
+type svgPathScanner struct {
+	str string
+	pos int
+}
+
+// skip advances past whitespace and commas
+func (s *svgPathScanner) skip() {
+	for s.pos < len(s.str) && strings.IndexByte(" \t\r\n\f,", s.str[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+// number reads a number. Numbers need not be separated if they can be told
+// apart, so "10-5" is read as 10 followed by -5 and "0.5.5" as 0.5 followed by
+// 0.5.
+func (s *svgPathScanner) number() (val float64, err error) {
+	s.skip()
+	start := s.pos
+	digits := func() (n int) {
+		for s.pos < len(s.str) && s.str[s.pos] >= '0' && s.str[s.pos] <= '9' {
+			s.pos++
+			n++
 		}
-		switch segPtr.Cmd {
-		case 'M':
-			x = seg.Arg[0]
-			y = seg.Arg[1]
-		case 'm':
-			adjust(0, x, y)
-			segPtr.Cmd = 'M'
-			x = segPtr.Arg[0]
-			y = segPtr.Arg[1]
-		case 'L':
-			x = seg.Arg[0]
-			y = seg.Arg[1]
-		case 'l':
-			adjust(0, x, y)
-			segPtr.Cmd = 'L'
-			x = segPtr.Arg[0]
-			y = segPtr.Arg[1]
-		case 'C':
-			x = seg.Arg[4]
-			y = seg.Arg[5]
-		case 'c':
-			adjust(0, x, y)
-			adjust(2, x, y)
-			adjust(4, x, y)
-			segPtr.Cmd = 'C'
-			x = segPtr.Arg[4]
-			y = segPtr.Arg[5]
+		return
+	}
+	if s.pos < len(s.str) && (s.str[s.pos] == '+' || s.str[s.pos] == '-') {
+		s.pos++
+	}
+	n := digits()
+	if s.pos < len(s.str) && s.str[s.pos] == '.' {
+		s.pos++
+		n += digits()
+	}
+	if n == 0 {
+		s.pos = start
+		return 0, fmt.Errorf("expecting number at position %d of SVG path", start)
+	}
+	if s.pos < len(s.str) && (s.str[s.pos] == 'e' || s.str[s.pos] == 'E') {
+		mark := s.pos
+		s.pos++
+		if s.pos < len(s.str) && (s.str[s.pos] == '+' || s.str[s.pos] == '-') {
+			s.pos++
+		}
+		if digits() == 0 {
+			// Not an exponent
+			s.pos = mark
 		}
 	}
+	return strconv.ParseFloat(s.str[start:s.pos], 64)
 }
 
+// flag reads an arc flag, which is a single digit that need not be
+// separated from what follows
+func (s *svgPathScanner) flag() (val float64, err error) {
+	s.skip()
+	if s.pos < len(s.str) && (s.str[s.pos] == '0' || s.str[s.pos] == '1') {
+		val = float64(s.str[s.pos] - '0')
+		s.pos++
+		return
+	}
+	return 0, fmt.Errorf("expecting arc flag at position %d of SVG path", s.pos)
+}
+
+// pathParse returns the segments of the SVG path data pathStr. All commands
+// are converted to the absolute commands 'M', 'L', 'C' and 'Z'.
 func pathParse(pathStr string) (segs []SVGBasicSegmentType, err error) {
-	var seg SVGBasicSegmentType
-	var j, argJ, argCount, prevArgCount int
-	setup := func(n int) {
-		// It is not strictly necessary to clear arguments, but result may be clearer
-		// to caller
-		for j := 0; j < len(seg.Arg); j++ {
-			seg.Arg[j] = 0.0
+	s := svgPathScanner{str: pathStr}
+	var cmd byte
+	var x, y, startX, startY float64 // current point and start of subpath
+	var ctlX, ctlY float64           // last control point of previous curve
+	var prevCmd byte                 // previous command, upper case
+	var arg [7]float64
+	add := func(c byte, args ...float64) {
+		seg := SVGBasicSegmentType{Cmd: c}
+		copy(seg.Arg[:], args)
+		segs = append(segs, seg)
+	}
+	for {
+		s.skip()
+		if s.pos >= len(s.str) {
+			break
+		}
+		c := s.str[s.pos]
+		switch {
+		case strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0:
+			cmd = c
+			s.pos++
+		case cmd == 0:
+			return nil, fmt.Errorf("expecting SVG path command at first position, got %c", c)
+		case cmd == 'Z' || cmd == 'z':
+			return nil, fmt.Errorf("expecting SVG path command at position %d, got %c", s.pos, c)
+		case cmd == 'M':
+			// Coordinate pairs that follow a moveto are implicit linetos
+			cmd = 'L'
+		case cmd == 'm':
+			cmd = 'l'
 		}
-		argJ = 0
-		argCount = n
-		prevArgCount = n
-	}
-	var str string
-	var c byte
-	pathStr = pathCmdSub.Replace(pathStr)
-	strList := strings.Fields(pathStr)
-	count := len(strList)
-	for j = 0; j < count && err == nil; j++ {
-		str = strList[j]
-		if argCount == 0 { // Look for path command or argument continuation
-			c = str[0]
-			if c == '-' || (c >= '0' && c <= '9') { // More arguments
-				if j > 0 {
-					setup(prevArgCount)
-					// Repeat previous action
-					if seg.Cmd == 'M' {
-						seg.Cmd = 'L'
-					} else if seg.Cmd == 'm' {
-						seg.Cmd = 'l'
-					}
-				} else {
-					err = fmt.Errorf("expecting SVG path command at first position, got %s", str)
+		upper := cmd &^ 0x20
+		var n int
+		switch upper {
+		case 'M', 'L', 'T':
+			n = 2
+		case 'H', 'V':
+			n = 1
+		case 'S', 'Q':
+			n = 4
+		case 'C':
+			n = 6
+		case 'A':
+			n = 7
+		}
+		for j := 0; j < n; j++ {
+			if upper == 'A' && (j == 3 || j == 4) {
+				arg[j], err = s.flag()
+			} else {
+				arg[j], err = s.number()
+			}
+			if err != nil {
+				if s.pos >= len(s.str) {
+					err = fmt.Errorf("expecting additional (%d) numeric arguments", n-j)
 				}
+				return nil, err
 			}
 		}
-		if err == nil {
-			if argCount == 0 {
-				seg.Cmd = str[0]
-				switch seg.Cmd {
-				case 'M', 'm': // Absolute/relative moveto: x, y
-					setup(2)
-				case 'C', 'c': // Absolute/relative Bézier curve: cx0, cy0, cx1, cy1, x1, y1
-					setup(6)
-				case 'L', 'l': // Absolute/relative lineto: x, y
-					setup(2)
-				default:
-					err = fmt.Errorf("expecting SVG path command at position %d, got %s", j, str)
-				}
-			} else {
-				seg.Arg[argJ], err = strconv.ParseFloat(str, 64)
-				if err == nil {
-					argJ++
-					argCount--
-					if argCount == 0 {
-						segs = append(segs, seg)
-					}
+		// Make coordinates absolute
+		if cmd != upper {
+			switch upper {
+			case 'H':
+				arg[0] += x
+			case 'V':
+				arg[0] += y
+			case 'A':
+				arg[5] += x
+				arg[6] += y
+			default:
+				for j := 0; j < n; j += 2 {
+					arg[j] += x
+					arg[j+1] += y
 				}
 			}
 		}
-	}
-	if err == nil {
-		if argCount == 0 {
-			absolutizePath(segs)
-		} else {
-			err = fmt.Errorf("expecting additional (%d) numeric arguments", argCount)
+		// The first control point of a smooth curve is the reflection of the
+		// last control point of the previous curve of the same kind
+		reflect := func(kinds string) (float64, float64) {
+			if prevCmd != 0 && strings.IndexByte(kinds, prevCmd) >= 0 {
+				return 2*x - ctlX, 2*y - ctlY
+			}
+			return x, y
 		}
+		switch upper {
+		case 'M':
+			x, y = arg[0], arg[1]
+			startX, startY = x, y
+			add('M', x, y)
+		case 'L':
+			x, y = arg[0], arg[1]
+			add('L', x, y)
+		case 'H':
+			x = arg[0]
+			add('L', x, y)
+		case 'V':
+			y = arg[0]
+			add('L', x, y)
+		case 'C':
+			add('C', arg[0], arg[1], arg[2], arg[3], arg[4], arg[5])
+			ctlX, ctlY, x, y = arg[2], arg[3], arg[4], arg[5]
+		case 'S':
+			cx, cy := reflect("CS")
+			add('C', cx, cy, arg[0], arg[1], arg[2], arg[3])
+			ctlX, ctlY, x, y = arg[0], arg[1], arg[2], arg[3]
+		case 'Q':
+			svgQuadratic(add, x, y, arg[0], arg[1], arg[2], arg[3])
+			ctlX, ctlY, x, y = arg[0], arg[1], arg[2], arg[3]
+		case 'T':
+			cx, cy := reflect("QT")
+			svgQuadratic(add, x, y, cx, cy, arg[0], arg[1])
+			ctlX, ctlY, x, y = cx, cy, arg[0], arg[1]
+		case 'A':
+			svgArc(add, x, y, arg[0], arg[1], arg[2], arg[3] != 0, arg[4] != 0, arg[5], arg[6])
+			x, y = arg[5], arg[6]
+		case 'Z':
+			add('Z', startX, startY)
+			x, y = startX, startY
+		}
+		prevCmd = upper
+	}
+	if len(segs) > 0 && segs[0].Cmd != 'M' {
+		err = fmt.Errorf("expecting SVG path to begin with moveto command")
+		segs = nil
 	}
 	return
 }
 
+// svgQuadratic adds the cubic Bézier curve that is equivalent to the
+// quadratic curve from (x0, y0) to (x1, y1) with control point (cx, cy)
+func svgQuadratic(add func(byte, ...float64), x0, y0, cx, cy, x1, y1 float64) {
+	add('C', x0+2*(cx-x0)/3, y0+2*(cy-y0)/3, x1+2*(cx-x1)/3, y1+2*(cy-y1)/3, x1, y1)
+}
+
+// svgArc adds the cubic Bézier curves that approximate the elliptical arc
+// from (x0, y0) to (x1, y1) as described in the implementation notes of the
+// SVG specification. The arc is divided into curves that span no more than
+// a quarter turn each.
+func svgArc(add func(byte, ...float64), x0, y0, rx, ry, angle float64, large, sweep bool, x1, y1 float64) {
+	if x0 == x1 && y0 == y1 {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		add('L', x1, y1)
+		return
+	}
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	// Center parameterization
+	dx, dy := (x0-x1)/2, (y0-y1)/2
+	px, py := cos*dx+sin*dy, -sin*dx+cos*dy
+	if lambda := px*px/(rx*rx) + py*py/(ry*ry); lambda > 1 {
+		// Radii are too small to reach the end point
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+	num := rx*rx*ry*ry - rx*rx*py*py - ry*ry*px*px
+	den := rx*rx*py*py + ry*ry*px*px
+	coef := 0.0
+	if num > 0 && den > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		coef = -coef
+	}
+	qx, qy := coef*rx*py/ry, -coef*ry*px/rx
+	cx := cos*qx - sin*qy + (x0+x1)/2
+	cy := sin*qx + cos*qy + (y0+y1)/2
+	theta := math.Atan2((py-qy)/ry, (px-qx)/rx)
+	delta := math.Atan2((-py-qy)/ry, (-px-qx)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+	// Approximate each part with a cubic Bézier curve
+	count := int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-9))
+	step := delta / float64(count)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	point := func(t float64) (float64, float64, float64, float64) {
+		st, ct := math.Sincos(t)
+		ex, ey := rx*ct, ry*st  // point on ellipse
+		tx, ty := -rx*st, ry*ct // tangent
+		return cx + cos*ex - sin*ey, cy + sin*ex + cos*ey,
+			cos*tx - sin*ty, sin*tx + cos*ty
+	}
+	ax, ay, atx, aty := point(theta)
+	for j := 1; j <= count; j++ {
+		bx, by, btx, bty := point(theta + step*float64(j))
+		if j == count {
+			bx, by = x1, y1
+		}
+		add('C', ax+k*atx, ay+k*aty, bx-k*btx, by-k*bty, bx, by)
+		ax, ay, atx, aty = bx, by, btx, bty
+	}
+}
+
 // SVGBasicType aggregates the information needed to describe a multi-segment
-// basic vector image
+// basic vector image. Segments holds the outline of each shape of the image,
+// with the transformations of the shape and its groups applied. A descriptor
+// returned by SVGBasicParse() also retains the structure and styling of the
+// image for use by SVGBasicWrite().
 type SVGBasicType struct {
-	Wd, Ht   float64
-	Segments [][]SVGBasicSegmentType
+	Wd, Ht    float64
+	Segments  [][]SVGBasicSegmentType
+	elements  []svgElementType
+	viewBox   [4]float64 // x, y, width and height of the user space
+	aspectStr string     // preserveAspectRatio
 }
 
 // SVGBasicParse parses a simple scalable vector graphics (SVG) buffer into a
-// descriptor. Only a small subset of the SVG standard, in particular the path
-// information generated by jSignature, is supported. The returned path data
-// includes only the commands 'M' (absolute moveto: x, y), 'L' (absolute
-// lineto: x, y), and 'C' (absolute cubic Bézier curve: cx0, cy0, cx1, cy1,
-// x1,y1).
+// descriptor. Only a subset of the SVG standard is supported: the shape
+// elements path, rect, circle, ellipse, line, polyline and polygon, text
+// elements with tspan elements, and use elements, nested in any number of g
+// elements. Use elements may refer to any element with an id, including
+// elements within defs and symbol elements. The transform attribute of
+// shapes and groups may contain any of the SVG transformation functions. The
+// presentation properties fill, stroke, color, stroke-width, opacity,
+// fill-opacity, stroke-opacity, fill-rule, stroke-linecap, stroke-linejoin,
+// stroke-dasharray, stroke-dashoffset, display, clip-path, clip-rule,
+// font-family, font-size, font-weight, font-style and text-anchor are
+// recognized both as attributes and in style attributes. Fill and stroke may
+// refer to linearGradient and radialGradient elements with any number of
+// stops, and clip-path to clipPath elements. Other elements are ignored.
+//
+// Path data may contain all of the commands of the SVG path grammar in
+// absolute and relative form, including implicitly repeated commands. The
+// returned path data includes only the commands 'M' (absolute moveto: x, y),
+// 'L' (absolute lineto: x, y), 'C' (absolute cubic Bézier curve: cx0, cy0,
+// cx1, cy1, x1,y1) and 'Z' (close path: x, y, the start of the subpath).
+// Horizontal and vertical lines are converted to linetos, and quadratic
+// Bézier curves, elliptical arcs and the other basic shapes to linetos and
+// cubic Bézier curves.
+//
+// The width and height attributes of the svg element determine the extent of
+// the image. If they are missing, the dimensions of the viewBox attribute are
+// used. If a viewBox is specified, the coordinates of the image are fitted
+// into its extent as specified by the preserveAspectRatio attribute.
 func SVGBasicParse(buf []byte) (sig SVGBasicType, err error) {
-	type pathType struct {
-		D string `xml:"d,attr"`
+	var root *svgNodeType
+	root, err = svgTree(buf)
+	if err != nil {
+		return
 	}
-	type srcType struct {
-		Wd    float64    `xml:"width,attr"`
-		Ht    float64    `xml:"height,attr"`
-		Paths []pathType `xml:"path"`
+	if root.name != "svg" {
+		err = fmt.Errorf("expecting svg root element, got %s", root.name)
+		return
 	}
-	var src srcType
-	err = xml.Unmarshal(buf, &src)
-	if err == nil {
-		if src.Wd > 0 && src.Ht > 0 {
-			sig.Wd, sig.Ht = src.Wd, src.Ht
-			var segs []SVGBasicSegmentType
-			for _, path := range src.Paths {
-				if err == nil {
-					segs, err = pathParse(path.D)
-					if err == nil {
-						sig.Segments = append(sig.Segments, segs)
-					}
-				}
-			}
-		} else {
-			err = fmt.Errorf("unacceptable values for basic SVG extent: %.2f x %.2f",
-				sig.Wd, sig.Ht)
+	vb, vbOk := svgViewBox(root.attr["viewBox"])
+	sig.Wd, _ = svgLength(root.attr["width"], 0)
+	sig.Ht, _ = svgLength(root.attr["height"], 0)
+	if (sig.Wd <= 0 || sig.Ht <= 0) && vbOk {
+		sig.Wd, sig.Ht = vb[2], vb[3]
+	}
+	if sig.Wd <= 0 || sig.Ht <= 0 {
+		err = fmt.Errorf("unacceptable values for basic SVG extent: %.2f x %.2f",
+			sig.Wd, sig.Ht)
+		return
+	}
+	if !vbOk {
+		vb = [4]float64{0, 0, sig.Wd, sig.Ht}
+	}
+	sig.viewBox, sig.aspectStr = vb, root.attr["preserveAspectRatio"]
+	p := svgParseType{sb: &sig, wd: vb[2], ht: vb[3], ids: make(map[string]*svgNodeType),
+		grads: make(map[string]*svgGradientType)}
+	svgIndex(root, p.ids)
+	style := p.style(svgStyleDefault, root)
+	if style.hidden {
+		return
+	}
+	var el svgElementType
+	var ok bool
+	for _, n := range root.kids {
+		el, ok, err = p.element(n, style)
+		if err != nil {
+			return
+		}
+		if ok {
+			sig.elements = append(sig.elements, el)
 		}
 	}
+	m := svgViewBoxMatrix(vb, sig.aspectStr, sig.Wd, sig.Ht)
+	svgFlatten(sig.elements, m, func(segs []SVGBasicSegmentType) {
+		sig.Segments = append(sig.Segments, segs)
+	})
 	return
 }
 
@@ -0,0 +1,411 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"math"
+	"strings"
+)
+
+// svgElementType is a shape, text or group of an SVG image that has been
+// prepared for rendering
+type svgElementType struct {
+	segs      []SVGBasicSegmentType // outline of a shape in its own coordinates
+	closed    bool                  // outline of a shape is a closed figure
+	group     bool
+	spans     []svgSpanType   // content of a text element
+	matrix    TransformMatrix // transformation of the element's coordinates
+	transform bool            // matrix is specified
+	style     svgStyleType
+	clip      *svgClipType
+	kids      []svgElementType
+}
+
+// svgSpanType is a run of text that is rendered in a single style. A span
+// with an absolute position begins a new chunk of text, which is aligned as
+// a whole according to text-anchor.
+type svgSpanType struct {
+	x, y       float64 // absolute position
+	hasX, hasY bool    // absolute position is specified
+	dx, dy     float64 // shift relative to the end of the previous span
+	str        string
+	style      svgStyleType
+}
+
+// svgClipType is a clipping path
+type svgClipType struct {
+	segs    []SVGBasicSegmentType // outline in the user space of the clipped element
+	evenOdd bool
+	bbox    bool // outline is in fractions of the bounding box of the element
+}
+
+// svgShapes lists the elements that are rendered as outlines
+var svgShapes = map[string]bool{"path": true, "rect": true, "circle": true,
+	"ellipse": true, "line": true, "polyline": true, "polygon": true}
+
+// element prepares element n and its descendants for rendering. ok is false
+// if n is not rendered.
+func (p *svgParseType) element(n *svgNodeType, parent svgStyleType) (el svgElementType, ok bool, err error) {
+	el.style = p.style(parent, n)
+	if el.style.hidden {
+		return
+	}
+	el.matrix = svgMatrixIdentity
+	if str, found := n.attr["transform"]; found {
+		el.matrix, err = svgTransform(str)
+		if err != nil {
+			return
+		}
+		el.transform = true
+	}
+	translate := func(xName, yName string) {
+		x, _ := svgLength(n.attr[xName], p.wd)
+		y, _ := svgLength(n.attr[yName], p.ht)
+		if x != 0 || y != 0 {
+			el.matrix = svgMultiply(el.matrix, TransformMatrix{A: 1, D: 1, E: x, F: y})
+			el.transform = true
+		}
+	}
+	if id := svgRef(el.style.clipStr); id != "" {
+		el.clip = p.clipPath(id)
+	}
+	switch {
+	case n.name == "g" || n.name == "a" || n.name == "switch" || n.name == "svg" ||
+		(n.name == "symbol" && p.depth > 0):
+		el.group = true
+		if n.name == "svg" {
+			translate("x", "y")
+			if vb, vbOk := svgViewBox(n.attr["viewBox"]); vbOk {
+				wd, ht := p.wd, p.ht
+				if v, ok := svgLength(n.attr["width"], wd); ok && v > 0 {
+					wd = v
+				}
+				if v, ok := svgLength(n.attr["height"], ht); ok && v > 0 {
+					ht = v
+				}
+				el.matrix = svgMultiply(el.matrix, svgViewBoxMatrix(vb, n.attr["preserveAspectRatio"], wd, ht))
+				el.transform = true
+				// Percentages within the element refer to its view box
+				defer func(wd, ht float64) {
+					p.wd, p.ht = wd, ht
+				}(p.wd, p.ht)
+				p.wd, p.ht = vb[2], vb[3]
+			}
+		}
+		var kid svgElementType
+		for _, k := range n.kids {
+			kid, ok, err = p.element(k, el.style)
+			if err != nil {
+				return
+			}
+			if ok {
+				el.kids = append(el.kids, kid)
+			}
+		}
+		ok = true
+	case n.name == "use":
+		ref := p.ids[svgRef(n.attr["href"])]
+		if ref == nil || p.depth >= svgMaxDepth {
+			return
+		}
+		el.group = true
+		translate("x", "y")
+		var kid svgElementType
+		p.depth++
+		kid, ok, err = p.element(ref, el.style)
+		p.depth--
+		if err != nil {
+			return
+		}
+		if ok {
+			el.kids = append(el.kids, kid)
+		}
+		ok = true
+	case n.name == "text":
+		el.spans = p.text(n, el.style)
+		ok = len(el.spans) > 0
+	case svgShapes[n.name]:
+		el.segs, el.closed, err = p.shape(n)
+		ok = err == nil && len(el.segs) > 0
+	}
+	return
+}
+
+// text returns the spans of text element n. White space is collapsed as
+// specified for the default value of xml:space.
+func (p *svgParseType) text(n *svgNodeType, st svgStyleType) (spans []svgSpanType) {
+	space := true // leading white space is removed
+	// The position attributes of an element apply to the first text
+	// within it
+	var pending svgSpanType
+	var walk func(n *svgNodeType, st svgStyleType)
+	walk = func(n *svgNodeType, st svgStyleType) {
+		if v, ok := svgFirstLength(n.attr["x"], p.wd); ok {
+			pending.x, pending.hasX = v, true
+		}
+		if v, ok := svgFirstLength(n.attr["y"], p.ht); ok {
+			pending.y, pending.hasY = v, true
+		}
+		dx, _ := svgFirstLength(n.attr["dx"], p.wd)
+		dy, _ := svgFirstLength(n.attr["dy"], p.ht)
+		pending.dx += dx
+		pending.dy += dy
+		for _, k := range n.kids {
+			switch k.name {
+			case "#text":
+				var buf []byte
+				for _, ch := range []byte(k.text) {
+					if ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n' {
+						if space {
+							continue
+						}
+						ch = ' '
+						space = true
+					} else {
+						space = false
+					}
+					buf = append(buf, ch)
+				}
+				if len(buf) > 0 {
+					pending.str = string(buf)
+					pending.style = st
+					spans = append(spans, pending)
+					pending = svgSpanType{}
+				}
+			case "tspan", "a":
+				kst := p.style(st, k)
+				if !kst.hidden {
+					walk(k, kst)
+				}
+			}
+		}
+	}
+	walk(n, st)
+	// Remove trailing white space
+	for j := len(spans) - 1; j >= 0; j-- {
+		spans[j].str = strings.TrimRight(spans[j].str, " ")
+		if spans[j].str != "" {
+			break
+		}
+	}
+	for len(spans) > 0 && spans[len(spans)-1].str == "" {
+		spans = spans[:len(spans)-1]
+	}
+	return
+}
+
+// svgFirstLength returns the first length in a list of lengths, such as the
+// value of the x attribute of a text element
+func svgFirstLength(str string, ref float64) (v float64, ok bool) {
+	if list := strings.FieldsFunc(str, svgIsSep); len(list) > 0 {
+		v, ok = svgLength(list[0], ref)
+	}
+	return
+}
+
+// clipPath returns the clipping path with the specified identifier, or nil
+// if there is no such clipping path
+func (p *svgParseType) clipPath(id string) (cp *svgClipType) {
+	n := p.ids[id]
+	if n == nil || n.name != "clipPath" || p.depth >= svgMaxDepth {
+		return nil
+	}
+	cp = &svgClipType{bbox: n.attr["clipPathUnits"] == "objectBoundingBox"}
+	m := svgMatrixIdentity
+	if str, ok := n.attr["transform"]; ok {
+		m, _ = svgTransform(str)
+	}
+	p.depth++
+	var kids []svgElementType
+	for _, k := range n.kids {
+		if el, ok, err := p.element(k, svgStyleDefault); err == nil && ok {
+			if len(kids) == 0 {
+				cp.evenOdd = el.style.clipEvenOdd
+			}
+			kids = append(kids, el)
+		}
+	}
+	p.depth--
+	svgFlatten(kids, m, func(segs []SVGBasicSegmentType) {
+		cp.segs = append(cp.segs, segs...)
+	})
+	return
+}
+
+// svgFlatten calls fnc with the outline of each shape in list and its
+// descendants, transformed by their transformations and by m
+func svgFlatten(list []svgElementType, m TransformMatrix, fnc func([]SVGBasicSegmentType)) {
+	for j := range list {
+		el := &list[j]
+		mm := m
+		if el.transform {
+			mm = svgMultiply(m, el.matrix)
+		}
+		if el.group {
+			svgFlatten(el.kids, mm, fnc)
+		} else if len(el.segs) > 0 {
+			segs := make([]SVGBasicSegmentType, len(el.segs))
+			for k, seg := range el.segs {
+				for a := 0; a < 6; a += 2 {
+					seg.Arg[a], seg.Arg[a+1] = svgApply(mm, seg.Arg[a], seg.Arg[a+1])
+				}
+				segs[k] = seg
+			}
+			fnc(segs)
+		}
+	}
+}
+
+// bounds returns the bounding box of the shapes of el in the coordinates of
+// el. ok is false if el has no shapes.
+func (el *svgElementType) bounds() (x0, y0, x1, y1 float64, ok bool) {
+	x0, y0 = math.Inf(1), math.Inf(1)
+	x1, y1 = math.Inf(-1), math.Inf(-1)
+	add := func(x, y float64) {
+		x0, y0 = math.Min(x0, x), math.Min(y0, y)
+		x1, y1 = math.Max(x1, x), math.Max(y1, y)
+		ok = true
+	}
+	extent := func(segs []SVGBasicSegmentType) {
+		var x, y float64
+		for _, seg := range segs {
+			switch seg.Cmd {
+			case 'C':
+				// Include the extreme points of the curve
+				for _, t := range cubicExtremes(x, seg.Arg[0], seg.Arg[2], seg.Arg[4]) {
+					add(cubicPoint(t, x, seg.Arg[0], seg.Arg[2], seg.Arg[4]),
+						cubicPoint(t, y, seg.Arg[1], seg.Arg[3], seg.Arg[5]))
+				}
+				for _, t := range cubicExtremes(y, seg.Arg[1], seg.Arg[3], seg.Arg[5]) {
+					add(cubicPoint(t, x, seg.Arg[0], seg.Arg[2], seg.Arg[4]),
+						cubicPoint(t, y, seg.Arg[1], seg.Arg[3], seg.Arg[5]))
+				}
+				x, y = seg.Arg[4], seg.Arg[5]
+			default:
+				x, y = seg.Arg[0], seg.Arg[1]
+			}
+			add(x, y)
+		}
+	}
+	if el.group {
+		svgFlatten(el.kids, svgMatrixIdentity, extent)
+	} else {
+		extent(el.segs)
+	}
+	return
+}
+
+// shape returns the outline of the basic shape or path n
+func (p *svgParseType) shape(n *svgNodeType) (segs []SVGBasicSegmentType, closed bool, err error) {
+	add := func(c byte, args ...float64) {
+		seg := SVGBasicSegmentType{Cmd: c}
+		copy(seg.Arg[:], args)
+		segs = append(segs, seg)
+	}
+	diag := math.Sqrt(p.wd*p.wd+p.ht*p.ht) / math.Sqrt2
+	num := func(name string, ref float64) float64 {
+		v, _ := svgLength(n.attr[name], ref)
+		return v
+	}
+	ellipse := func(cx, cy, rx, ry float64) {
+		if rx > 0 && ry > 0 {
+			add('M', cx+rx, cy)
+			svgArc(add, cx+rx, cy, rx, ry, 0, false, true, cx-rx, cy)
+			svgArc(add, cx-rx, cy, rx, ry, 0, false, true, cx+rx, cy)
+			add('Z', cx+rx, cy)
+		}
+	}
+	switch n.name {
+	case "path":
+		segs, err = pathParse(n.attr["d"])
+		for _, seg := range segs {
+			if seg.Cmd == 'Z' {
+				closed = true
+			}
+		}
+		return
+	case "rect":
+		x, y := num("x", p.wd), num("y", p.ht)
+		w, h := num("width", p.wd), num("height", p.ht)
+		if w <= 0 || h <= 0 {
+			return
+		}
+		rx, rxOk := svgLength(n.attr["rx"], p.wd)
+		ry, ryOk := svgLength(n.attr["ry"], p.ht)
+		if !rxOk {
+			rx = ry
+		} else if !ryOk {
+			ry = rx
+		}
+		rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+		if rx > 0 && ry > 0 {
+			add('M', x+rx, y)
+			add('L', x+w-rx, y)
+			svgArc(add, x+w-rx, y, rx, ry, 0, false, true, x+w, y+ry)
+			add('L', x+w, y+h-ry)
+			svgArc(add, x+w, y+h-ry, rx, ry, 0, false, true, x+w-rx, y+h)
+			add('L', x+rx, y+h)
+			svgArc(add, x+rx, y+h, rx, ry, 0, false, true, x, y+h-ry)
+			add('L', x, y+ry)
+			svgArc(add, x, y+ry, rx, ry, 0, false, true, x+rx, y)
+			add('Z', x+rx, y)
+		} else {
+			add('M', x, y)
+			add('L', x+w, y)
+			add('L', x+w, y+h)
+			add('L', x, y+h)
+			add('Z', x, y)
+		}
+	case "circle":
+		r := num("r", diag)
+		ellipse(num("cx", p.wd), num("cy", p.ht), r, r)
+	case "ellipse":
+		ellipse(num("cx", p.wd), num("cy", p.ht), num("rx", p.wd), num("ry", p.ht))
+	case "line":
+		add('M', num("x1", p.wd), num("y1", p.ht))
+		add('L', num("x2", p.wd), num("y2", p.ht))
+		return
+	case "polyline", "polygon":
+		s := svgPathScanner{str: n.attr["points"]}
+		var pts []float64
+		for {
+			s.skip()
+			if s.pos >= len(s.str) {
+				break
+			}
+			v, e := s.number()
+			if e != nil {
+				// Render the points up to the error
+				break
+			}
+			pts = append(pts, v)
+		}
+		for j := 0; j+1 < len(pts); j += 2 {
+			if j == 0 {
+				add('M', pts[j], pts[j+1])
+			} else {
+				add('L', pts[j], pts[j+1])
+			}
+		}
+		if n.name == "polyline" || len(segs) == 0 {
+			return
+		}
+		add('Z', pts[0], pts[1])
+	}
+	closed = len(segs) > 0
+	return
+}
@@ -0,0 +1,623 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// svgNodeType is an element of an SVG document. Character data is held by
+// nodes named "#text".
+type svgNodeType struct {
+	name string
+	attr map[string]string
+	kids []*svgNodeType
+	text string
+}
+
+// svgTree returns the root element of the SVG document in buf
+func svgTree(buf []byte) (root *svgNodeType, err error) {
+	dec := xml.NewDecoder(bytes.NewReader(buf))
+	dec.Entity = xml.HTMLEntity
+	var stack []*svgNodeType
+	var tok xml.Token
+	for {
+		tok, err = dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &svgNodeType{name: t.Name.Local, attr: make(map[string]string)}
+			for _, a := range t.Attr {
+				// References may be given with the xlink namespace
+				if a.Name.Space == "" || a.Name.Space == "http://www.w3.org/2000/svg" ||
+					a.Name.Local == "href" {
+					n.attr[a.Name.Local] = a.Value
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.kids = append(parent.kids, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.kids = append(parent.kids, &svgNodeType{name: "#text", text: string(t)})
+			}
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if err == nil && root == nil {
+		err = fmt.Errorf("SVG document has no elements")
+	}
+	return
+}
+
+// svgIndex records the elements of the tree rooted at n that have an id
+func svgIndex(n *svgNodeType, ids map[string]*svgNodeType) {
+	if id, ok := n.attr["id"]; ok {
+		if _, found := ids[id]; !found {
+			ids[id] = n
+		}
+	}
+	for _, k := range n.kids {
+		svgIndex(k, ids)
+	}
+}
+
+// svgRef returns the identifier of the element referred to by a local IRI,
+// such as "#id" or "url(#id)"
+func svgRef(str string) string {
+	str = strings.TrimSpace(str)
+	if strings.HasPrefix(str, "url(") {
+		if pos := strings.Index(str, ")"); pos >= 0 {
+			str = strings.Trim(strings.TrimSpace(str[4:pos]), `"'`)
+		}
+	}
+	if strings.HasPrefix(str, "#") {
+		return str[1:]
+	}
+	return ""
+}
+
+// svgParseType holds the state of parsing an SVG document
+type svgParseType struct {
+	sb    *SVGBasicType
+	wd    float64 // width and height of the user space, to which
+	ht    float64 // percentages refer
+	ids   map[string]*svgNodeType
+	grads map[string]*svgGradientType
+	depth int // nesting of references
+}
+
+// svgMaxDepth limits the nesting of references, such as a use element that
+// refers to a group that contains a use element
+const svgMaxDepth = 16
+
+// svgPaintType is the value of a fill or stroke property
+type svgPaintType struct {
+	set     bool // property has been specified
+	none    bool
+	r, g, b int
+	grad    *svgGradientType // gradient paint server; r, g and b are its average color
+}
+
+// alpha returns the opacity of the paint itself
+func (p *svgPaintType) alpha() float64 {
+	if p.grad != nil {
+		return p.grad.opacity
+	}
+	return 1
+}
+
+// shaded returns true if the paint is a gradient with more than one color
+func (p *svgPaintType) shaded() bool {
+	return !p.none && p.grad != nil && len(p.grad.stops) > 1
+}
+
+// svgStyleType holds the presentation properties of an SVG element. Except
+// for opacity, which is the product of the opacities of the element and its
+// ancestors, the properties are inherited as specified.
+type svgStyleType struct {
+	fill, stroke                        svgPaintType
+	color                               svgPaintType // value of currentColor
+	strokeWd                            float64      // negative if unspecified
+	opacity, fillOpacity, strokeOpacity float64
+	evenOdd                             bool
+	capStr, joinStr                     string
+	dash                                []float64
+	dashOffset                          float64
+	clipEvenOdd                         bool
+	fontFamily                          string
+	fontSize                            float64 // in user units
+	bold, italic                        bool
+	anchorStr                           string
+	hidden                              bool   // display is none
+	clipStr                             string // clip-path reference, not inherited
+}
+
+// svgStyleDefault is the style of the root element before its own
+// properties are applied
+var svgStyleDefault = svgStyleType{strokeWd: -1, opacity: 1, fillOpacity: 1,
+	strokeOpacity: 1, fontFamily: "sans-serif", fontSize: 16}
+
+// painted returns true if either the fill or the stroke of the element has
+// been specified
+func (st *svgStyleType) painted() bool {
+	return st.fill.set || st.stroke.set
+}
+
+// svgStyleProps lists the presentation attributes that are recognized
+var svgStyleProps = []string{"fill", "stroke", "color", "stroke-width", "opacity",
+	"fill-opacity", "stroke-opacity", "fill-rule", "stroke-linecap",
+	"stroke-linejoin", "stroke-dasharray", "stroke-dashoffset", "display",
+	"clip-path", "clip-rule", "font-family", "font-size", "font-weight",
+	"font-style", "text-anchor"}
+
+// style returns the style of element n given the style of its parent.
+// Declarations in the style attribute take precedence over presentation
+// attributes.
+func (p *svgParseType) style(parent svgStyleType, n *svgNodeType) (st svgStyleType) {
+	st = parent
+	st.hidden = false
+	st.clipStr = ""
+	var decls []cssDeclType
+	for _, prop := range svgStyleProps {
+		if value, ok := n.attr[prop]; ok {
+			decls = append(decls, cssDeclType{prop, strings.TrimSpace(value)})
+		}
+	}
+	decls = append(decls, cssParseDecls(n.attr["style"])...)
+	// color must be known before fill and stroke refer to it
+	for _, d := range decls {
+		if d.prop == "color" {
+			if r, g, b, ok := cssColor(d.value); ok {
+				st.color = svgPaintType{set: true, r: r, g: g, b: b}
+			}
+		}
+	}
+	for _, d := range decls {
+		if d.value == "inherit" {
+			continue
+		}
+		switch d.prop {
+		case "fill":
+			st.fill = p.paint(&st, d.value)
+		case "stroke":
+			st.stroke = p.paint(&st, d.value)
+		case "stroke-width":
+			if v, ok := svgLength(d.value, 0); ok && v >= 0 {
+				st.strokeWd = v
+			}
+		case "opacity":
+			st.opacity *= svgOpacity(d.value)
+		case "fill-opacity":
+			st.fillOpacity = svgOpacity(d.value)
+		case "stroke-opacity":
+			st.strokeOpacity = svgOpacity(d.value)
+		case "fill-rule":
+			st.evenOdd = d.value == "evenodd"
+		case "stroke-linecap":
+			st.capStr = d.value
+		case "stroke-linejoin":
+			st.joinStr = d.value
+		case "stroke-dasharray":
+			st.dash = nil
+			if d.value != "none" {
+				for _, str := range strings.FieldsFunc(d.value, svgIsSep) {
+					if v, ok := svgLength(str, 0); ok && v >= 0 {
+						st.dash = append(st.dash, v)
+					}
+				}
+				if len(st.dash)%2 == 1 {
+					st.dash = append(st.dash, st.dash...)
+				}
+			}
+		case "stroke-dashoffset":
+			st.dashOffset, _ = svgLength(d.value, 0)
+		case "display":
+			st.hidden = d.value == "none"
+		case "clip-path":
+			st.clipStr = d.value
+		case "clip-rule":
+			st.clipEvenOdd = d.value == "evenodd"
+		case "font-family":
+			st.fontFamily = d.value
+		case "font-size":
+			// Relative sizes refer to the font size of the parent
+			if v, ok := cssLength(d.value, parent.fontSize*0.75, parent.fontSize*0.75); ok && v > 0 {
+				st.fontSize = v / 0.75
+			}
+		case "font-weight":
+			switch d.value {
+			case "bold", "bolder", "600", "700", "800", "900":
+				st.bold = true
+			case "normal", "lighter", "100", "200", "300", "400", "500":
+				st.bold = false
+			}
+		case "font-style":
+			st.italic = d.value == "italic" || d.value == "oblique"
+		case "text-anchor":
+			st.anchorStr = d.value
+		}
+	}
+	return
+}
+
+// paint returns the paint specified by str for an element of style st. A
+// reference to a gradient that cannot be used is replaced by the fallback
+// color that follows it, if any.
+func (p *svgParseType) paint(st *svgStyleType, str string) (pt svgPaintType) {
+	pt.set = true
+	if strings.HasPrefix(str, "url(") {
+		if g := p.gradient(svgRef(str)); g != nil {
+			pt.grad = g
+			pt.r, pt.g, pt.b = g.avgR, g.avgG, g.avgB
+			pt.none = len(g.stops) == 0
+			return
+		}
+		if pos := strings.Index(str, ")"); pos >= 0 {
+			str = strings.TrimSpace(str[pos+1:])
+		}
+		if str == "" {
+			str = "none"
+		}
+	}
+	switch strings.ToLower(str) {
+	case "none", "transparent":
+		pt.none = true
+	case "currentcolor":
+		pt = st.color
+		pt.set = true
+	default:
+		var ok bool
+		pt.r, pt.g, pt.b, ok = cssColor(str)
+		if !ok {
+			pt.none = true
+		}
+	}
+	return
+}
+
+// svgGradientType is a linear or radial gradient. The coordinates follow the
+// convention of gradientType: a linear gradient runs from (x1, y1) to (x2,
+// y2), and a radial gradient from the focal point (x1, y1) to the circle
+// centered at (x2, y2) with radius r. They are expressed in user units or,
+// if bbox is true, as fractions of the bounding box of the painted element.
+type svgGradientType struct {
+	radial            bool
+	x1, y1, x2, y2, r float64
+	bbox              bool
+	matrix            TransformMatrix // gradientTransform
+	stops             []gradientStopType
+	avgR, avgG, avgB  int     // average color of the stops
+	opacity           float64 // average opacity of the stops
+}
+
+// gradient returns the linear or radial gradient with the specified
+// identifier, or nil if there is no such gradient. Attributes and stops that
+// are not specified are taken from the gradient referred to with href, if
+// any.
+func (p *svgParseType) gradient(id string) (g *svgGradientType) {
+	if g, ok := p.grads[id]; ok {
+		return g
+	}
+	n := p.ids[id]
+	if n == nil || (n.name != "linearGradient" && n.name != "radialGradient") {
+		return nil
+	}
+	attr := make(map[string]string)
+	var stops []*svgNodeType
+	for j, m := 0, n; m != nil && j < svgMaxDepth; j++ {
+		if m.name == "linearGradient" || m.name == "radialGradient" {
+			for key, value := range m.attr {
+				if _, ok := attr[key]; !ok {
+					attr[key] = value
+				}
+			}
+			if stops == nil {
+				for _, k := range m.kids {
+					if k.name == "stop" {
+						stops = append(stops, k)
+					}
+				}
+			}
+		}
+		m = p.ids[svgRef(m.attr["href"])]
+	}
+	g = &svgGradientType{radial: n.name == "radialGradient",
+		bbox: attr["gradientUnits"] != "userSpaceOnUse", matrix: svgMatrixIdentity}
+	if str, ok := attr["gradientTransform"]; ok {
+		g.matrix, _ = svgTransform(str)
+	}
+	wd, ht := 1.0, 1.0
+	if !g.bbox {
+		wd, ht = p.wd, p.ht
+	}
+	coord := func(name, defStr string, ref float64) float64 {
+		str, ok := attr[name]
+		if !ok {
+			str = defStr
+		}
+		v, _ := svgLength(str, ref)
+		return v
+	}
+	if g.radial {
+		g.x2, g.y2 = coord("cx", "50%", wd), coord("cy", "50%", ht)
+		g.r = coord("r", "50%", math.Sqrt((wd*wd+ht*ht)/2))
+		g.x1, g.y1 = g.x2, g.y2
+		if str, ok := attr["fx"]; ok {
+			g.x1, _ = svgLength(str, wd)
+		}
+		if str, ok := attr["fy"]; ok {
+			g.y1, _ = svgLength(str, ht)
+		}
+		// Keep the focal point inside the circle
+		if dist := math.Hypot(g.x1-g.x2, g.y1-g.y2); dist > 0.99*g.r && dist > 0 {
+			g.x1 = g.x2 + (g.x1-g.x2)*0.99*g.r/dist
+			g.y1 = g.y2 + (g.y1-g.y2)*0.99*g.r/dist
+		}
+	} else {
+		g.x1, g.y1 = coord("x1", "0%", wd), coord("y1", "0%", ht)
+		g.x2, g.y2 = coord("x2", "100%", wd), coord("y2", "0%", ht)
+	}
+	// Stops
+	var pos, sumR, sumG, sumB, sumA float64
+	var r, gr, b int
+	for _, k := range stops {
+		decls := []cssDeclType{{"stop-color", k.attr["stop-color"]},
+			{"stop-opacity", k.attr["stop-opacity"]}}
+		decls = append(decls, cssParseDecls(k.attr["style"])...)
+		r, gr, b = 0, 0, 0
+		alpha := 1.0
+		for _, d := range decls {
+			switch d.prop {
+			case "stop-color":
+				if cr, cg, cb, ok := cssColor(d.value); ok {
+					r, gr, b = cr, cg, cb
+				}
+			case "stop-opacity":
+				if d.value != "" {
+					alpha = svgOpacity(d.value)
+				}
+			}
+		}
+		if v, ok := svgLength(k.attr["offset"], 1); ok {
+			pos = math.Max(pos, math.Min(v, 1))
+		}
+		g.stops = append(g.stops, gradientStopType{pos, colorValue(r, gr, b, "", "").str})
+		sumR += float64(r)
+		sumG += float64(gr)
+		sumB += float64(b)
+		sumA += alpha
+	}
+	if count := len(g.stops); count > 0 {
+		c := float64(count)
+		g.avgR, g.avgG, g.avgB = int(sumR/c+0.5), int(sumG/c+0.5), int(sumB/c+0.5)
+		g.opacity = sumA / c
+		if (g.radial && g.r == 0) || (!g.radial && g.x1 == g.x2 && g.y1 == g.y2) {
+			// A gradient of zero length is painted with its last color
+			g.stops = g.stops[count-1:]
+			g.avgR, g.avgG, g.avgB = r, gr, b
+		} else if count > 1 {
+			// Extend the first and last colors to the ends of the gradient
+			if first := g.stops[0]; first.pos > 0 {
+				g.stops = append([]gradientStopType{{0, first.clrStr}}, g.stops...)
+			}
+			if last := g.stops[len(g.stops)-1]; last.pos < 1 {
+				g.stops = append(g.stops, gradientStopType{1, last.clrStr})
+			}
+		}
+	}
+	p.grads[id] = g
+	return
+}
+
+// svgOpacity returns the opacity value in str clamped to the range 0 to 1
+func svgOpacity(str string) float64 {
+	pct := strings.HasSuffix(str, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(str, "%"), 64)
+	if err != nil {
+		return 1
+	}
+	if pct {
+		v /= 100
+	}
+	return math.Max(0, math.Min(1, v))
+}
+
+// svgIsSep returns true if r separates the values of a list
+func svgIsSep(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+// svgLength returns the length in str in user units, which correspond to
+// pixels. Percentages are relative to ref.
+func svgLength(str string, ref float64) (v float64, ok bool) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return 0, false
+	}
+	// cssLength works in points, of which there are 0.75 to a pixel
+	v, ok = cssLength(str, 12, ref*0.75)
+	return v / 0.75, ok
+}
+
+// svgViewBox parses the value of a viewBox attribute. ok is false if the
+// value is missing or invalid.
+func svgViewBox(str string) (vb [4]float64, ok bool) {
+	list := strings.FieldsFunc(str, svgIsSep)
+	if len(list) != 4 {
+		return
+	}
+	for j, s := range list {
+		var err error
+		if vb[j], err = strconv.ParseFloat(s, 64); err != nil {
+			return
+		}
+	}
+	return vb, vb[2] > 0 && vb[3] > 0
+}
+
+// svgViewBoxMatrix returns the transformation that fits the view box vb into
+// a viewport of width wd and height ht as specified by aspectStr, the value
+// of a preserveAspectRatio attribute
+func svgViewBoxMatrix(vb [4]float64, aspectStr string, wd, ht float64) TransformMatrix {
+	sx, sy := wd/vb[2], ht/vb[3]
+	align, slice := svgAspect(aspectStr)
+	if align != "none" {
+		s := math.Min(sx, sy)
+		if slice {
+			s = math.Max(sx, sy)
+		}
+		sx, sy = s, s
+	}
+	m := TransformMatrix{A: sx, D: sy, E: -vb[0] * sx, F: -vb[1] * sy}
+	dx, dy := svgAlign(align, wd-vb[2]*sx, ht-vb[3]*sy)
+	m.E += dx
+	m.F += dy
+	return m
+}
+
+// svgAspect returns the alignment, such as "xMidYMid" or "none", and the
+// meet or slice setting of the value of a preserveAspectRatio attribute
+func svgAspect(aspectStr string) (align string, slice bool) {
+	list := strings.Fields(aspectStr)
+	if len(list) > 0 && list[0] == "defer" {
+		list = list[1:]
+	}
+	align = "xMidYMid"
+	if len(list) > 0 {
+		align = list[0]
+	}
+	slice = len(list) > 1 && list[1] == "slice"
+	return
+}
+
+// svgAlign returns the offsets that align content within a viewport that
+// exceeds it by dx horizontally and dy vertically
+func svgAlign(align string, dx, dy float64) (x, y float64) {
+	switch {
+	case strings.Contains(align, "xMid"):
+		x = dx / 2
+	case strings.Contains(align, "xMax"):
+		x = dx
+	}
+	switch {
+	case strings.Contains(align, "YMid"):
+		y = dy / 2
+	case strings.Contains(align, "YMax"):
+		y = dy
+	}
+	return
+}
+
+// svgMatrixIdentity is the transformation that leaves coordinates unchanged
+var svgMatrixIdentity = TransformMatrix{A: 1, D: 1}
+
+// svgMultiply returns the transformation that applies n and then m
+func svgMultiply(m, n TransformMatrix) TransformMatrix {
+	return TransformMatrix{
+		A: m.A*n.A + m.C*n.B,
+		B: m.B*n.A + m.D*n.B,
+		C: m.A*n.C + m.C*n.D,
+		D: m.B*n.C + m.D*n.D,
+		E: m.A*n.E + m.C*n.F + m.E,
+		F: m.B*n.E + m.D*n.F + m.F,
+	}
+}
+
+// svgApply returns the point (x, y) transformed by m
+func svgApply(m TransformMatrix, x, y float64) (float64, float64) {
+	return m.A*x + m.C*y + m.E, m.B*x + m.D*y + m.F
+}
+
+// svgTransform parses the value of a transform attribute, such as
+// "translate(10 20) rotate(45)". The matrix uses the SVG convention in which
+// a point (x, y) is transformed to (A*x + C*y + E, B*x + D*y + F).
+func svgTransform(str string) (m TransformMatrix, err error) {
+	m = svgMatrixIdentity
+	str = strings.TrimSpace(str)
+	for str != "" {
+		open := strings.Index(str, "(")
+		end := strings.Index(str, ")")
+		if open < 0 || end < open {
+			return m, fmt.Errorf("invalid SVG transform \"%s\"", str)
+		}
+		name := strings.TrimSpace(str[:open])
+		var args []float64
+		for _, field := range strings.FieldsFunc(str[open+1:end], svgIsSep) {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return m, fmt.Errorf("invalid SVG transform argument \"%s\"", field)
+			}
+			args = append(args, v)
+		}
+		str = strings.TrimLeft(str[end+1:], " \t\r\n,")
+		var t TransformMatrix
+		count := len(args)
+		switch {
+		case name == "matrix" && count == 6:
+			t = TransformMatrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		case name == "translate" && (count == 1 || count == 2):
+			t = svgMatrixIdentity
+			t.E = args[0]
+			if count == 2 {
+				t.F = args[1]
+			}
+		case name == "scale" && (count == 1 || count == 2):
+			t = TransformMatrix{A: args[0], D: args[0]}
+			if count == 2 {
+				t.D = args[1]
+			}
+		case name == "rotate" && (count == 1 || count == 3):
+			sin, cos := math.Sincos(args[0] * math.Pi / 180)
+			t = TransformMatrix{A: cos, B: sin, C: -sin, D: cos}
+			if count == 3 {
+				cx, cy := args[1], args[2]
+				t.E = cx - cos*cx + sin*cy
+				t.F = cy - sin*cx - cos*cy
+			}
+		case name == "skewX" && count == 1:
+			t = svgMatrixIdentity
+			t.C = math.Tan(args[0] * math.Pi / 180)
+		case name == "skewY" && count == 1:
+			t = svgMatrixIdentity
+			t.B = math.Tan(args[0] * math.Pi / 180)
+		default:
+			return m, fmt.Errorf("invalid SVG transform \"%s\" with %d arguments", name, count)
+		}
+		m = svgMultiply(m, t)
+	}
+	return
+}
@@ -16,43 +16,474 @@
 
 package gofpdf
 
-// SVGBasicWrite renders the paths encoded in the basic SVG image specified by
-// sb. The scale value is used to convert the coordinates in the path to the
-// unit of measure specified in New(). The current position (as set with a call
-// to SetXY()) is used as the origin of the image. The current line cap style
-// (as set with SetLineCapStyle()), line width (as set with SetLineWidth()),
-// and draw color (as set with SetDrawColor()) are used in drawing the image
-// paths.
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// SVGBasicWrite renders the image specified by sb. The scale value is used to
+// convert the coordinates of the image to the unit of measure specified in
+// New(). The current position (as set with a call to SetXY()) is used as the
+// origin of the image.
+//
+// Shapes are filled and stroked according to their fill, stroke and related
+// properties, with the transformations of the shapes and their groups
+// applied. As specified by SVG, a shape without a fill property is filled
+// with black and a shape without a stroke property is not stroked. The
+// opacity of the image is combined with the current alpha value (as set with
+// SetAlpha()). The current colors, line width, line styles and font are
+// restored after each shape is drawn.
+//
+// Gradient fills of shapes are rendered as smooth shadings. Because PDF
+// shadings have no opacity of their own, the stop opacities of a gradient
+// are averaged, and strokes and text painted with a gradient use the average
+// color of its stops. The shapes of a clipping path are combined into a
+// single path using the clip-rule of the first shape. Text is set in the
+// first family of its font-family list that is either a core font or has
+// been added with AddFont(); the generic families serif, sans-serif and
+// monospace map to Times, Helvetica and Courier. Unknown families are
+// replaced with Helvetica.
+//
+// Open shapes, such as the paths generated by jSignature, that have no fill
+// or stroke properties specified for them or their groups are instead stroked
+// with the current line cap style (as set with SetLineCapStyle()), line width
+// (as set with SetLineWidth()), and draw color (as set with SetDrawColor()).
+// This is also the case for all paths of a descriptor that has not been
+// returned by SVGBasicParse().
 func (f *Fpdf) SVGBasicWrite(sb *SVGBasicType, scale float64) {
 	originX, originY := f.GetXY()
-	var x, y, newX, newY float64
+	if sb.elements == nil {
+		for j := 0; j < len(sb.Segments) && f.Ok(); j++ {
+			f.svgBasicStroke(sb.Segments[j], originX, originY, scale)
+		}
+		return
+	}
+	root := svgElementType{group: true, kids: sb.elements,
+		matrix: svgViewBoxMatrix(sb.viewBox, sb.aspectStr, sb.Wd, sb.Ht)}
+	root.transform = root.matrix != svgMatrixIdentity
+	f.svgBasicElement(&root, originX, originY, scale)
+}
+
+// svgFormType is an SVG image that has been rendered once as a form XObject
+// by RegisterSVG()
+type svgFormType struct {
+	tpl       *pageTplType // content of the view box of the image
+	wd, ht    float64      // intrinsic extent of the image in pixels
+	aspectStr string       // preserveAspectRatio
+}
+
+// RegisterSVG parses the SVG image read from r and renders it once as a form
+// XObject that is identified by svgName. The image can then be drawn any
+// number of times with SVG(), each time at the cost of a single reference to
+// the form. Nothing is done if an image with the same name has already been
+// registered. See SVGBasicParse() for the subset of SVG that is
+// supported.
+//
+// The image is rendered as described for SVGBasicWrite(), except that the
+// paths that are stroked with the current line settings and draw color use
+// those in effect at each call to SVG(), scaled with the image.
+//
+// The RegisterSVG() example demonstrates this method.
+func (f *Fpdf) RegisterSVG(svgName string, r io.Reader) {
+	if f.err != nil {
+		return
+	}
+	if _, ok := f.svgs[svgName]; ok {
+		return
+	}
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		f.err = err
+		return
+	}
+	sb, err := SVGBasicParse(buf)
+	if err != nil {
+		f.err = err
+		return
+	}
+	// User units are pixels, of which there are 96 to the inch
+	scale := 0.75 / f.k
+	vb := sb.viewBox
+	size := SizeType{Wd: vb[2] * scale, Ht: vb[3] * scale}
+	// Render the image into a separate content stream
+	root := svgElementType{group: true, kids: sb.elements}
+	content := f.captureContent(func() {
+		f.svgBasicElement(&root, -vb[0]*scale, f.h-size.Ht-vb[1]*scale, scale)
+	})
+	if f.err == nil {
+		f.svgs[svgName] = &svgFormType{
+			tpl: &pageTplType{id: GenerateTemplateID(), size: size, page: content},
+			wd:  sb.Wd, ht: sb.Ht, aspectStr: sb.aspectStr}
+	}
+}
+
+// SVG draws the SVG image that has been registered with RegisterSVG() under
+// the name svgName. The upper left corner of the area that the image
+// occupies is at (x, y), and its width and height are w and h. If both w and
+// h are zero, the intrinsic extent of the image, given by its width and
+// height attributes at 96 pixels to the inch, is used. If only one of them
+// is zero, it is calculated from the other to keep the proportions of the
+// image.
+//
+// If the proportions of the area differ from those of the view box of the
+// image, the image is fitted into the area as specified by its
+// preserveAspectRatio attribute. By default, the image is scaled uniformly
+// to fit within the area and centered. If the attribute specifies slice, the
+// image covers the area and is clipped to it.
+//
+// The RegisterSVG() example demonstrates this method.
+func (f *Fpdf) SVG(svgName string, x, y, w, h float64) {
+	if f.err != nil {
+		return
+	}
+	form, ok := f.svgs[svgName]
+	if !ok {
+		f.err = fmt.Errorf("SVG image %s has not been registered", svgName)
+		return
+	}
+	if f.page <= 0 {
+		f.err = fmt.Errorf("cannot draw SVG image %s without first adding a page", svgName)
+		return
+	}
+	wd, ht := form.wd*0.75/f.k, form.ht*0.75/f.k
+	switch {
+	case w == 0 && h == 0:
+		w, h = wd, ht
+	case w == 0:
+		w = h * wd / ht
+	case h == 0:
+		h = w * ht / wd
+	}
+	size := form.tpl.size
+	sx, sy := w/size.Wd, h/size.Ht
+	align, slice := svgAspect(form.aspectStr)
+	if align != "none" {
+		s := math.Min(sx, sy)
+		if slice {
+			s = math.Max(sx, sy)
+		}
+		sx, sy = s, s
+	}
+	dx, dy := svgAlign(align, w-size.Wd*sx, h-size.Ht*sy)
+	clip := slice && align != "none"
+	if clip {
+		f.ClipRect(x, y, w, h, false)
+	}
+	f.templates[form.tpl.id] = form.tpl
+	f.measureRect(x, y, w, h)
+	f.outf("q %.5f 0 0 %.5f %.5f %.5f cm /TPL%d Do Q", sx, sy,
+		(x+dx)*f.k, (f.h-y-dy-size.Ht*sy)*f.k, form.tpl.id)
+	if clip {
+		f.ClipEnd()
+	}
+}
+
+// svgBasicElement renders a shape or group of an SVG image with its origin
+// at (originX, originY)
+func (f *Fpdf) svgBasicElement(el *svgElementType, originX, originY, scale float64) {
+	if el.transform {
+		// The SVG transformation is applied to user space, which is
+		// mapped to the page by the origin and scale and by the flipped
+		// vertical axis of PDF space
+		m := el.matrix
+		f.TransformBegin()
+		f.Transform(TransformMatrix{
+			A: m.A, B: 0 - m.B, C: 0 - m.C, D: m.D,
+			E: f.k * (originX + scale*m.E - m.A*originX + m.C*(f.h-originY)),
+			F: f.k * ((f.h-originY)*(1-m.D) - scale*m.F + m.B*originX),
+		})
+	}
+	clip := el.clip != nil
+	if clip {
+		clip = f.svgBasicClip(el, originX, originY, scale)
+	}
+	switch {
+	case el.group:
+		for j := 0; j < len(el.kids) && f.Ok(); j++ {
+			f.svgBasicElement(&el.kids[j], originX, originY, scale)
+		}
+	case len(el.spans) > 0:
+		f.svgBasicText(el, originX, originY, scale)
+	case !el.closed && !el.style.painted():
+		f.svgBasicStroke(el.segs, originX, originY, scale)
+	default:
+		f.svgBasicPaint(el, originX, originY, scale)
+	}
+	if clip {
+		f.ClipEnd()
+	}
+	if el.transform {
+		f.TransformEnd()
+	}
+}
+
+// svgBasicClip begins clipping with the clipping path of el. It returns
+// false if the clipping path cannot be applied.
+func (f *Fpdf) svgBasicClip(el *svgElementType, originX, originY, scale float64) bool {
+	cp := el.clip
+	segs := cp.segs
+	if cp.bbox {
+		x0, y0, x1, y1, ok := el.bounds()
+		if !ok {
+			return false
+		}
+		m := TransformMatrix{A: x1 - x0, D: y1 - y0, E: x0, F: y0}
+		segs = make([]SVGBasicSegmentType, len(cp.segs))
+		for j, seg := range cp.segs {
+			for k := 0; k < 6; k += 2 {
+				seg.Arg[k], seg.Arg[k+1] = svgApply(m, seg.Arg[k], seg.Arg[k+1])
+			}
+			segs[j] = seg
+		}
+	}
+	f.clipNest++
+	f.out("q")
+	if len(segs) == 0 {
+		// An empty clipping path hides the element
+		f.out("0 0 m W n")
+		return true
+	}
+	f.svgBasicPath(segs, originX, originY, scale)
+	if cp.evenOdd {
+		f.out("W* n")
+	} else {
+		f.out("W n")
+	}
+	return true
+}
+
+// svgBasicPaint fills and strokes a shape according to its style
+func (f *Fpdf) svgBasicPaint(el *svgElementType, originX, originY, scale float64) {
+	st := &el.style
+	// A single line segment, such as a line element, encloses no area
+	fill := !st.fill.none && len(el.segs) > 2
+	stroke := st.stroke.set && !st.stroke.none
+	if !fill && !stroke {
+		return
+	}
+	fillOpacity := st.opacity * st.fillOpacity * st.fill.alpha()
+	strokeOpacity := st.opacity * st.strokeOpacity * st.stroke.alpha()
+	if fill && st.fill.shaded() {
+		f.svgBasicShade(el, originX, originY, scale, fillOpacity)
+		fill = false
+		if !stroke {
+			return
+		}
+	}
+	// The graphics state is saved and restored around the shape, so the
+	// current values only need to be reinstated afterward
+	color, colorFlag := f.color, f.colorFlag
+	lineWidth, capStyle, joinStyle := f.lineWidth, f.capStyle, f.joinStyle
+	dashArray, dashPhase := f.dashArray, f.dashPhase
+	alpha, blendMode := f.alpha, f.blendMode
+	f.out("q")
+	if fill {
+		f.SetFillColor(st.fill.r, st.fill.g, st.fill.b)
+	}
+	if stroke {
+		f.SetDrawColor(st.stroke.r, st.stroke.g, st.stroke.b)
+		wd := 1.0
+		if st.strokeWd >= 0 {
+			wd = st.strokeWd
+		}
+		f.SetLineWidth(wd * scale)
+		f.SetLineCapStyle(st.capStr)
+		f.SetLineJoinStyle(st.joinStr)
+		dash := make([]float64, len(st.dash))
+		for j, v := range st.dash {
+			dash[j] = v * scale
+		}
+		f.SetDashPattern(dash, st.dashOffset*scale)
+	}
+	draw := func(styleStr string, opacity float64) {
+		if a := alpha * opacity; a != f.alpha {
+			f.SetAlpha(a, f.blendMode)
+		}
+		f.svgBasicPath(el.segs, originX, originY, scale)
+		f.DrawPath(styleStr)
+	}
+	rule := ""
+	if st.evenOdd {
+		rule = "*"
+	}
+	switch {
+	case fill && stroke && fillOpacity == strokeOpacity:
+		draw("FD"+rule, fillOpacity)
+	default:
+		if fill {
+			draw("F"+rule, fillOpacity)
+		}
+		if stroke {
+			draw("D", strokeOpacity)
+		}
+	}
+	f.out("Q")
+	f.color, f.colorFlag = color, colorFlag
+	f.lineWidth, f.capStyle, f.joinStyle = lineWidth, capStyle, joinStyle
+	f.dashArray, f.dashPhase = dashArray, dashPhase
+	f.alpha, f.blendMode = alpha, blendMode
+}
+
+// svgBasicShade fills a shape with its gradient
+func (f *Fpdf) svgBasicShade(el *svgElementType, originX, originY, scale, opacity float64) {
+	g := el.style.fill.grad
+	m := g.matrix
+	if g.bbox {
+		x0, y0, x1, y1, ok := el.bounds()
+		if !ok || x1 <= x0 || y1 <= y0 {
+			return
+		}
+		m = svgMultiply(TransformMatrix{A: x1 - x0, D: y1 - y0, E: x0, F: y0}, m)
+	}
+	// Map the gradient space to PDF space
+	m = svgMultiply(TransformMatrix{A: scale * f.k, D: -scale * f.k,
+		E: originX * f.k, F: (f.h - originY) * f.k}, m)
+	alpha := f.alpha
+	f.out("q")
+	if a := alpha * opacity; a != f.alpha {
+		f.SetAlpha(a, f.blendMode)
+	}
+	f.svgBasicPath(el.segs, originX, originY, scale)
+	if el.style.evenOdd {
+		f.out("W* n")
+	} else {
+		f.out("W n")
+	}
+	f.outf("%.5f %.5f %.5f %.5f %.5f %.5f cm", m.A, m.B, m.C, m.D, m.E, m.F)
+	gr := gradientType{tp: 2, stops: g.stops, x1: g.x1, y1: g.y1, x2: g.x2, y2: g.y2,
+		extend: [2]bool{true, true}}
+	if g.radial {
+		gr.tp, gr.r = 3, g.r
+	}
+	f.shade(gr)
+	f.out("Q")
+	f.alpha = alpha
+}
+
+// svgBasicText renders the spans of a text element
+func (f *Fpdf) svgBasicText(el *svgElementType, originX, originY, scale float64) {
+	// The graphics state is saved and restored around the text, so the
+	// current values only need to be reinstated afterward
+	color, colorFlag, alpha := f.color, f.colorFlag, f.alpha
+	fontFamily, fontStyle, underline := f.fontFamily, f.fontStyle, f.underline
+	fontSizePt, fontSize, currentFont := f.fontSizePt, f.fontSize, f.currentFont
+	f.out("q")
+	tr := make(map[string]func(string) string)
+	setFont := func(st *svgStyleType) {
+		familyStr := f.cssFontFamily(st.fontFamily, "helvetica")
+		f.SetFont(familyStr, f.cssFontStyle(familyStr, st.bold, st.italic), st.fontSize*scale*f.k)
+	}
+	text := func(str string) string {
+		enc := f.currentFont.Enc
+		if tr[enc] == nil {
+			tr[enc] = htmlTranslator(enc, f.fontpath)
+		}
+		return tr[enc](str)
+	}
+	var x, y float64
+	for j := 0; j < len(el.spans) && f.Ok(); {
+		// Measure the chunk that begins with span j to align it
+		span := &el.spans[j]
+		if span.hasX {
+			x = span.x
+		}
+		if span.hasY {
+			y = span.y
+		}
+		var wd float64
+		end := j
+		for end < len(el.spans) && (end == j || !(el.spans[end].hasX || el.spans[end].hasY)) {
+			setFont(&el.spans[end].style)
+			wd += f.GetStringWidth(text(el.spans[end].str))/scale + el.spans[end].dx
+			end++
+		}
+		wd -= span.dx
+		switch span.style.anchorStr {
+		case "middle":
+			x -= wd / 2
+		case "end":
+			x -= wd
+		}
+		for ; j < end && f.Ok(); j++ {
+			span = &el.spans[j]
+			st := &span.style
+			x += span.dx
+			y += span.dy
+			setFont(st)
+			str := text(span.str)
+			if !st.fill.none {
+				f.SetTextColor(st.fill.r, st.fill.g, st.fill.b)
+				if a := alpha * st.opacity * st.fillOpacity * st.fill.alpha(); a != f.alpha {
+					f.SetAlpha(a, f.blendMode)
+				}
+				f.Text(originX+scale*x, originY+scale*y, str)
+			}
+			x += f.GetStringWidth(str) / scale
+		}
+	}
+	f.out("Q")
+	f.color, f.colorFlag, f.alpha = color, colorFlag, alpha
+	f.fontFamily, f.fontStyle, f.underline = fontFamily, fontStyle, underline
+	f.fontSizePt, f.fontSize, f.currentFont = fontSizePt, fontSize, currentFont
+}
+
+// svgBasicPath constructs a path from the specified segments
+func (f *Fpdf) svgBasicPath(path []SVGBasicSegmentType, originX, originY, scale float64) {
+	for _, seg := range path {
+		val := func(arg int) (float64, float64) {
+			return originX + scale*seg.Arg[arg], originY + scale*seg.Arg[arg+1]
+		}
+		switch seg.Cmd {
+		case 'M':
+			f.MoveTo(val(0))
+		case 'L':
+			f.LineTo(val(0))
+		case 'C':
+			cx0, cy0 := val(0)
+			cx1, cy1 := val(2)
+			x, y := val(4)
+			f.CurveBezierCubicTo(cx0, cy0, cx1, cy1, x, y)
+		case 'Z':
+			f.ClosePath()
+		default:
+			f.SetErrorf("Unexpected path command '%c'", seg.Cmd)
+		}
+	}
+}
+
+// svgBasicStroke draws the segments of a path with the current line
+// settings and draw color
+func (f *Fpdf) svgBasicStroke(path []SVGBasicSegmentType, originX, originY, scale float64) {
+	var x, y, newX, newY, startX, startY float64
 	var cx0, cy0, cx1, cy1 float64
-	var path []SVGBasicSegmentType
 	var seg SVGBasicSegmentType
 	val := func(arg int) (float64, float64) {
 		return originX + scale*seg.Arg[arg], originY + scale*seg.Arg[arg+1]
 	}
-	for j := 0; j < len(sb.Segments) && f.Ok(); j++ {
-		path = sb.Segments[j]
-		for k := 0; k < len(path) && f.Ok(); k++ {
-			seg = path[k]
-			switch seg.Cmd {
-			case 'M':
-				x, y = val(0)
-				f.SetXY(x, y)
-			case 'L':
-				newX, newY = val(0)
-				f.Line(x, y, newX, newY)
-				x, y = newX, newY
-			case 'C':
-				cx0, cy0 = val(0)
-				cx1, cy1 = val(2)
-				newX, newY = val(4)
-				f.CurveCubic(x, y, cx0, cy0, newX, newY, cx1, cy1, "D")
-				x, y = newX, newY
-			default:
-				f.SetErrorf("Unexpected path command '%c'", seg.Cmd)
-			}
+	for k := 0; k < len(path) && f.Ok(); k++ {
+		seg = path[k]
+		switch seg.Cmd {
+		case 'M':
+			x, y = val(0)
+			startX, startY = x, y
+			f.SetXY(x, y)
+		case 'L':
+			newX, newY = val(0)
+			f.Line(x, y, newX, newY)
+			x, y = newX, newY
+		case 'C':
+			cx0, cy0 = val(0)
+			cx1, cy1 = val(2)
+			newX, newY = val(4)
+			f.CurveCubic(x, y, cx0, cy0, newX, newY, cx1, cy1, "D")
+			x, y = newX, newY
+		case 'Z':
+			f.Line(x, y, startX, startY)
+			x, y = startX, startY
+		default:
+			f.SetErrorf("Unexpected path command '%c'", seg.Cmd)
 		}
 	}
 }
@@ -149,11 +149,25 @@ func (f *Fpdf) putTemplates() {
 			f.outf("/Matrix [1 0 0 1 %.5f %.5f]", -corner.X*f.k*2, corner.Y*f.k*2)
 		}
 
+		// Templates that capture pages of this document share its resources
+		if _, ok := t.(*pageTplType); ok {
+			f.out("/Resources 2 0 R")
+			buffer := t.Bytes()
+			if f.compress {
+				buffer = sliceCompress(buffer)
+			}
+			f.outf("/Length %d >>", len(buffer))
+			f.putstream(buffer)
+			f.out("endobj")
+			continue
+		}
+
 		// Template's resource dictionary
 		f.out("/Resources ")
 		f.out("<</ProcSet [/PDF /Text /ImageB /ImageC /ImageI]")
 
 		f.templateFontCatalog()
+		f.spotColorPutResourceDict()
 
 		tImages := t.Images()
 		tTemplates := t.Templates()
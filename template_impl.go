@@ -100,6 +100,8 @@ func (t *Tpl) loadParamsFromFpdf(f *Fpdf) {
 	t.Fpdf.color.draw = f.color.draw
 	t.Fpdf.color.fill = f.color.fill
 	t.Fpdf.color.text = f.color.text
+	t.Fpdf.spotColorList = f.spotColorList
+	t.Fpdf.spotColorMap = f.spotColorMap
 
 	t.Fpdf.fonts = f.fonts
 	t.Fpdf.currentFont = f.currentFont
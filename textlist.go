@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2015 Kurt Jung (Gmail: kurt.w.jung)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ListLevelType specifies how the labels of list items at one nesting level
+// are rendered.
+//
+// If Numbering is not empty, items at this level are numbered. The first
+// occurrence in Numbering of one of the characters '1', 'a', 'A', 'i' or 'I'
+// that is not adjacent to another letter or digit is replaced with the item
+// number formatted as an Arabic numeral, a lowercase or uppercase letter, or
+// a lowercase or uppercase Roman numeral respectively. The remaining
+// characters are output literally, so formats such as "1.", "a)", "(i)",
+// "A." and "Item 1:" are supported. Numbering restarts
+// whenever an item at a shallower level intervenes.
+//
+// If Numbering is empty, Bullet is output as the label of each item. This is
+// a string in the encoding of the label font; for example, "\x95" is a bullet
+// in the cp1252 encoding used by the core fonts and "l" is a filled circle in
+// the ZapfDingbats font.
+//
+// FontFamily, FontStyle and FontSize (in points) specify the font of the
+// label. Empty or zero values select the corresponding attribute of the
+// current font. Labels are never underlined.
+type ListLevelType struct {
+	Bullet     string
+	Numbering  string
+	FontFamily string
+	FontStyle  string
+	FontSize   float64
+}
+
+// ListType is used for rendering bulleted and numbered lists that may be
+// nested to any depth. Each item is rendered with a hanging indent: the label
+// is placed in a column of width LabelWd and the text of the item wraps to
+// the right of it. The label of an item at nesting level n is indented by n
+// times Indent from the left margin. Levels specifies the label of each
+// nesting level; if there are more nesting levels than elements of Levels,
+// the elements are reused cyclically. Align specifies the alignment of item
+// text as in MultiCell() and LabelAlign the alignment of labels within their
+// column as in CellFormat().
+type ListType struct {
+	pdf        *Fpdf
+	counters   []int
+	Indent     float64
+	LabelWd    float64
+	Align      string
+	LabelAlign string
+	Levels     []ListLevelType
+}
+
+// ListNew returns an instance that facilitates writing lists in the specified
+// PDF document. By default, each level is indented by a quarter inch, bullets
+// are used at every level and item text is left aligned.
+//
+// The ListNew() example demonstrates this method.
+func (f *Fpdf) ListNew() (list ListType) {
+	list.pdf = f
+	list.Indent = 18 / f.k
+	list.LabelWd = 18 / f.k
+	list.Align = "L"
+	list.LabelAlign = "L"
+	list.Levels = []ListLevelType{
+		{Bullet: "\x95"},
+		{Bullet: "\x96"},
+		{Bullet: "\xb7"},
+	}
+	return
+}
+
+// Item writes a list item at the specified nesting level, beginning at the
+// current vertical position. level is zero for top-level items. lineHt
+// indicates the line height in the unit of measure specified in New(). The
+// text of the item is written with MultiCell(), so it can contain explicit
+// line breaks and page breaks occur as needed. Upon method exit, the current
+// position is at the left margin below the item.
+//
+// The ListNew() example demonstrates this method.
+func (l *ListType) Item(lineHt float64, level int, txtStr string) {
+	f := l.pdf
+	if f.err != nil {
+		return
+	}
+	if level < 0 {
+		f.SetErrorf("invalid list level %d", level)
+		return
+	}
+	for len(l.counters) <= level {
+		l.counters = append(l.counters, 0)
+	}
+	l.counters = l.counters[:level+1]
+	l.counters[level]++
+	lvl := l.level(level)
+	var labelStr string
+	if lvl.Numbering != "" {
+		labelStr = listNumber(lvl.Numbering, l.counters[level])
+	} else {
+		labelStr = lvl.Bullet
+	}
+	f.x = f.lMargin + l.Indent*float64(level)
+	l.label(lineHt, lvl, labelStr)
+	f.MultiCell(0, lineHt, txtStr, "", l.Align, false)
+}
+
+// Reset restarts the numbering of the list so that the next item at any
+// level is numbered as the first.
+//
+// The ListNew() example demonstrates this method.
+func (l *ListType) Reset() {
+	l.counters = l.counters[:0]
+}
+
+// level returns the style of the specified nesting level
+func (l *ListType) level(level int) (lvl ListLevelType) {
+	if len(l.Levels) > 0 {
+		lvl = l.Levels[level%len(l.Levels)]
+	}
+	return
+}
+
+// label writes an item label in a cell of width LabelWd at the current
+// position using the label font of the specified level. The current font is
+// restored afterward.
+func (l *ListType) label(lineHt float64, lvl ListLevelType, labelStr string) {
+	f := l.pdf
+	familyStr, styleStr, sizePt := f.fontFamily, f.fontStyle, f.fontSizePt
+	if f.underline {
+		styleStr += "U"
+	}
+	lblFamilyStr, lblStyleStr := lvl.FontFamily, lvl.FontStyle
+	if lblFamilyStr == "" {
+		lblFamilyStr = f.fontFamily
+	}
+	if lblStyleStr == "" {
+		lblStyleStr = f.fontStyle
+	}
+	lblStyleStr = strings.Replace(strings.ToUpper(lblStyleStr), "U", "", -1)
+	f.SetFont(lblFamilyStr, lblStyleStr, lvl.FontSize)
+	f.CellFormat(l.LabelWd, lineHt, labelStr, "", 0, l.LabelAlign, false, 0, "")
+	f.SetFont(familyStr, styleStr, sizePt)
+}
+
+// listNumber formats n according to the numbering format fmtStr as described
+// for ListLevelType
+func listNumber(fmtStr string, n int) string {
+	pos := -1
+	for j := 0; j < len(fmtStr) && pos < 0; j++ {
+		if strings.IndexByte("1aAiI", fmtStr[j]) >= 0 &&
+			(j == 0 || !listAlnum(fmtStr[j-1])) &&
+			(j == len(fmtStr)-1 || !listAlnum(fmtStr[j+1])) {
+			pos = j
+		}
+	}
+	if pos < 0 {
+		return fmtStr
+	}
+	var numStr string
+	switch fmtStr[pos] {
+	case '1':
+		numStr = strconv.Itoa(n)
+	case 'a':
+		numStr = listAlpha(n)
+	case 'A':
+		numStr = strings.ToUpper(listAlpha(n))
+	case 'i':
+		numStr = listRoman(n)
+	case 'I':
+		numStr = strings.ToUpper(listRoman(n))
+	}
+	return fmtStr[:pos] + numStr + fmtStr[pos+1:]
+}
+
+// listAlnum returns true if b is an ASCII letter or digit
+func listAlnum(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// listAlpha returns n as a sequence of lowercase letters: a, b, ... z, aa,
+// ab, ...
+func listAlpha(n int) string {
+	var buf []byte
+	for n > 0 {
+		n--
+		buf = append([]byte{byte('a' + n%26)}, buf...)
+		n /= 26
+	}
+	return string(buf)
+}
+
+// listRoman returns n as a lowercase Roman numeral. Values that cannot be
+// represented as Roman numerals are returned as Arabic numerals.
+func listRoman(n int) string {
+	if n <= 0 || n >= 4000 {
+		return strconv.Itoa(n)
+	}
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"m", "cm", "d", "cd", "c", "xc", "l", "xl", "x", "ix", "v", "iv", "i"}
+	var buf []byte
+	for j, v := range values {
+		for n >= v {
+			buf = append(buf, symbols[j]...)
+			n -= v
+		}
+	}
+	return string(buf)
+}